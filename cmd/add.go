@@ -11,15 +11,40 @@ var addCmd = &cobra.Command{
 	Use:     "add",
 	Aliases: []string{"a"},
 	Short:   "Add a todo item",
-	Long:    `Add a todo item to the Notion database with an optional due date.`,
-	Run:     processors.Add,
-	Args:    cobra.MinimumNArgs(1),
-	Example: `todo add "Buy groceries" --date 15-03-25
-todo a "Finish project report"
-todo add "Call dentist" -d 20-06-25`,
+	Long: `Add a todo item to the Notion database with an optional due date.
+Due dates can be written as today, tomorrow, fri, next monday, in 3 days, eow, eom,
+an ISO date such as 2025-03-15, or a date in the configured format (DD-MM-YYYY by default),
+optionally followed by a time such as 17:00 or 5pm.
+Use --end for a date range and --tz to read times in another time zone.
+A note given with --note or --note-file is written to the page body.
+Set the optional priority, tags and assignees with --priority, --tag and --assignee;
+users are matched by name, email or ID.
+Any other property is set with --prop Name=value, encoded by its type in the database.`,
+	RunE: processors.Add,
+	Args: cobra.MinimumNArgs(1),
+	Example: `todo add "Buy groceries" --date 15-03-2025
+todo a "Finish project report" -d fri
+todo add "Call dentist" -d "tomorrow 17:00"
+todo add "Renew passport" -d "in 3 weeks"
+todo add "Team offsite" --start 10-06-2025 --end 12-06-2025
+todo add "Standup" -d "mon 9:30" --end 9:45 --tz Europe/Berlin
+todo add "Ship release" --priority high --tag backend,release --assignee ada
+todo add "Plan sprint" --prop Estimate=3 --prop "Sprint=24" --prop "Spec=https://example.com/spec"
+todo add "Fix login bug" --note "Fails only with SSO accounts"
+git log -1 --format=%B | todo add "Review change" --note-file -`,
 }
 
 func init() {
 	rootCmd.AddCommand(addCmd)
-	addCmd.Flags().StringP("date", "d", "", "Due date for the todo item (optional, format: DD-MM-YYYY)")
+	processors.AddDatabaseFlag(addCmd)
+	addCmd.Flags().StringP("date", "d", "", "Due date for the todo item (optional, e.g. tomorrow, fri 17:00, in 3 days, DD-MM-YYYY)")
+	addCmd.Flags().String("start", "", "Start of the due date range, same as --date")
+	addCmd.Flags().String("end", "", "End of the due date range; a time alone ends on the start day")
+	addCmd.Flags().String("tz", "", "IANA time zone of the due time, e.g. Europe/Berlin (defaults to the configured zone)")
+	addCmd.Flags().String("priority", "", "Priority of the todo, one of the options of the priority property")
+	addCmd.Flags().StringSlice("tag", nil, "Tag to add, repeatable or comma separated")
+	addCmd.Flags().StringSlice("assignee", nil, "User to assign by name, email or ID, repeatable or comma separated")
+	addCmd.Flags().StringArray("prop", nil, "Set a database property as Name=value, repeatable")
+	addCmd.Flags().String("note", "", "Note to write to the page body, in Markdown")
+	addCmd.Flags().String("note-file", "", "Read the note from a file, or standard input with -")
 }
@@ -3,10 +3,21 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/caffeines/notion-todo/cmd/output"
+	"github.com/caffeines/notion-todo/cmd/processors"
 	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
 	"github.com/caffeines/notion-todo/service/config"
 	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/secrets"
+	"github.com/caffeines/notion-todo/service/statuses"
+	"github.com/caffeines/notion-todo/service/utility"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 )
@@ -16,8 +27,38 @@ var configCmd = &cobra.Command{
 	Use:     "config",
 	Aliases: []string{"c"},
 	Short:   "Configure the app",
-	Long:    `Configure the app by setting the token and database id.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Configure the app by setting the token and database id.
+The database properties used for title, status and due date are detected automatically.
+With --profile, the credentials are stored in that profile, which is created if needed.`,
+	Example: `todo config
+todo config --refresh
+todo config --profile work
+todo config --secret-store file
+todo config --date-format MM-DD-YYYY
+todo config --time-zone America/New_York`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file := files.NewFileService(files.ConfigDir, consts.ConfigFileName)
+		credService := config.NewCredentialSvc(file)
+
+		if cmd.Flags().Changed("secret-store") {
+			return setSecretStore(cmd, credService)
+		}
+
+		if cmd.Flags().Changed("date-format") || cmd.Flags().Changed("time-zone") {
+			return setDateSettings(cmd, credService)
+		}
+
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		if refresh {
+			return discoverProperties(cmd, credService)
+		}
+
+		if !output.IsTerminal(os.Stdin) {
+			fmt.Println("todo config needs a terminal. Use 'todo config set token TOKEN' and 'todo config set databaseId ID',")
+			fmt.Printf("or set %s and %s.\n", consts.EnvToken, consts.EnvDatabaseID)
+			return processors.Reported(&processors.UsageError{Err: errors.New("not a terminal")})
+		}
+
 		tokenValidate := func(input string) error {
 			if len(input) == 0 {
 				return errors.New("Token cannot be empty")
@@ -46,25 +87,218 @@ var configCmd = &cobra.Command{
 
 		token, err := tokenPrompt.Run()
 		if err != nil {
-			return
+			return nil
 		}
 		databaseId, err := databaseIDPrompt.Run()
 		if err != nil {
-			return
+			return nil
 		}
 
-		file := files.NewFileService(consts.ConfigFileName)
-		credService := config.NewCredentialSvc(file)
 		err = credService.SetConfig(token, databaseId)
 		if err != nil {
 			fmt.Println("Error setting config: " + err.Error())
-			return
+			return processors.Reported(err)
 		}
 
-		fmt.Printf("\n✅ Application configured successfully\n")
+		profile, _ := credService.Profile()
+		if profile == consts.DefaultProfile {
+			fmt.Printf("\n✅ Application configured successfully\n")
+		} else {
+			fmt.Printf("\n✅ Profile '%s' configured successfully\n", profile)
+		}
+		if path, err := file.Path(); err == nil {
+			fmt.Printf("📁 Saved to %s\n", path)
+		}
+		if cfg, err := credService.GetSettings(); err == nil {
+			fmt.Printf("🔒 Token kept %s\n", secretStoreName(cfg.SecretStore))
+		}
+		return discoverProperties(cmd, credService)
 	},
 }
 
+// configSetCmd saves a single config key without prompting
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a config key without prompting",
+	Long: `Save a config key of the profile in use, for scripts, CI jobs and containers.
+An empty value resets optional keys to their default. The profile is created if needed.
+
+Every key can also be given for a single run by an environment variable, and the token and database ID by
+the global --token and --database flags. Flags take precedence over environment variables, which take
+precedence over the config file.
+
+` + configKeysHelp(),
+	Example: `todo config set token "$NOTION_TOKEN"
+todo config set databaseId 1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d
+todo config set timeZone Europe/Berlin
+todo --profile work config set requestTimeout 60`,
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names := make([]string, len(config.Keys))
+		for i, key := range config.Keys {
+			names[i] = key.Name
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+		key, err := config.FindKey(args[0])
+		if err == nil {
+			err = credService.Set(key.Name, args[1])
+		}
+		if err != nil {
+			fmt.Println("Error setting config: " + err.Error())
+			if hint := processors.Hint(err); hint != "" {
+				fmt.Println(hint)
+			}
+			return processors.Reported(err)
+		}
+		if key.Name == config.KeyToken {
+			fmt.Println("✅ Token saved")
+			return nil
+		}
+		fmt.Printf("✅ %s set to %q\n", key.Name, args[1])
+		return nil
+	},
+}
+
+// configKeysHelp lists the config keys with their environment variables
+func configKeysHelp() string {
+	var help strings.Builder
+	help.WriteString("Keys:\n")
+	for _, key := range config.Keys {
+		fmt.Fprintf(&help, "  %-15s %-21s %s\n", key.Name, key.Env, key.Usage)
+	}
+	return help.String()
+}
+
+// discoverProperties detects the database property mapping and reports it
+func discoverProperties(cmd *cobra.Command, credService config.Credential) error {
+	notionSvc := notion.NewNotionImpl(credService)
+	mapping, err := notionSvc.DiscoverProperties(cmd.Context())
+	if err != nil {
+		fmt.Println("\n⚠️  Could not detect database properties: " + err.Error())
+		if hint := processors.Hint(err); hint != "" {
+			fmt.Println(hint)
+		}
+		return processors.Reported(err)
+	}
+
+	fmt.Printf("\nDatabase properties:\n")
+	fmt.Printf("  Title:    %s\n", mapping.Title)
+	fmt.Printf("  Status:   %s (%s)\n", mapping.Status, mapping.StatusType)
+	if mapping.DueDate != "" {
+		fmt.Printf("  Due date: %s\n", mapping.DueDate)
+	} else {
+		fmt.Printf("  Due date: none\n")
+	}
+	if mapping.Priority != "" {
+		fmt.Printf("  Priority: %s\n", mapping.Priority)
+	}
+	if mapping.Tags != "" {
+		fmt.Printf("  Tags:     %s\n", mapping.Tags)
+	}
+	if mapping.Assignee != "" {
+		fmt.Printf("  Assignee: %s\n", mapping.Assignee)
+	}
+
+	// Refresh the cached status list so completion and validation see changes
+	statusSvc := statuses.NewStatusSvc(notionSvc, credService, files.NewFileService(files.CacheDir, consts.StatusCacheFileName))
+	options, err := statusSvc.Refresh(cmd.Context())
+	if err != nil {
+		fmt.Println("\n⚠️  Could not load statuses: " + err.Error())
+		return processors.Reported(err)
+	}
+	fmt.Printf("  Statuses: %s\n", strings.Join(statuses.Names(options), ", "))
+	return nil
+}
+
+// setSecretStore moves the tokens of every profile to the chosen store
+func setSecretStore(cmd *cobra.Command, credService config.Credential) error {
+	backend, _ := cmd.Flags().GetString("secret-store")
+	backend = strings.ToLower(backend)
+	if !slices.Contains(secrets.Backends, backend) {
+		return &processors.UsageError{Err: fmt.Errorf("unknown secret store %q, use %s", backend, strings.Join(secrets.Backends, ", "))}
+	}
+	if err := credService.SetSecretStore(backend); err != nil {
+		fmt.Println("Error moving tokens: " + err.Error())
+		if hint := processors.Hint(err); hint != "" {
+			fmt.Println(hint)
+		}
+		return processors.Reported(err)
+	}
+	fmt.Printf("✅ Tokens are now kept %s\n", secretStoreName(backend))
+	return nil
+}
+
+// secretStoreName describes where a secret store backend keeps tokens
+func secretStoreName(backend string) string {
+	switch backend {
+	case secrets.BackendKeyring:
+		return "in the system keyring"
+	case secrets.BackendFile:
+		path, _ := files.NewFileService(files.DataDir, consts.SecretsFileName).Path()
+		return "in the passphrase-encrypted file " + path
+	}
+	path, _ := files.NewFileService(files.ConfigDir, consts.ConfigFileName).Path()
+	return "in plain text in " + path
+}
+
+// setDateSettings saves the order of numeric dates and the time zone times
+// are typed in
+func setDateSettings(cmd *cobra.Command, credService config.Credential) error {
+	flags := cmd.Flags()
+	var locale utility.DateLocale
+	if flags.Changed("date-format") {
+		name, _ := flags.GetString("date-format")
+		var err error
+		if locale, err = utility.ParseDateLocale(name); err != nil {
+			return &processors.UsageError{Err: err}
+		}
+	}
+	timeZone, _ := flags.GetString("time-zone")
+	if timeZone != "" {
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			return &processors.UsageError{Err: fmt.Errorf("unknown time zone %q, use an IANA name such as Europe/Berlin", timeZone)}
+		}
+	}
+
+	err := credService.UpdateConfig(func(cfg *models.Config) error {
+		if flags.Changed("date-format") {
+			cfg.DateFormat = string(locale)
+		}
+		if flags.Changed("time-zone") {
+			cfg.TimeZone = timeZone
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error setting config: %w", err)
+	}
+	if flags.Changed("date-format") {
+		fmt.Printf("✅ Dates are now read and shown as %s\n", locale)
+	}
+	if flags.Changed("time-zone") {
+		if timeZone == "" {
+			fmt.Println("✅ Times are now read in local time")
+		} else {
+			fmt.Printf("✅ Times are now read in %s\n", timeZone)
+		}
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.Flags().String("date-format", "", "Order of numeric dates typed and shown: DD-MM-YYYY, MM-DD-YYYY or YYYY-MM-DD")
+	configCmd.Flags().String("time-zone", "", "IANA time zone due times are typed in, e.g. Europe/Berlin; empty for local time")
+	configCmd.Flags().String("secret-store", "", "Where to keep tokens: "+strings.Join(secrets.Backends, ", ")+" (default keyring when available, otherwise file)")
+	_ = configCmd.RegisterFlagCompletionFunc("secret-store", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return secrets.Backends, cobra.ShellCompDirectiveNoFileComp
+	})
+	configCmd.Flags().Bool("refresh", false, "Detect the database properties again without changing credentials")
 }
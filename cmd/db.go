@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/caffeines/notion-todo/cmd/processors"
+
+	"github.com/spf13/cobra"
+)
+
+// dbCmd represents the db command
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the databases of a profile",
+	Long: `Register further Notion databases of the profile under short aliases.
+The database set with 'todo config' is called "default". Pick another one for a single command with --db <alias>,
+or change the default with 'todo db use <alias>'. 'todo list --all-dbs' lists the todos of every database.`,
+}
+
+var dbAddCmd = &cobra.Command{
+	Use:   "add <alias> <database-id>",
+	Short: "Register a database under an alias",
+	RunE:  processors.DbAdd,
+	Args:  cobra.ExactArgs(2),
+	Example: `todo db add sprint 1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d
+todo add "Fix login bug" --db sprint`,
+}
+
+var dbListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List databases, marking the one in use",
+	RunE:    processors.DbList,
+	Args:    cobra.NoArgs,
+}
+
+var dbUseCmd = &cobra.Command{
+	Use:               "use <alias>",
+	Short:             "Use a database by default",
+	RunE:              processors.DbUse,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: processors.CompleteDatabases,
+}
+
+var dbRemoveCmd = &cobra.Command{
+	Use:               "remove <alias>",
+	Aliases:           []string{"rm"},
+	Short:             "Remove a database alias",
+	RunE:              processors.DbRemove,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: processors.CompleteDatabases,
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbAddCmd, dbListCmd, dbUseCmd, dbRemoveCmd)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/caffeines/notion-todo/cmd/processors"
+
+	"github.com/spf13/cobra"
+)
+
+// doneCmd represents the done command
+var doneCmd = &cobra.Command{
+	Use:   "done <todo>",
+	Short: "Mark a todo as complete",
+	Long: `Mark a todo as complete without opening the list view.
+The todo is referenced by its number or short ID in 'todo list -o table', its page ID, or part of its title.
+The first status in the database's Complete group is used.`,
+	RunE: processors.Done,
+	Args: cobra.MinimumNArgs(1),
+	Example: `todo done 3
+todo done 1a2b3c4d
+todo done "buy milk"
+todo done groceries --all-matching --dry-run`,
+}
+
+func init() {
+	rootCmd.AddCommand(doneCmd)
+	addStatusChangeFlags(doneCmd)
+}
+
+// addStatusChangeFlags registers the flags shared by the status commands
+func addStatusChangeFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("all-matching", "a", false, "Change every todo matching the reference instead of failing when several match")
+	cmd.Flags().BoolP("dry-run", "n", false, "Show what would change without updating Notion")
+}
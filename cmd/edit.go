@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/caffeines/notion-todo/cmd/processors"
+
+	"github.com/spf13/cobra"
+)
+
+// editCmd represents the edit command
+var editCmd = &cobra.Command{
+	Use:     "edit <todo>",
+	Aliases: []string{"e"},
+	Short:   "Edit the title, due date, status, priority, tags or assignees of a todo",
+	Long: `Edit the title, due date, status, priority, tags or assignees of a todo.
+The todo is referenced by its number or short ID in 'todo list -o table', its page ID, or part of its title.
+--tag and --untag add and remove tags; --assignee replaces the assignees, matched by name, email or ID.
+--prop Name=value sets any other database property; an empty value clears it.`,
+	RunE: processors.Edit,
+	Args: cobra.MinimumNArgs(1),
+	Example: `todo edit 3 --title "Buy oat milk"
+todo edit "report" --date "next monday 9am"
+todo edit "conference" --date 10-06-2025 --end 12-06-2025 --tz Europe/Berlin
+todo edit 1a2b3c4d --clear-date --status "In progress"
+todo edit 2 --priority high --tag urgent --untag someday
+todo edit "release" --assignee ada,grace
+todo edit 4 --prop Estimate=5 --prop "Sprint="`,
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+	processors.AddDatabaseFlag(editCmd)
+	editCmd.Flags().StringP("title", "t", "", "New title")
+	editCmd.Flags().StringP("date", "d", "", "New due date (e.g. tomorrow, fri 17:00, in 3 days, DD-MM-YYYY)")
+	editCmd.Flags().String("start", "", "New start of the due date range, same as --date")
+	editCmd.Flags().String("end", "", "End of the due date range; a time alone ends on the start day (needs --date)")
+	editCmd.Flags().String("tz", "", "IANA time zone of the due time, e.g. Europe/Berlin (needs --date, defaults to the configured zone)")
+	editCmd.Flags().Bool("clear-date", false, "Remove the due date")
+	editCmd.Flags().StringP("status", "s", "", "New status, as named in the database")
+	editCmd.Flags().String("priority", "", "New priority, one of the options of the priority property")
+	editCmd.Flags().Bool("clear-priority", false, "Remove the priority")
+	editCmd.Flags().StringSlice("tag", nil, "Tag to add, repeatable or comma separated")
+	editCmd.Flags().StringSlice("untag", nil, "Tag to remove, repeatable or comma separated")
+	editCmd.Flags().StringSlice("assignee", nil, "User to assign by name, email or ID, replacing the current assignees")
+	editCmd.Flags().Bool("unassign", false, "Remove all assignees")
+	editCmd.Flags().StringArray("prop", nil, "Set a database property as Name=value, repeatable; an empty value clears it")
+	editCmd.Flags().BoolP("dry-run", "n", false, "Show what would change without updating Notion")
+	_ = editCmd.RegisterFlagCompletionFunc("status", processors.CompleteStatuses)
+}
@@ -11,7 +11,7 @@ var guideCmd = &cobra.Command{
 	Aliases: []string{"g"},
 	Short:   "Interactive guide to setup Notion database and integration token",
 	Long:    `An interactive, step-by-step guide to help you set up your Notion integration and database for the todo CLI.`,
-	Run:     processors.Guide,
+	RunE:    processors.Guide,
 }
 
 func init() {
@@ -4,10 +4,7 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/caffeines/notion-todo/cmd/processors"
-	"github.com/caffeines/notion-todo/consts"
 	"github.com/spf13/cobra"
 )
 
@@ -17,13 +14,17 @@ var listCmd = &cobra.Command{
 	Short:   "List all items in the Notion Todo database",
 	Long: `list retrieves and displays all items from the Notion Todo database.
 This command is useful for viewing all tasks, their statuses, and due dates in a structured format.`,
-	Run: processors.List,
+	RunE: processors.List,
 }
 
 func init() {
 	rootCmd.AddCommand(listCmd)
 
-	listCmd.Flags().StringP("status", "s", "", fmt.Sprintf("Filter items by status (e.g., %s)", consts.GetAllStatuses()))
+	processors.AddFilterFlags(listCmd)
+	listCmd.Flags().String("view", "", "Open a saved view; other filter flags override it")
+	_ = listCmd.RegisterFlagCompletionFunc("view", processors.CompleteViews)
+	processors.AddDatabaseFlag(listCmd)
+	listCmd.Flags().Bool("all-dbs", false, "List the todos of every database of the profile, with a source column")
 
 	// Here you will define your flags and configuration settings.
 
@@ -0,0 +1,385 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/mattn/go-isatty"
+)
+
+// Format is a machine-readable output format
+type Format string
+
+const (
+	FormatTable  Format = "table"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatYAML   Format = "yaml"
+	FormatCSV    Format = "csv"
+)
+
+// Formats lists the supported formats in the order shown in help
+var Formats = []Format{FormatTable, FormatJSON, FormatNDJSON, FormatYAML, FormatCSV}
+
+// Fields are the stable field names of a todo record, in output order. They
+// match the JSON tags of models.TodoItem.
+var Fields = []string{"id", "title", "status", "status_group", "due_date", "due_date_end", "due_time_zone", "priority", "tags", "assignees", "url", "created_time", "last_edited_time"}
+
+// TableColumns are the fields shown in tables by default
+var TableColumns = []string{PositionField, "id", "status", "due_date", "title"}
+
+// SourceField is the column with the database alias of todos listed from
+// several databases
+const SourceField = "source"
+
+// PositionField is the column with the 1-based row number of a todo. The
+// numbers of the unfiltered table are accepted as todo references.
+const PositionField = "position"
+
+// DefaultColumns returns the columns of format when none are given, nil for
+// formats that write every field
+func DefaultColumns(format Format) []string {
+	switch format {
+	case FormatCSV:
+		return Fields
+	case FormatTable:
+		return TableColumns
+	}
+	return nil
+}
+
+// ValidateColumns reports empty columns. Columns that are not record fields
+// name database properties, which are checked once todos are loaded.
+func ValidateColumns(columns []string) error {
+	for _, column := range columns {
+		if strings.TrimSpace(column) == "" {
+			return fmt.Errorf("empty column name, use any of: %s or a database property", strings.Join(Fields, ", "))
+		}
+	}
+	return nil
+}
+
+func fieldIndex(field string) int {
+	for i, name := range Fields {
+		if name == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// property returns the name of the property of todo matching column,
+// ignoring case
+func property(todo models.TodoItem, column string) (string, bool) {
+	if _, ok := todo.Properties[column]; ok {
+		return column, true
+	}
+	for name := range todo.Properties {
+		if strings.EqualFold(name, column) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// CheckColumns reports columns that are neither fields nor properties of
+// todos. Every todo of a database has every property of it, so the first
+// todo of each source is enough.
+func CheckColumns(todos []models.TodoItem, columns []string) error {
+	if len(todos) == 0 {
+		return nil
+	}
+	var samples []models.TodoItem
+	seen := map[string]bool{}
+	for _, todo := range todos {
+		if !seen[todo.Source] {
+			seen[todo.Source] = true
+			samples = append(samples, todo)
+		}
+	}
+	for _, column := range columns {
+		if fieldIndex(column) >= 0 || column == SourceField || column == PositionField {
+			continue
+		}
+		known := false
+		names := map[string]bool{}
+		for _, todo := range samples {
+			_, ok := property(todo, column)
+			known = known || ok
+			for name := range todo.Properties {
+				names[name] = true
+			}
+		}
+		if !known {
+			list := make([]string, 0, len(names))
+			for name := range names {
+				list = append(list, name)
+			}
+			sort.Strings(list)
+			return fmt.Errorf("unknown column %q, use any of: %s, or a database property: %s", column, strings.Join(Fields, ", "), strings.Join(list, ", "))
+		}
+	}
+	return nil
+}
+
+// columnValue returns the value of a field or database property of the
+// todo at position
+func columnValue(todo models.TodoItem, position int, values []interface{}, column string) interface{} {
+	if i := fieldIndex(column); i >= 0 {
+		return values[i]
+	}
+	switch column {
+	case SourceField:
+		return todo.Source
+	case PositionField:
+		return strconv.Itoa(position)
+	}
+	if name, ok := property(todo, column); ok {
+		return todo.Properties[name]
+	}
+	return nil
+}
+
+// ParseFormat validates a format name, ignoring case
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q, use one of: %s", name, FormatNames())
+}
+
+// FormatNames returns the supported format names separated by "|"
+func FormatNames() string {
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, "|")
+}
+
+// Resolve returns the format to print in for the --output flag value. An
+// empty flag means interactive output on a terminal and a table otherwise;
+// ok is false when the interactive view should be used.
+func Resolve(flag string) (format Format, ok bool, err error) {
+	if flag != "" {
+		format, err := ParseFormat(flag)
+		return format, err == nil, err
+	}
+	if IsTerminal(os.Stdout) {
+		return "", false, nil
+	}
+	return FormatTable, true, nil
+}
+
+// IsTerminal reports whether f is an interactive terminal
+func IsTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// WriteTodos writes todos to w in format. Columns select the fields of
+// tables and CSV; empty columns use the defaults of the format.
+func WriteTodos(w io.Writer, format Format, todos []models.TodoItem, columns []string) error {
+	if err := ValidateColumns(columns); err != nil {
+		return err
+	}
+	if todos == nil {
+		todos = []models.TodoItem{}
+	}
+	if err := CheckColumns(todos, columns); err != nil {
+		return err
+	}
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(todos)
+	case FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		for _, todo := range todos {
+			if err := encoder.Encode(todo); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatYAML:
+		return writeYAML(w, todos)
+	case FormatCSV:
+		if len(columns) == 0 {
+			columns = DefaultColumns(format)
+		}
+		return writeCSV(w, todos, columns)
+	case FormatTable:
+		if len(columns) == 0 {
+			columns = DefaultColumns(format)
+		}
+		return writeTable(w, todos, columns)
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// record returns the field values of todo in Fields order. Values are
+// strings or, for tags and assignees, string lists; nil is null.
+func record(todo models.TodoItem) []interface{} {
+	optional := func(value *string) interface{} {
+		if value == nil {
+			return nil
+		}
+		return *value
+	}
+	return []interface{}{
+		todo.ID, todo.Title, todo.Status, todo.StatusGroup, optional(todo.DueDate),
+		optional(todo.DueDateEnd), optional(todo.DueTimeZone), optional(todo.Priority),
+		todo.Tags, todo.Assignees, todo.URL, todo.CreatedTime, todo.LastEditedTime,
+	}
+}
+
+// Text returns a record or property value as a single string, joining lists
+// with commas. ok is false for null and empty lists.
+func Text(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case []string:
+		return strings.Join(value, ", "), len(value) > 0
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(value), true
+	}
+	return "", false
+}
+
+// writeYAML writes todos as a YAML sequence of mappings. Values are written
+// in JSON syntax, which YAML accepts.
+func writeYAML(w io.Writer, todos []models.TodoItem) error {
+	if len(todos) == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+	for _, todo := range todos {
+		for i, value := range record(todo) {
+			prefix := "  "
+			if i == 0 {
+				prefix = "- "
+			}
+			// Lists are written as flow sequences
+			scalar, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "%s%s: %s\n", prefix, Fields[i], scalar); err != nil {
+				return err
+			}
+		}
+		if todo.Source != "" {
+			source, err := json.Marshal(todo.Source)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "  %s: %s\n", SourceField, source); err != nil {
+				return err
+			}
+		}
+		if err := writeYAMLProperties(w, todo.Properties); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeYAMLProperties writes the properties of a todo as a nested mapping
+// with quoted keys, sorted by name
+func writeYAMLProperties(w io.Writer, properties map[string]interface{}) error {
+	if len(properties) == 0 {
+		_, err := fmt.Fprintln(w, "  properties: {}")
+		return err
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if _, err := fmt.Fprintln(w, "  properties:"); err != nil {
+		return err
+	}
+	for _, name := range names {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(properties[name])
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "    %s: %s\n", key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes a header row followed by one row per todo; null is empty
+// and lists are joined with commas
+func writeCSV(w io.Writer, todos []models.TodoItem, columns []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for n, todo := range todos {
+		values := record(todo)
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i], _ = Text(columnValue(todo, n+1, values, column))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeTable writes an aligned plain text table with short IDs and row
+// numbers under #
+func writeTable(w io.Writer, todos []models.TodoItem, columns []string) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = strings.ToUpper(strings.ReplaceAll(column, "_", " "))
+		if column == PositionField {
+			header[i] = "#"
+		}
+	}
+	fmt.Fprintln(table, strings.Join(header, "\t"))
+
+	clean := strings.NewReplacer("\n", " ", "\t", " ")
+	for n, todo := range todos {
+		values := record(todo)
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			value, ok := Text(columnValue(todo, n+1, values, column))
+			switch {
+			case !ok || value == "":
+				row[i] = "-"
+			case column == "id":
+				row[i] = utility.ShortID(value)
+			default:
+				row[i] = clean.Replace(value)
+			}
+		}
+		fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+	return table.Flush()
+}
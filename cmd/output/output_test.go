@@ -0,0 +1,147 @@
+package output
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+func ptr(value string) *string {
+	return &value
+}
+
+// testTodos are a todo with every field set and a todo of another database
+// with most fields empty
+var testTodos = []models.TodoItem{
+	{
+		ID:             "1f2e3d4c-5b6a-4789-8abc-def012345678",
+		Title:          "Write the \"Q4\" report,\nthen\tship it",
+		Status:         "In review",
+		StatusGroup:    "In progress",
+		DueDate:        ptr("2026-10-20T09:00:00"),
+		DueDateEnd:     ptr("2026-10-20T11:30:00"),
+		DueTimeZone:    ptr("Europe/Berlin"),
+		Priority:       ptr("High"),
+		Tags:           []string{"work", "q4"},
+		Assignees:      []string{"Ada Lovelace"},
+		URL:            "https://www.notion.so/Write-1f2e3d4c5b6a47898abcdef012345678",
+		CreatedTime:    "2026-10-01T08:00:00.000Z",
+		LastEditedTime: "2026-10-18T16:45:00.000Z",
+		Properties: map[string]interface{}{
+			"Estimate": 3.5,
+			"Reviewed": true,
+			"Labels":   []string{"writing", "finance"},
+			"Notes":    "first line\nsecond: line",
+		},
+		Source: "sprint",
+	},
+	{
+		ID:             "0a1b2c3d-4e5f-4607-8899-aabbccddeeff",
+		Title:          "Renew passport",
+		Status:         "Not started",
+		StatusGroup:    "To-do",
+		Tags:           []string{},
+		Assignees:      []string{},
+		URL:            "https://www.notion.so/Renew-passport-0a1b2c3d4e5f46078899aabbccddeeff",
+		CreatedTime:    "2026-09-12T10:00:00.000Z",
+		LastEditedTime: "2026-09-12T10:00:00.000Z",
+		Properties: map[string]interface{}{
+			"Estimate": nil,
+			"Labels":   []string{},
+		},
+		Source: "personal \"life\"",
+	},
+}
+
+func TestWriteTodosGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		todos   []models.TodoItem
+		columns []string
+	}{
+		{name: "json", format: FormatJSON, todos: testTodos},
+		{name: "ndjson", format: FormatNDJSON, todos: testTodos},
+		{name: "yaml", format: FormatYAML, todos: testTodos},
+		{name: "csv", format: FormatCSV, todos: testTodos},
+		{name: "csv_properties", format: FormatCSV, todos: testTodos, columns: []string{PositionField, "title", "estimate", "Labels", "Reviewed", SourceField}},
+		{name: "table", format: FormatTable, todos: testTodos},
+		{name: "table_properties", format: FormatTable, todos: testTodos, columns: []string{PositionField, "id", "due_date_end", "tags", "Estimate", "labels", "Notes", "Reviewed", SourceField}},
+		{name: "json_empty", format: FormatJSON},
+		{name: "ndjson_empty", format: FormatNDJSON},
+		{name: "yaml_empty", format: FormatYAML},
+		{name: "csv_empty", format: FormatCSV},
+		{name: "table_empty", format: FormatTable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := WriteTodos(&out, tt.format, tt.todos, tt.columns); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, out.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != string(want) {
+				t.Errorf("output differs from %s:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+func TestWriteTodosColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		wantErr string
+	}{
+		{name: "fields and properties", columns: []string{"title", "Estimate", PositionField, SourceField}},
+		{name: "property of one database", columns: []string{"Reviewed"}},
+		{name: "unknown", columns: []string{"title", "Effort"}, wantErr: `unknown column "Effort"`},
+		{name: "empty", columns: []string{"title", " "}, wantErr: "empty column name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WriteTodos(&bytes.Buffer{}, FormatCSV, testTodos, tt.columns)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			// The error lists the properties to pick from
+			if tt.name == "unknown" && !strings.Contains(err.Error(), "Estimate, Labels, Notes, Reviewed") {
+				t.Errorf("error %q does not list the properties", err)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"json", "JSON", "Table", "ndjson", "yaml", "csv"} {
+		if _, err := ParseFormat(name); err != nil {
+			t.Errorf("ParseFormat(%q): %v", name, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil || !strings.Contains(err.Error(), FormatNames()) {
+		t.Errorf("ParseFormat(xml) error = %v, want the supported formats", err)
+	}
+}
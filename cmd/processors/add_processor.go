@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -8,46 +9,69 @@ import (
 	"github.com/briandowns/spinner"
 	tpl "github.com/caffeines/notion-todo/cmd/template"
 	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
 	"github.com/caffeines/notion-todo/service/config"
 	"github.com/caffeines/notion-todo/service/files"
 	"github.com/caffeines/notion-todo/service/notion"
-	"github.com/caffeines/notion-todo/service/utility"
 	"github.com/spf13/cobra"
 )
 
-func Add(cmd *cobra.Command, args []string) {
+func Add(cmd *cobra.Command, args []string) error {
 	todoItem := strings.Join(args, " ")
-	date, err := cmd.Flags().GetString("date")
 
 	// Validation
 	if todoItem == "" {
 		fmt.Println(tpl.RenderContainer(
 			tpl.RenderTitle("Add Todo", 80)+"\n\n"+
 				tpl.RenderError("Please provide a todo item to add.")+"\n\n"+
-				tpl.RenderHelp("Usage: todo add \"Your task description\" [--date DD-MM-YYYY]"),
+				tpl.RenderHelp("Usage: todo add \"Your task description\" [--date DATE] [--end DATE] [--tz ZONE] [--priority NAME] [--tag TAG] [--assignee USER] [--prop NAME=VALUE] [--note TEXT]"),
 			80, 24,
 		))
-		return
+		return Reported(usagef("no todo item given"))
 	}
 
+	// Parse the due date only if it is provided
+	dates := loadDateSettings()
+	due, err := dates.dueDateFlags(cmd)
 	if err != nil {
 		fmt.Println(tpl.RenderContainer(
 			tpl.RenderTitle("Add Todo", 80)+"\n\n"+
-				tpl.RenderError("Error getting date flag: "+err.Error()),
+				tpl.RenderError(err.Error())+"\n\n"+
+				tpl.RenderHelp("Examples: tomorrow, fri 17:00, next monday, in 3 days, "+string(dates.locale)),
 			80, 24,
 		))
-		return
+		return Reported(usageError(err))
 	}
 
-	// Check date format DD-MM-YYYY only if date is provided
-	if date != "" && !utility.IsValidDateFormat(date) {
+	body, err := noteFlags(cmd)
+	if err != nil {
 		fmt.Println(tpl.RenderContainer(
 			tpl.RenderTitle("Add Todo", 80)+"\n\n"+
-				tpl.RenderError("Invalid date format. Please use DD-MM-YYYY.")+"\n\n"+
-				tpl.RenderHelp("Example: 25-12-2024"),
+				tpl.RenderError(err.Error()),
 			80, 24,
 		))
-		return
+		return Reported(usageError(err))
+	}
+
+	props, err := propFlags(cmd)
+	if err != nil {
+		fmt.Println(tpl.RenderContainer(
+			tpl.RenderTitle("Add Todo", 80)+"\n\n"+
+				tpl.RenderError(err.Error()),
+			80, 24,
+		))
+		return Reported(usageError(err))
+	}
+
+	priority, _ := cmd.Flags().GetString("priority")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	assignees, _ := cmd.Flags().GetStringSlice("assignee")
+	todo := models.NewTodo{
+		Title:     todoItem,
+		Due:       due,
+		Priority:  strings.TrimSpace(priority),
+		Tags:      trimList(tags),
+		Assignees: trimList(assignees),
 	}
 
 	// Create and start spinner
@@ -58,28 +82,47 @@ func Add(cmd *cobra.Command, args []string) {
 
 	// Create todo
 	credService := config.NewCredentialSvc(
-		files.NewFileService(consts.ConfigFileName),
+		files.NewFileService(files.ConfigDir, consts.ConfigFileName),
 	)
 	notionSvc := notion.NewNotionImpl(credService)
 
-	// Convert date if provided, otherwise pass empty string
-	dateForAPI := ""
-	if date != "" {
-		dateForAPI = utility.ConvertDateToYYYYMMDD(date)
+	ctx, cancel := commandContext(cmd)
+	todo.Extra, err = encodeProps(ctx, notionSvc, props, dates)
+	if err != nil {
+		cancel()
+		s.Stop()
+		fmt.Println(tpl.RenderContainer(
+			tpl.RenderTitle("Add Todo", 80)+"\n\n"+
+				tpl.RenderError(err.Error()),
+			80, 24,
+		))
+		return Reported(err)
 	}
-
-	err = notionSvc.AddPage(todoItem, dateForAPI)
+	err = notionSvc.AddPage(ctx, todo, body)
+	cancel()
 
 	// Stop spinner
 	s.Stop()
+	var partial *notion.PartialPageError
+	if errors.As(err, &partial) {
+		// Running the command again would create a second todo
+		fmt.Println(tpl.RenderContainer(
+			tpl.RenderTitle("Add Todo", 80)+"\n\n"+
+				tpl.RenderError("Todo created, but part of its note could not be added: "+partial.Err.Error())+"\n\n"+
+				"Page: "+partial.URL+"\n\n"+
+				tpl.RenderHelp("Add the rest of the note in Notion, as running the command again creates a duplicate todo."),
+			80, 24,
+		))
+		return Reported(err)
+	}
 	if err != nil {
 		fmt.Println(tpl.RenderContainer(
 			tpl.RenderTitle("Add Todo", 80)+"\n\n"+
 				tpl.RenderError("Todo creation failed: "+err.Error())+"\n\n"+
-				tpl.RenderHelp("Check configuration: todo config"),
+				tpl.RenderHelp(errorHint(err)),
 			80, 24,
 		))
-		return
+		return Reported(err)
 	}
 
 	// Success message with minimal styling
@@ -87,13 +130,29 @@ func Add(cmd *cobra.Command, args []string) {
 		tpl.RenderSuccess("Todo added successfully!") + "\n\n" +
 		"Task: " + todoItem + "\n"
 
-	if date != "" {
-		successContent += "Date: " + date + "\n"
+	if due != nil {
+		successContent += "Date: " + dates.formatDue(*due.Start, due.End, due.TimeZone) + "\n"
 	} else {
 		successContent += "Date: No due date\n"
 	}
+	if todo.Priority != "" {
+		successContent += "Priority: " + todo.Priority + "\n"
+	}
+	if len(todo.Tags) > 0 {
+		successContent += "Tags: " + strings.Join(todo.Tags, ", ") + "\n"
+	}
+	if len(todo.Assignees) > 0 {
+		successContent += "Assignees: " + strings.Join(todo.Assignees, ", ") + "\n"
+	}
+	for _, prop := range props {
+		successContent += prop.name + ": " + prop.value + "\n"
+	}
+	if len(body) > 0 {
+		successContent += fmt.Sprintf("Note: %d block(s)\n", len(body))
+	}
 
 	successContent += "\n" + tpl.RenderHelp("Use 'todo list' to view all todos")
 
 	fmt.Println(tpl.RenderContainer(successContent, 80, 24))
+	return nil
 }
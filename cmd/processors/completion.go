@@ -0,0 +1,44 @@
+package processors
+
+import (
+	"context"
+	"time"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/statuses"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds the API call made while completing a flag
+const completionTimeout = 3 * time.Second
+
+// statusService returns the status service for the configured database
+func statusService() statuses.Statuses {
+	credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+	return statuses.NewStatusSvc(
+		notion.NewNotionImpl(credService),
+		credService,
+		files.NewFileService(files.CacheDir, consts.StatusCacheFileName),
+	)
+}
+
+// CompleteStatuses completes status flag values from the database statuses,
+// using the local cache when it is available
+func CompleteStatuses(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// Completion skips the pre-run hooks, so select the profile here
+	SelectConfig(cmd, args)
+	statusSvc := statusService()
+	options, err := statusSvc.Cached()
+	if err != nil {
+		ctx, cancel := context.WithTimeout(cmd.Context(), completionTimeout)
+		defer cancel()
+		options, err = statusSvc.GetStatuses(ctx)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return statuses.Names(options), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,30 @@
+package processors
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// commandContext returns the command context bounded by the global --timeout flag
+func commandContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return withTimeout(cmd.Context(), commandTimeout(cmd))
+}
+
+// commandTimeout returns the value of the global --timeout flag
+func commandTimeout(cmd *cobra.Command) time.Duration {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	return timeout
+}
+
+// withTimeout derives a cancellable context, bounded by timeout when it is positive
+func withTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if parent == nil {
+		parent = context.Background()
+	}
+	if timeout > 0 {
+		return context.WithTimeout(parent, timeout)
+	}
+	return context.WithCancel(parent)
+}
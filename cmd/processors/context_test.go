@@ -0,0 +1,50 @@
+package processors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCommandContext(t *testing.T) {
+	newCommand := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "list"}
+		cmd.Flags().Duration("timeout", 0, "")
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatal(err)
+		}
+		parent, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		cmd.SetContext(parent)
+		return cmd
+	}
+
+	// --timeout bounds the requests of the command
+	ctx, cancel := commandContext(newCommand("--timeout", "20ms"))
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > 20*time.Millisecond {
+		t.Errorf("deadline = %v, %v, want 20ms from now", deadline, ok)
+	}
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("error = %v, want %v", ctx.Err(), context.DeadlineExceeded)
+	}
+
+	// Without it, the command context still cancels the requests
+	cmd := newCommand()
+	ctx, cancel = commandContext(cmd)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("deadline set without --timeout")
+	}
+	parent, stop := context.WithCancel(context.Background())
+	cmd.SetContext(parent)
+	ctx, cancel = commandContext(cmd)
+	defer cancel()
+	stop()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("error = %v after the command was interrupted", ctx.Err())
+	}
+}
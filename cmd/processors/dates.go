@@ -0,0 +1,135 @@
+package processors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/spf13/cobra"
+)
+
+// dateSettings are the configured date format and time zone. Commands load
+// them once and pass them on, so formatting does not read the config again.
+type dateSettings struct {
+	locale   utility.DateLocale
+	timeZone string
+}
+
+// loadDateSettings returns the configured date format and time zone,
+// falling back to DD-MM-YYYY and no zone
+func loadDateSettings() dateSettings {
+	credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+	cfg, err := credService.GetSettings()
+	if err != nil {
+		return dateSettings{locale: utility.DefaultDateLocale}
+	}
+	locale, err := utility.ParseDateLocale(cfg.DateFormat)
+	if err != nil {
+		locale = utility.DefaultDateLocale
+	}
+	return dateSettings{locale: locale, timeZone: cfg.TimeZone}
+}
+
+// parseDate parses a date typed by the user, relative to now in local time
+func (d dateSettings) parseDate(value string) (utility.ParsedDate, error) {
+	return utility.ParseDate(value, time.Now(), d.locale)
+}
+
+// formatDue formats a due date with its optional end and time zone for
+// display in the configured format and local time
+func (d dateSettings) formatDue(start string, end, timeZone *string) string {
+	return utility.FormatDateRange(start, end, timeZone, d.locale, time.Local)
+}
+
+// entryZone returns the zone times are typed in: timeZone, else the
+// configured zone, else local time. The returned name is empty for local time.
+func (d dateSettings) entryZone(timeZone string) (string, *time.Location, error) {
+	if timeZone == "" {
+		timeZone = d.timeZone
+	}
+	if timeZone == "" {
+		return "", time.Local, nil
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return "", nil, fmt.Errorf("unknown time zone %q, use an IANA name such as Europe/Berlin", timeZone)
+	}
+	return timeZone, loc, nil
+}
+
+// dueDate builds a due date from the start, end and time zone typed by the
+// user. Times are read in timeZone, the configured zone or local time, and
+// an end given as a time alone falls on the start day. The zone is sent to
+// Notion only for date-times when one was chosen.
+func (d dateSettings) dueDate(start, end, timeZone string) (*models.DateValue, error) {
+	timeZone, loc, err := d.entryZone(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(loc)
+	startDate, err := utility.ParseDate(start, now, d.locale)
+	if err != nil {
+		return nil, err
+	}
+
+	var endDate *utility.ParsedDate
+	if end != "" {
+		parsed, err := utility.ParseDate(end, now, d.locale)
+		if hour, minute, ok := utility.ParseClock(end); ok {
+			day := startDate.Time
+			parsed = utility.ParsedDate{Time: time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc), HasTime: true}
+			err = nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parsed.HasTime != startDate.HasTime {
+			return nil, fmt.Errorf("start and end must both have a time or both be dates")
+		}
+		if parsed.Time.Before(startDate.Time) {
+			return nil, fmt.Errorf("end %q is before the start %q", end, start)
+		}
+		endDate = &parsed
+	}
+
+	format := utility.ParsedDate.Notion
+	due := &models.DateValue{}
+	if timeZone != "" && startDate.HasTime {
+		format = utility.ParsedDate.NotionInZone
+		due.TimeZone = &timeZone
+	}
+	startValue := format(startDate)
+	due.Start = &startValue
+	if endDate != nil {
+		endValue := format(*endDate)
+		due.End = &endValue
+	}
+	return due, nil
+}
+
+// dueDateFlags builds the due date from the --date (or --start), --end and
+// --tz flags, returning nil when no date was given
+func (d dateSettings) dueDateFlags(cmd *cobra.Command) (*models.DateValue, error) {
+	flags := cmd.Flags()
+	start, _ := flags.GetString("date")
+	if flags.Changed("start") {
+		if start != "" {
+			return nil, fmt.Errorf("--start and --date are the same flag, pass only one")
+		}
+		start, _ = flags.GetString("start")
+	}
+	end, _ := flags.GetString("end")
+	timeZone, _ := flags.GetString("tz")
+	if start == "" {
+		if end != "" || timeZone != "" {
+			return nil, fmt.Errorf("--end and --tz need --date or --start")
+		}
+		return nil, nil
+	}
+	return d.dueDate(start, end, timeZone)
+}
@@ -0,0 +1,94 @@
+package processors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/utility"
+)
+
+func TestDueDate(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		configured string
+		start, end string
+		timeZone   string
+		want       models.DateValue
+		wantErr    string
+	}{
+		{name: "date", start: "10-06-2025", want: models.DateValue{Start: str("2025-06-10")}},
+		{
+			name:  "date range",
+			start: "10-06-2025", end: "12-06-2025",
+			want: models.DateValue{Start: str("2025-06-10"), End: str("2025-06-12")},
+		},
+		{
+			// Dates have no time, so the zone is not sent
+			name:  "date with zone",
+			start: "10-06-2025", timeZone: "UTC",
+			want: models.DateValue{Start: str("2025-06-10")},
+		},
+		{
+			name:  "time range on one day",
+			start: "10-06-2025 9am", end: "17:30", timeZone: "UTC",
+			want: models.DateValue{Start: str("2025-06-10T09:00:00"), End: str("2025-06-10T17:30:00"), TimeZone: str("UTC")},
+		},
+		{
+			name:       "configured zone",
+			configured: "UTC",
+			start:      "10-06-2025 9am",
+			want:       models.DateValue{Start: str("2025-06-10T09:00:00"), TimeZone: str("UTC")},
+		},
+		{
+			name:       "zone flag wins",
+			configured: "Nowhere/Else",
+			start:      "10-06-2025 9am", timeZone: "UTC",
+			want: models.DateValue{Start: str("2025-06-10T09:00:00"), TimeZone: str("UTC")},
+		},
+		{name: "unknown zone", start: "10-06-2025 9am", timeZone: "Mars/Olympus", wantErr: `unknown time zone "Mars/Olympus"`},
+		{name: "end before start", start: "12-06-2025", end: "10-06-2025", wantErr: "is before the start"},
+		{name: "end time before start", start: "10-06-2025 17:00", end: "9am", timeZone: "UTC", wantErr: "is before the start"},
+		{name: "date and time mixed", start: "10-06-2025", end: "12-06-2025 9am", wantErr: "both have a time"},
+		{name: "bad end", start: "10-06-2025", end: "later", wantErr: "later"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dates := dateSettings{locale: utility.LocaleDMY, timeZone: tt.configured}
+			got, err := dates.dueDate(tt.start, tt.end, tt.timeZone)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("dueDate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("dueDate() error = %v", err)
+			}
+			assertUpdate(t, models.PageUpdate{DueDate: got}, models.PageUpdate{DueDate: &tt.want})
+		})
+	}
+}
+
+func TestFormatDue(t *testing.T) {
+	str := func(s string) *string { return &s }
+	dates := dateSettings{locale: utility.LocaleMDY}
+
+	tests := []struct {
+		start string
+		end   *string
+		want  string
+	}{
+		{start: "2025-06-10", want: "06-10-2025"},
+		{start: "2025-06-10", end: str("2025-06-12"), want: "06-10-2025 to 06-12-2025"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := dates.formatDue(tt.start, tt.end, nil); got != tt.want {
+				t.Errorf("formatDue(%q) = %q, want %q", tt.start, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package processors
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/spf13/cobra"
+)
+
+// DbAdd registers a database of the profile under an alias and detects its
+// properties
+func DbAdd(cmd *cobra.Command, args []string) error {
+	alias, databaseID := args[0], args[1]
+	if err := config.ValidateDatabaseAlias(alias); err != nil || alias == consts.DefaultDatabase {
+		if err == nil {
+			err = fmt.Errorf("the %q database is set with 'todo config'", alias)
+		}
+		return usageError(err)
+	}
+	credService := credentialService()
+	if err := credService.AddDatabase(alias, databaseID); err != nil {
+		return reportError("Failed to add database", err)
+	}
+
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	mapping, err := notion.NewNotionSvc(credService.ForDatabase(alias)).DiscoverProperties(ctx)
+	if err != nil {
+		return reportError(fmt.Sprintf("Added database '%s', but could not detect its properties", alias), err)
+	}
+	fmt.Println(tpl.RenderSuccess(fmt.Sprintf("Added database '%s' (title: %s, status: %s)", alias, mapping.Title, mapping.Status)))
+	fmt.Println(tpl.RenderHelp(fmt.Sprintf("Use it with: todo list --db %s", alias)))
+	return nil
+}
+
+// DbList prints the databases of the profile and marks the one in use
+func DbList(cmd *cobra.Command, args []string) error {
+	credService := credentialService()
+	databases, err := credService.Databases()
+	if err != nil {
+		return reportError("Failed to read databases", err)
+	}
+	if len(databases) == 0 {
+		fmt.Println("No databases configured. Set one with: todo config")
+		return nil
+	}
+	current, _ := credService.Database()
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "\tALIAS\tDATABASE")
+	for _, alias := range databaseAliases(databases) {
+		marker := ""
+		if alias == current {
+			marker = "*"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", marker, alias, databases[alias])
+	}
+	table.Flush()
+	return nil
+}
+
+// DbUse makes a database the default of the profile
+func DbUse(cmd *cobra.Command, args []string) error {
+	if err := credentialService().UseDatabase(args[0]); err != nil {
+		return reportError("Failed to switch database", err)
+	}
+	fmt.Println(tpl.RenderSuccess(fmt.Sprintf("Now using database '%s'", args[0])))
+	return nil
+}
+
+// DbRemove deletes a database alias of the profile
+func DbRemove(cmd *cobra.Command, args []string) error {
+	if args[0] == consts.DefaultDatabase {
+		return usagef("The default database cannot be removed, change it with 'todo config'")
+	}
+	if err := credentialService().RemoveDatabase(args[0]); err != nil {
+		return reportError("Failed to remove database", err)
+	}
+	fmt.Println(tpl.RenderSuccess(fmt.Sprintf("Removed database '%s'", args[0])))
+	return nil
+}
+
+// databaseAliases returns the aliases of databases, default first
+func databaseAliases(databases map[string]string) []string {
+	aliases := make([]string, 0, len(databases))
+	for alias := range databases {
+		if alias != consts.DefaultDatabase {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	if _, ok := databases[consts.DefaultDatabase]; ok {
+		aliases = append([]string{consts.DefaultDatabase}, aliases...)
+	}
+	return aliases
+}
+
+// AddDatabaseFlag registers the --db flag selecting the database of a command
+func AddDatabaseFlag(cmd *cobra.Command) {
+	cmd.Flags().String("db", "", "Database alias to use instead of the default one, see 'todo db list'")
+	_ = cmd.RegisterFlagCompletionFunc("db", CompleteDatabases)
+}
+
+// CompleteDatabases completes the database aliases of the profile
+func CompleteDatabases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	_ = SelectConfig(cmd, args)
+	databases, err := credentialService().Databases()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return databaseAliases(databases), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,244 @@
+package processors
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/caffeines/notion-todo/cmd/output"
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/statuses"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/spf13/cobra"
+)
+
+// Edit changes the title, due date, status, priority, tags and assignees of
+// a single todo
+func Edit(cmd *cobra.Command, args []string) error {
+	ref := strings.Join(args, " ")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	dates := loadDateSettings()
+	update, tags, err := editUpdate(cmd, dates)
+	var props []propAssignment
+	if err == nil {
+		props, err = propFlags(cmd)
+	}
+	if err == nil && update.IsEmpty() && tags.isEmpty() && len(props) == 0 {
+		err = fmt.Errorf("nothing to change, pass at least one of --title, --date, --clear-date, --status, --priority, --tag, --assignee or --prop")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tpl.RenderError(err.Error()))
+		fmt.Fprintln(os.Stderr, tpl.RenderHelp("Usage: todo edit <todo> [--title TEXT] [--date DATE [--end DATE] [--tz ZONE]] [--clear-date] [--status STATUS] [--priority NAME] [--tag TAG] [--untag TAG] [--assignee USER] [--unassign] [--prop NAME=VALUE]"))
+		return Reported(usageError(err))
+	}
+
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	if update.Status != nil {
+		option, options, err := statusService().Find(ctx, *update.Status)
+		if err != nil {
+			return reportError("Could not load statuses", err)
+		}
+		if option == nil {
+			return usagef("Invalid status '%s'. Valid statuses are: %s", *update.Status, strings.Join(statuses.Names(options), ", "))
+		}
+		update.Status = &option.Name
+	}
+
+	credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+	notionSvc := notion.NewNotionImpl(credService)
+
+	if update.Extra, err = encodeProps(ctx, notionSvc, props, dates); err != nil {
+		return err
+	}
+
+	matches, err := resolveTodos(ctx, notionSvc, ref, false)
+	if err != nil {
+		return err
+	}
+	todo := matches[0]
+	label := fmt.Sprintf("%s (%s)", todo.Title, utility.ShortID(todo.ID))
+	if !tags.isEmpty() {
+		newTags := tags.apply(todo.Tags)
+		update.Tags = &newTags
+	}
+
+	if dryRun {
+		fmt.Println("Would update " + label + ":")
+		for _, change := range describeUpdate(todo, update, props, dates) {
+			fmt.Println("  " + change)
+		}
+		return nil
+	}
+
+	if err := notionSvc.UpdatePage(ctx, todo.ID, update); err != nil {
+		return reportError("Failed to update "+label, err)
+	}
+	fmt.Println(tpl.RenderSuccess("Updated " + label))
+	for _, change := range describeUpdate(todo, update, props, dates) {
+		fmt.Println("  " + change)
+	}
+	return nil
+}
+
+// tagEdit lists the tags to add to and remove from a todo
+type tagEdit struct {
+	add    []string
+	remove []string
+}
+
+func (e tagEdit) isEmpty() bool {
+	return len(e.add) == 0 && len(e.remove) == 0
+}
+
+// apply returns current without the removed tags and with the added ones,
+// comparing names ignoring case
+func (e tagEdit) apply(current []string) []string {
+	tags := []string{}
+	has := func(list []string, tag string) bool {
+		for _, item := range list {
+			if strings.EqualFold(item, tag) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, tag := range current {
+		if !has(e.remove, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range e.add {
+		if !has(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// editUpdate builds the page update from the edit flags. Tag changes depend
+// on the current tags, so they are returned separately.
+func editUpdate(cmd *cobra.Command, dates dateSettings) (models.PageUpdate, tagEdit, error) {
+	var update models.PageUpdate
+	var tags tagEdit
+	flags := cmd.Flags()
+
+	if flags.Changed("title") {
+		title, _ := flags.GetString("title")
+		if strings.TrimSpace(title) == "" {
+			return update, tags, fmt.Errorf("title cannot be empty")
+		}
+		update.Title = &title
+	}
+
+	clearDate, _ := flags.GetBool("clear-date")
+	dueDate, err := dates.dueDateFlags(cmd)
+	if err != nil {
+		return update, tags, err
+	}
+	if clearDate && (dueDate != nil || flags.Changed("end") || flags.Changed("tz")) {
+		return update, tags, fmt.Errorf("--clear-date cannot be combined with --date, --end or --tz")
+	}
+	update.ClearDueDate = clearDate
+	update.DueDate = dueDate
+
+	if flags.Changed("status") {
+		status, _ := flags.GetString("status")
+		update.Status = &status
+	}
+
+	clearPriority, _ := flags.GetBool("clear-priority")
+	if flags.Changed("priority") {
+		if clearPriority {
+			return update, tags, fmt.Errorf("--clear-priority cannot be combined with --priority")
+		}
+		priority, _ := flags.GetString("priority")
+		if priority = strings.TrimSpace(priority); priority == "" {
+			return update, tags, fmt.Errorf("priority cannot be empty, use --clear-priority to remove it")
+		}
+		update.Priority = &priority
+	} else if clearPriority {
+		none := ""
+		update.Priority = &none
+	}
+
+	added, _ := flags.GetStringSlice("tag")
+	removed, _ := flags.GetStringSlice("untag")
+	tags = tagEdit{add: trimList(added), remove: trimList(removed)}
+
+	assignees, _ := flags.GetStringSlice("assignee")
+	unassign, _ := flags.GetBool("unassign")
+	if flags.Changed("assignee") {
+		if unassign {
+			return update, tags, fmt.Errorf("--unassign cannot be combined with --assignee")
+		}
+		assignees = trimList(assignees)
+		if len(assignees) == 0 {
+			return update, tags, fmt.Errorf("assignee cannot be empty, use --unassign to remove assignees")
+		}
+		update.Assignees = &assignees
+	} else if unassign {
+		update.Assignees = &[]string{}
+	}
+
+	return update, tags, nil
+}
+
+// describeUpdate lists the changes update and the --prop values make to todo
+func describeUpdate(todo models.TodoItem, update models.PageUpdate, props []propAssignment, dates dateSettings) []string {
+	var changes []string
+	if update.Title != nil {
+		changes = append(changes, fmt.Sprintf("Title: %s → %s", todo.Title, *update.Title))
+	}
+	oldDate := "(none)"
+	if todo.DueDate != nil {
+		oldDate = dates.formatDue(*todo.DueDate, todo.DueDateEnd, todo.DueTimeZone)
+	}
+	if update.ClearDueDate {
+		changes = append(changes, fmt.Sprintf("Due date: %s → (none)", oldDate))
+	}
+	if update.DueDate != nil {
+		newDate := dates.formatDue(*update.DueDate.Start, update.DueDate.End, update.DueDate.TimeZone)
+		changes = append(changes, fmt.Sprintf("Due date: %s → %s", oldDate, newDate))
+	}
+	if update.Status != nil {
+		changes = append(changes, fmt.Sprintf("Status: %s → %s", displayStatus(todo.Status), *update.Status))
+	}
+	if update.Priority != nil {
+		oldPriority, newPriority := "(none)", "(none)"
+		if todo.Priority != nil {
+			oldPriority = *todo.Priority
+		}
+		if *update.Priority != "" {
+			newPriority = *update.Priority
+		}
+		changes = append(changes, fmt.Sprintf("Priority: %s → %s", oldPriority, newPriority))
+	}
+	if update.Tags != nil {
+		changes = append(changes, fmt.Sprintf("Tags: %s → %s", displayList(todo.Tags), displayList(*update.Tags)))
+	}
+	if update.Assignees != nil {
+		changes = append(changes, fmt.Sprintf("Assignees: %s → %s", displayList(todo.Assignees), displayList(*update.Assignees)))
+	}
+	for _, prop := range props {
+		oldValue, newValue := "(none)", "(none)"
+		if name, ok := findTodoProperty(todo, prop.name); ok {
+			if text, ok := output.Text(todo.Properties[name]); ok {
+				oldValue = text
+			}
+			prop.name = name
+		}
+		if prop.value != "" {
+			newValue = prop.value
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s → %s", prop.name, oldValue, newValue))
+	}
+	return changes
+}
@@ -0,0 +1,167 @@
+package processors
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/spf13/cobra"
+)
+
+// editCommand returns a command with the flags of todo edit, parsed from args
+func editCommand(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{Use: "edit"}
+	flags := cmd.Flags()
+	flags.StringP("title", "t", "", "")
+	flags.StringP("date", "d", "", "")
+	flags.String("start", "", "")
+	flags.String("end", "", "")
+	flags.String("tz", "", "")
+	flags.Bool("clear-date", false, "")
+	flags.StringP("status", "s", "", "")
+	flags.String("priority", "", "")
+	flags.Bool("clear-priority", false, "")
+	flags.StringSlice("tag", nil, "")
+	flags.StringSlice("untag", nil, "")
+	flags.StringSlice("assignee", nil, "")
+	flags.Bool("unassign", false, "")
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestEditUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	dates := dateSettings{locale: utility.LocaleDMY}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    models.PageUpdate
+		wantErr string
+	}{
+		{name: "no flags", want: models.PageUpdate{}},
+		{
+			name: "title and status",
+			args: []string{"--title", "Buy oat milk", "-s", "Done"},
+			want: models.PageUpdate{Title: str("Buy oat milk"), Status: str("Done")},
+		},
+		{
+			name: "date",
+			args: []string{"--date", "10-06-2025"},
+			want: models.PageUpdate{DueDate: &models.DateValue{Start: str("2025-06-10")}},
+		},
+		{
+			name: "range in a zone",
+			args: []string{"--start", "10-06-2025 9am", "--end", "17:00", "--tz", "UTC"},
+			want: models.PageUpdate{DueDate: &models.DateValue{
+				Start:    str("2025-06-10T09:00:00"),
+				End:      str("2025-06-10T17:00:00"),
+				TimeZone: str("UTC"),
+			}},
+		},
+		{name: "clear date", args: []string{"--clear-date"}, want: models.PageUpdate{ClearDueDate: true}},
+		{name: "empty title", args: []string{"--title", " "}, wantErr: "title cannot be empty"},
+		{name: "clear and set date", args: []string{"--clear-date", "--date", "tomorrow"}, wantErr: "--clear-date cannot be combined"},
+		{name: "clear date with zone", args: []string{"--clear-date", "--tz", "UTC"}, wantErr: "need --date or --start"},
+		{name: "end without date", args: []string{"--end", "12-06-2025"}, wantErr: "need --date or --start"},
+		{name: "start and date", args: []string{"--date", "today", "--start", "tomorrow"}, wantErr: "same flag"},
+		{name: "bad date", args: []string{"--date", "someday"}, wantErr: "someday"},
+		{
+			name: "priority and assignees",
+			args: []string{"--priority", " high ", "--assignee", "ada, grace"},
+			want: models.PageUpdate{Priority: str("high"), Assignees: &[]string{"ada", "grace"}},
+		},
+		{
+			name: "clear priority and assignees",
+			args: []string{"--clear-priority", "--unassign"},
+			want: models.PageUpdate{Priority: str(""), Assignees: &[]string{}},
+		},
+		{name: "empty priority", args: []string{"--priority", " "}, wantErr: "use --clear-priority"},
+		{name: "set and clear priority", args: []string{"--priority", "high", "--clear-priority"}, wantErr: "--clear-priority cannot be combined"},
+		{name: "empty assignee", args: []string{"--assignee", " , "}, wantErr: "use --unassign"},
+		{name: "assign and unassign", args: []string{"--assignee", "ada", "--unassign"}, wantErr: "--unassign cannot be combined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := editUpdate(editCommand(t, tt.args...), dates)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("editUpdate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("editUpdate() error = %v", err)
+			}
+			assertUpdate(t, got, tt.want)
+		})
+	}
+}
+
+// assertUpdate compares page updates by their JSON, following pointers
+func assertUpdate(t *testing.T, got, want models.PageUpdate) {
+	t.Helper()
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("update = %s\nwant     %s", gotJSON, wantJSON)
+	}
+}
+
+func TestTagEdit(t *testing.T) {
+	cmd := editCommand(t, "--tag", "Urgent,home", "--untag", "SOMEDAY", "--tag", " ")
+	_, tags, err := editUpdate(cmd, dateSettings{locale: utility.LocaleDMY})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Tags compare ignoring case, so existing tags keep their spelling
+	got := tags.apply([]string{"Home", "someday", "work"})
+	if strings.Join(got, ",") != "Home,work,Urgent" {
+		t.Errorf("apply() = %v, want [Home work Urgent]", got)
+	}
+	if got := (tagEdit{remove: []string{"work"}}).apply([]string{"work"}); got == nil || len(got) != 0 {
+		t.Errorf("apply() removing every tag = %#v, want an empty list", got)
+	}
+}
+
+func TestDescribeUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	dates := dateSettings{locale: utility.LocaleDMY}
+	todo := models.TodoItem{
+		Title:     "Buy milk",
+		Status:    "Todo",
+		DueDate:   str("2025-06-01"),
+		Priority:  str("High"),
+		Assignees: []string{"Ada Lovelace"},
+	}
+
+	got := describeUpdate(todo, models.PageUpdate{
+		Title:     str("Buy oat milk"),
+		DueDate:   &models.DateValue{Start: str("2025-06-10"), End: str("2025-06-12")},
+		Status:    str("Done"),
+		Priority:  str(""),
+		Tags:      &[]string{"home"},
+		Assignees: &[]string{},
+	}, nil, dates)
+	want := []string{
+		"Title: Buy milk → Buy oat milk",
+		"Due date: 01-06-2025 → 10-06-2025 to 12-06-2025",
+		"Status: Todo → Done",
+		"Priority: High → (none)",
+		"Tags: (none) → home",
+		"Assignees: Ada Lovelace → (none)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("describeUpdate() = %q, want %q", got, want)
+	}
+
+	got = describeUpdate(models.TodoItem{Title: "Buy milk"}, models.PageUpdate{ClearDueDate: true}, nil, dates)
+	if len(got) != 1 || got[0] != "Due date: (none) → (none)" {
+		t.Errorf("describeUpdate() = %q", got)
+	}
+}
@@ -0,0 +1,258 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/secrets"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/caffeines/notion-todo/service/views"
+	"github.com/spf13/cobra"
+)
+
+// UsageError reports a command invoked with invalid arguments or flags,
+// which exits with consts.ExitUsage
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// usageError marks err as a usage error
+func usageError(err error) error {
+	return &UsageError{Err: err}
+}
+
+// usagef returns a usage error formatted like fmt.Errorf
+func usagef(format string, args ...interface{}) error {
+	return usageError(fmt.Errorf(format, args...))
+}
+
+// reportedError wraps an error the command already printed, so Report
+// only has to map it to an exit code
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.err
+}
+
+// Reported marks err as already printed by the command
+func Reported(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &reportedError{err: err}
+}
+
+// Report prints err as returned by cmd to stderr, followed by a pointer to
+// the command help for usage errors or a hint for the others. Errors the
+// command printed itself are not printed again.
+func Report(cmd *cobra.Command, err error) {
+	var reported *reportedError
+	if err == nil || errors.As(err, &reported) {
+		return
+	}
+	fmt.Fprintln(os.Stderr, tpl.RenderError(err.Error()))
+	var usage *UsageError
+	if errors.As(err, &usage) {
+		fmt.Fprintln(os.Stderr, tpl.RenderHelp(fmt.Sprintf("Run '%s --help' for usage.", cmd.CommandPath())))
+		return
+	}
+	if hint := Hint(err); hint != "" {
+		fmt.Fprintln(os.Stderr, tpl.RenderHelp(hint))
+	}
+}
+
+// Hint returns an actionable suggestion for err, or an empty string
+func Hint(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled):
+		return "The request was cancelled."
+	case errors.Is(err, context.DeadlineExceeded):
+		return "The request timed out. Try again or raise --timeout."
+	case isProfileNotFound(err):
+		return "Create the profile with 'todo config --profile NAME' or see 'todo profile list'."
+	case isInvalidConfigValue(err):
+		return "See 'todo config set --help' for the config keys and their values."
+	case isDatabaseConflict(err):
+		return "Unset " + consts.EnvDatabaseID + " or drop --db to pick one database."
+	case isDatabaseNotFound(err):
+		return "Register the database with 'todo db add ALIAS DATABASE_ID' or see 'todo db list'."
+	case errors.Is(err, secrets.ErrPassphraseRequired):
+		return "Set the TODO_PASSPHRASE environment variable, or run the command in a terminal to enter the passphrase."
+	case errors.Is(err, secrets.ErrWrongPassphrase):
+		return "Check the passphrase, or run 'todo config' to store the token again."
+	case errors.Is(err, secrets.ErrNotFound):
+		return "Run 'todo config' to store the token again."
+	case isUserNotFound(err):
+		return "Users are matched by the name, email or ID of a workspace member."
+	case isInvalidOption(err):
+		return "Run 'todo config --refresh' if the options were changed in Notion."
+	case notion.IsUnauthorized(err):
+		return "The integration token is invalid or lacks access. Run 'todo config' to update it."
+	case notion.IsNotFound(err):
+		return notFoundHint(err)
+	case notion.IsRateLimited(err):
+		return "Notion is rate limiting requests. Wait a moment and try again."
+	case notion.IsValidation(err):
+		return "Notion rejected the request. If the database properties changed, run 'todo config --refresh' to detect them again."
+	case notion.IsUnavailable(err):
+		return "Notion is having trouble right now. Try again later."
+	}
+	return ""
+}
+
+// notFoundHint words the hint for the kind of object that was not found
+func notFoundHint(err error) string {
+	switch notion.NotFoundObject(err) {
+	case "page":
+		return "The todo was not found. It may have been deleted, or it is not in the configured database."
+	case "block":
+		return "The page content or sub-task was not found. It may have been deleted in Notion; list it again to reload it."
+	case "database":
+		return "Database not shared with integration. In Notion open the database, choose '...' > Connections and add your integration, then check the database ID."
+	}
+	return "Not found in Notion. Check that the database is shared with your integration under '...' > Connections."
+}
+
+// ExitCode maps err to a process exit code from consts
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return consts.ExitOK
+	case isUsage(err):
+		return consts.ExitUsage
+	case errors.Is(err, context.Canceled):
+		return consts.ExitCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return consts.ExitTimeout
+	case isProfileNotFound(err), isDatabaseNotFound(err), isDatabaseConflict(err), isInvalidConfigValue(err):
+		return consts.ExitUsage
+	case isSecretUnavailable(err):
+		return consts.ExitUnauthorized
+	case isUserNotFound(err), isRefNotFound(err), isViewNotFound(err):
+		return consts.ExitNotFound
+	case isInvalidOption(err), isAmbiguousRef(err):
+		return consts.ExitUsage
+	case notion.IsUnauthorized(err):
+		return consts.ExitUnauthorized
+	case notion.IsNotFound(err):
+		return consts.ExitNotFound
+	case notion.IsRateLimited(err):
+		return consts.ExitRateLimited
+	case notion.IsValidation(err):
+		return consts.ExitValidation
+	case notion.IsUnavailable(err):
+		return consts.ExitUnavailable
+	}
+	return consts.ExitError
+}
+
+// isUsage reports whether err is a usage error
+func isUsage(err error) bool {
+	var usage *UsageError
+	return errors.As(err, &usage)
+}
+
+// isRefNotFound reports whether a todo or sub-task reference matched nothing
+func isRefNotFound(err error) bool {
+	var refErr *utility.NotFoundRefError
+	var subTaskErr *utility.SubTaskNotFoundError
+	return errors.As(err, &refErr) || errors.As(err, &subTaskErr)
+}
+
+// isAmbiguousRef reports whether a todo reference matched more than one todo
+func isAmbiguousRef(err error) bool {
+	var refErr *utility.AmbiguousRefError
+	return errors.As(err, &refErr)
+}
+
+// isViewNotFound reports whether a view name matched no saved view
+func isViewNotFound(err error) bool {
+	var viewErr *views.NotFoundError
+	return errors.As(err, &viewErr)
+}
+
+// isUserNotFound reports whether a user reference matched no workspace user
+func isUserNotFound(err error) bool {
+	var userErr *utility.UserNotFoundError
+	return errors.As(err, &userErr)
+}
+
+// isInvalidOption reports whether a value is not an option of its property
+func isInvalidOption(err error) bool {
+	var optionErr *models.InvalidOptionError
+	return errors.As(err, &optionErr)
+}
+
+// isProfileNotFound reports whether the selected config profile does not exist
+func isProfileNotFound(err error) bool {
+	var profileErr *config.ProfileNotFoundError
+	return errors.As(err, &profileErr)
+}
+
+// isDatabaseNotFound reports whether the selected database alias does not
+// exist in the profile
+func isDatabaseNotFound(err error) bool {
+	var databaseErr *config.DatabaseNotFoundError
+	return errors.As(err, &databaseErr)
+}
+
+// isDatabaseConflict reports whether a database ID was given by environment
+// variable along with another database selected by --db
+func isDatabaseConflict(err error) bool {
+	var conflictErr *config.DatabaseConflictError
+	return errors.As(err, &conflictErr)
+}
+
+// isInvalidConfigValue reports whether a config key was given a value it
+// does not accept
+func isInvalidConfigValue(err error) bool {
+	var valueErr *config.InvalidValueError
+	return errors.As(err, &valueErr)
+}
+
+// isSecretUnavailable reports whether the token could not be read from the
+// secret store: it is missing, or the passphrase is missing or wrong
+func isSecretUnavailable(err error) bool {
+	return errors.Is(err, secrets.ErrNotFound) || errors.Is(err, secrets.ErrPassphraseRequired) || errors.Is(err, secrets.ErrWrongPassphrase)
+}
+
+// errorHint returns an actionable hint for err, defaulting to the config command
+func errorHint(err error) string {
+	if hint := Hint(err); hint != "" {
+		return hint
+	}
+	return "Check configuration: todo config"
+}
+
+// errorMessage formats a failed operation for the list view, followed by
+// a hint when one is known
+func errorMessage(prefix string, err error) string {
+	msg := prefix + ": " + err.Error()
+	if hint := Hint(err); hint != "" {
+		msg += "\n" + hint
+	}
+	return msg
+}
@@ -0,0 +1,76 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/caffeines/notion-todo/service/views"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "nil", err: nil, want: consts.ExitOK},
+		{name: "plain", err: errors.New("boom"), want: consts.ExitError},
+		{name: "usage", err: usagef("--all-dbs cannot be used with --db"), want: consts.ExitUsage},
+		{name: "reported usage", err: Reported(usageError(errors.New("no todo item given"))), want: consts.ExitUsage},
+		{name: "cancelled", err: fmt.Errorf("fetch: %w", context.Canceled), want: consts.ExitCancelled},
+		{name: "timeout", err: context.DeadlineExceeded, want: consts.ExitTimeout},
+		{name: "todo not found", err: Reported(&utility.NotFoundRefError{Ref: "report"}), want: consts.ExitNotFound},
+		{name: "sub-task not found", err: &utility.SubTaskNotFoundError{Ref: "9"}, want: consts.ExitNotFound},
+		{name: "ambiguous todo", err: &utility.AmbiguousRefError{Ref: "report"}, want: consts.ExitUsage},
+		{name: "view not found", err: &views.NotFoundError{Name: "Someday"}, want: consts.ExitNotFound},
+		{name: "view flag", err: usageError(&views.NotFoundError{Name: "Someday"}), want: consts.ExitUsage},
+		{name: "profile not found", err: &config.ProfileNotFoundError{Name: "work"}, want: consts.ExitUsage},
+		{name: "unauthorized", err: &notion.APIError{Status: http.StatusUnauthorized, Code: notion.CodeUnauthorized}, want: consts.ExitUnauthorized},
+		{name: "page not found", err: Reported(&notion.APIError{Status: http.StatusNotFound, Code: notion.CodeObjectNotFound}), want: consts.ExitNotFound},
+		{name: "rate limited", err: &notion.APIError{Status: http.StatusTooManyRequests}, want: consts.ExitRateLimited},
+		{name: "validation", err: &notion.APIError{Status: http.StatusBadRequest, Code: notion.CodeValidation}, want: consts.ExitValidation},
+		{name: "unavailable", err: &notion.APIError{Status: http.StatusBadGateway}, want: consts.ExitUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHint(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "plain", err: errors.New("boom"), want: ""},
+		{name: "unauthorized", err: &notion.APIError{Status: http.StatusUnauthorized, Code: notion.CodeUnauthorized}, want: "integration token is invalid"},
+		{name: "database not shared", err: &notion.APIError{Status: http.StatusNotFound, Code: notion.CodeObjectNotFound, Message: "Could not find database with ID: x."}, want: "Database not shared with integration"},
+		{name: "page not found", err: fmt.Errorf("fetch: %w", &notion.APIError{Status: http.StatusNotFound, Message: "Could not find page with ID: x."}), want: "The todo was not found"},
+		{name: "rate limited", err: &notion.APIError{Status: http.StatusTooManyRequests}, want: "rate limiting"},
+		{name: "validation", err: &notion.APIError{Status: http.StatusBadRequest, Code: notion.CodeValidation}, want: "todo config --refresh"},
+		{name: "timeout", err: context.DeadlineExceeded, want: "--timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Hint(tt.err)
+			if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+				t.Errorf("Hint(%v) = %q, want it to contain %q", tt.err, got, tt.want)
+			}
+		})
+	}
+	if got := errorHint(errors.New("boom")); got != "Check configuration: todo config" {
+		t.Errorf("errorHint() = %q, want the config command", got)
+	}
+}
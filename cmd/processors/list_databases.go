@@ -0,0 +1,91 @@
+package processors
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/caffeines/notion-todo/cmd/output"
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/spf13/cobra"
+)
+
+// printAllTodos writes the todos matching filter from every database of the
+// profile, queried concurrently, with the alias of each in the source
+// column. Databases that fail are reported after the others are written.
+func printAllTodos(cmd *cobra.Command, format output.Format, filter models.TodoFilter, columns []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	credService := credentialService()
+	databases, err := credService.Databases()
+	if err != nil {
+		return reportError("Failed to read databases", err)
+	}
+	aliases := databaseAliases(databases)
+	if len(aliases) == 0 {
+		err := usagef("No databases configured")
+		fmt.Fprintln(os.Stderr, tpl.RenderError(err.Error()))
+		fmt.Fprintln(os.Stderr, tpl.RenderHelp("Check configuration: todo config"))
+		return Reported(err)
+	}
+
+	results := make([][]models.TodoItem, len(aliases))
+	errs := make([]error, len(aliases))
+	var wg sync.WaitGroup
+	for i, alias := range aliases {
+		wg.Add(1)
+		go func(i int, alias string) {
+			defer wg.Done()
+			notionSvc := notion.NewNotionSvc(credService.ForDatabase(alias))
+			results[i], errs[i] = notionSvc.QueryPages(ctx, filter)
+		}(i, alias)
+	}
+	wg.Wait()
+
+	todos := []models.TodoItem{}
+	var failed error
+	for i, alias := range aliases {
+		if errs[i] != nil {
+			if failed == nil {
+				failed = errs[i]
+			}
+			continue
+		}
+		for _, todo := range results[i] {
+			todo.Source = alias
+			todos = append(todos, todo)
+		}
+	}
+
+	if len(columns) == 0 {
+		// Row numbers only refer to todos of a single database
+		for _, column := range output.DefaultColumns(format) {
+			if len(columns) == 0 {
+				columns = append(columns, output.SourceField)
+			}
+			if column != output.PositionField {
+				columns = append(columns, column)
+			}
+		}
+	}
+	if err := output.CheckColumns(todos, columns); err != nil {
+		return usageError(err)
+	}
+	if err := output.WriteTodos(os.Stdout, format, todos, columns); err != nil {
+		return fmt.Errorf("Failed to write output: %w", err)
+	}
+
+	if failed != nil {
+		for i, alias := range aliases {
+			if errs[i] != nil {
+				fmt.Fprintln(os.Stderr, tpl.RenderError(fmt.Sprintf("Failed to fetch todos from '%s': %s", alias, errs[i].Error())))
+			}
+		}
+		fmt.Fprintln(os.Stderr, tpl.RenderHelp(errorHint(failed)))
+		return Reported(failed)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/utility"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Fields of the inline edit form
+const (
+	editFieldTitle = iota
+	editFieldDate
+	editFieldCount
+)
+
+// editForm is the inline title and due date editor of the list view
+type editForm struct {
+	todoID string
+	dates  dateSettings
+	field  int
+	title  []rune
+	// date is parsed like --date; an empty date clears the due date
+	date []rune
+	err  string
+}
+
+// Edit message for async page updates
+type editMsg struct {
+	success bool
+	todoID  string
+	update  models.PageUpdate
+	message string
+}
+
+// newEditForm opens the edit form prefilled with the values of todo
+func newEditForm(todo Todo, dates dateSettings) *editForm {
+	return &editForm{
+		todoID: todo.ID,
+		dates:  dates,
+		title:  []rune(todo.Title),
+		date:   []rune(dates.displayDate(todo)),
+	}
+}
+
+// displayDate formats the due date of todo for editing in the configured
+// format, showing times in the zone they are typed in
+func (d dateSettings) displayDate(todo Todo) string {
+	if todo.DueDate == nil || *todo.DueDate == "" {
+		return ""
+	}
+	zone := ""
+	if todo.DueTimeZone != nil {
+		zone = *todo.DueTimeZone
+	}
+	_, loc, err := d.entryZone(zone)
+	if err != nil {
+		loc = time.Local
+	}
+	return utility.FormatDateRange(*todo.DueDate, todo.DueDateEnd, todo.DueTimeZone, d.locale, loc)
+}
+
+// input returns the text of the focused field
+func (f *editForm) input() *[]rune {
+	if f.field == editFieldDate {
+		return &f.date
+	}
+	return &f.title
+}
+
+// pageUpdate returns the changes made in the form to todo
+func (f *editForm) pageUpdate(todo Todo) (models.PageUpdate, error) {
+	var update models.PageUpdate
+
+	title := strings.TrimSpace(string(f.title))
+	if title == "" {
+		return update, fmt.Errorf("title cannot be empty")
+	}
+	if title != todo.Title {
+		update.Title = &title
+	}
+
+	date := strings.TrimSpace(string(f.date))
+	switch {
+	case date == f.dates.displayDate(todo):
+	case date == "":
+		update.ClearDueDate = true
+	default:
+		// A range is typed as "<start> to <end>"
+		start, end, _ := strings.Cut(date, " to ")
+		zone := ""
+		if todo.DueTimeZone != nil {
+			zone = *todo.DueTimeZone
+		}
+		due, err := f.dates.dueDate(strings.TrimSpace(start), strings.TrimSpace(end), zone)
+		if err != nil {
+			return update, err
+		}
+		update.DueDate = due
+	}
+	return update, nil
+}
+
+// updateEdit handles a key press while the edit form is open
+func (m model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	form := m.edit
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.edit = nil
+	case tea.KeyTab, tea.KeyDown:
+		form.field = (form.field + 1) % editFieldCount
+	case tea.KeyShiftTab, tea.KeyUp:
+		form.field = (form.field + editFieldCount - 1) % editFieldCount
+	case tea.KeyBackspace:
+		if input := form.input(); len(*input) > 0 {
+			*input = (*input)[:len(*input)-1]
+		}
+	case tea.KeyCtrlU:
+		*form.input() = nil
+	case tea.KeySpace:
+		*form.input() = append(*form.input(), ' ')
+	case tea.KeyRunes:
+		*form.input() = append(*form.input(), msg.Runes...)
+	case tea.KeyEnter:
+		todo := m.todoByID(form.todoID)
+		if todo == nil {
+			m.edit = nil
+			return m, nil
+		}
+		update, err := form.pageUpdate(*todo)
+		if err != nil {
+			form.err = err.Error()
+			return m, nil
+		}
+		m.edit = nil
+		if update.IsEmpty() {
+			m.message = "Nothing changed"
+			m.messageTime = time.Now()
+			return m, nil
+		}
+		m.updating = true
+		return m, editTodoCmd(m.ctx, m.timeout, todo.ID, update)
+	case tea.KeyCtrlC:
+		m.cancel()
+		return m, tea.Quit
+	}
+	if m.edit != nil && msg.Type != tea.KeyEnter {
+		m.edit.err = ""
+	}
+	return m, nil
+}
+
+// applyEdit updates the local todo after a successful edit
+func (m *model) applyEdit(msg editMsg) {
+	todo := m.todoByID(msg.todoID)
+	if todo == nil {
+		return
+	}
+	if msg.update.Title != nil {
+		todo.Title = *msg.update.Title
+	}
+	if msg.update.ClearDueDate {
+		todo.DueDate, todo.DueDateEnd, todo.DueTimeZone = nil, nil, nil
+	}
+	if due := msg.update.DueDate; due != nil {
+		todo.DueDate, todo.DueDateEnd, todo.DueTimeZone = due.Start, due.End, due.TimeZone
+	}
+}
+
+// todoByID returns the listed todo with id, if any
+func (m model) todoByID(id string) *Todo {
+	for i := range m.todos {
+		if m.todos[i].ID == id {
+			return &m.todos[i]
+		}
+	}
+	return nil
+}
+
+// viewEdit renders the edit form
+func (m model) viewEdit() string {
+	form := m.edit
+	field := func(label string, index int, value []rune) string {
+		line := label + string(value)
+		if form.field == index {
+			return tpl.SelectedItemStyle.Render("> " + line + "▏")
+		}
+		return tpl.ItemStyle.Render("  " + line)
+	}
+
+	content := tpl.ConfirmationStyle.Render("Edit todo") + "\n\n" +
+		field("Title: ", editFieldTitle, form.title) + "\n" +
+		field("Due:   ", editFieldDate, form.date) + "\n"
+	if form.err != "" {
+		content += "\n" + tpl.MessageStyle.Render(form.err) + "\n"
+	}
+	content += "\n" + tpl.HelpStyle.Render("tab: field • enter: save • esc: cancel") + "\n" +
+		tpl.HelpStyle.Render("Due: e.g. fri, in 3 days, mon 9:00 to 10:30; empty removes it")
+
+	return getConfirmationContainerStyle(m.width).Render(content)
+}
+
+// Update the title and due date using Notion API
+func editTodoCmd(ctx context.Context, timeout time.Duration, todoID string, update models.PageUpdate) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
+		credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+		notionSvc := notion.NewNotionImpl(credService)
+
+		if err := notionSvc.UpdatePage(ctx, todoID, update); err != nil {
+			return editMsg{
+				success: false,
+				todoID:  todoID,
+				message: errorMessage("Failed to update", err),
+			}
+		}
+		return editMsg{
+			success: true,
+			todoID:  todoID,
+			update:  update,
+			message: "Todo updated",
+		}
+	}
+}
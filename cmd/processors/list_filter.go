@@ -0,0 +1,139 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/caffeines/notion-todo/cmd/output"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/statuses"
+	"github.com/spf13/cobra"
+)
+
+// filterDateFlags maps the date flags of the filter to their fields
+func filterDateFlags(filter *models.TodoFilter) map[string]*string {
+	return map[string]*string{
+		"due-before":    &filter.DueBefore,
+		"due-after":     &filter.DueAfter,
+		"due-on":        &filter.DueOn,
+		"created-since": &filter.CreatedSince,
+		"edited-since":  &filter.EditedSince,
+	}
+}
+
+// applyFilterFlags overrides filter with the filter flags set on the command.
+// Dates are kept as entered, so saved views stay relative to the day they
+// are used; resolveFilter converts them before querying.
+func applyFilterFlags(cmd *cobra.Command, filter models.TodoFilter) (models.TodoFilter, error) {
+	flags := cmd.Flags()
+	if flags.Changed("status") {
+		filter.Statuses, _ = flags.GetStringSlice("status")
+	}
+	if flags.Changed("title") {
+		filter.TitleContains, _ = flags.GetString("title")
+	}
+	if flags.Changed("priority") {
+		priorities, _ := flags.GetStringSlice("priority")
+		filter.Priorities = trimList(priorities)
+	}
+	if flags.Changed("tag") {
+		tags, _ := flags.GetStringSlice("tag")
+		filter.Tags = trimList(tags)
+	}
+	if flags.Changed("assignee") {
+		assignees, _ := flags.GetStringSlice("assignee")
+		filter.Assignees = trimList(assignees)
+	}
+	if flags.Changed("overdue") {
+		filter.Overdue, _ = flags.GetBool("overdue")
+	}
+	if flags.Changed("no-due") {
+		filter.NoDueDate, _ = flags.GetBool("no-due")
+	}
+	for flag, value := range filterDateFlags(&filter) {
+		if flags.Changed(flag) {
+			*value, _ = flags.GetString(flag)
+		}
+	}
+	if flags.Changed("sort") {
+		specs, _ := flags.GetStringSlice("sort")
+		filter.Sorts = nil
+		for _, spec := range specs {
+			sort, err := models.ParseTodoSort(spec)
+			if err != nil {
+				return filter, err
+			}
+			filter.Sorts = append(filter.Sorts, sort)
+		}
+	}
+	return filter, nil
+}
+
+// resolveFilter converts the dates of filter to YYYY-MM-DD and validates it
+func resolveFilter(filter models.TodoFilter, dates dateSettings) (models.TodoFilter, error) {
+	filter.Statuses = append([]string(nil), filter.Statuses...)
+	filter.Priorities = append([]string(nil), filter.Priorities...)
+	for flag, value := range filterDateFlags(&filter) {
+		if *value == "" {
+			continue
+		}
+		parsed, err := dates.parseDate(*value)
+		if err != nil {
+			return filter, fmt.Errorf("--%s: %v", flag, err)
+		}
+		*value = parsed.Notion()
+	}
+	return filter, filter.Validate()
+}
+
+// normalizeStatuses replaces the statuses of filter with their exact names in
+// the database and returns the database statuses. It fails when a status does
+// not exist; when statuses cannot be loaded the filter is left unchanged.
+func normalizeStatuses(ctx context.Context, statusSvc statuses.Statuses, filter *models.TodoFilter) ([]models.StatusOption, error) {
+	statusOptions, err := statusSvc.GetStatuses(ctx)
+	if err != nil {
+		return nil, nil
+	}
+	for i, status := range filter.Statuses {
+		option, options, err := statusSvc.Find(ctx, status)
+		if err != nil {
+			continue
+		}
+		if option == nil {
+			return nil, usagef("Invalid status filter: '%s'. Valid statuses are: %s", status, strings.Join(statuses.Names(options), ", "))
+		}
+		filter.Statuses[i] = option.Name // Use the exact option name
+		statusOptions = options
+	}
+	return statusOptions, nil
+}
+
+// AddFilterFlags registers the filter and sort flags shared by list and view save
+func AddFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceP("status", "s", nil, "Filter items by status, as named in the database (repeat or separate with commas to match any)")
+	cmd.Flags().StringP("title", "t", "", "Filter items whose title contains the text")
+	cmd.Flags().StringSlice("priority", nil, "Filter items by priority (repeat or separate with commas to match any)")
+	cmd.Flags().StringSlice("tag", nil, "Filter items having a tag (repeat or separate with commas to require all)")
+	cmd.Flags().StringSlice("assignee", nil, "Filter items assigned to a user by name, email or ID (repeat to match any)")
+	cmd.Flags().String("due-before", "", "Filter items due before a date (e.g. today, fri, in 3 days, DD-MM-YYYY)")
+	cmd.Flags().String("due-after", "", "Filter items due after a date (e.g. today, fri, in 3 days, DD-MM-YYYY)")
+	cmd.Flags().String("due-on", "", "Filter items due on a date (e.g. today, fri, in 3 days, DD-MM-YYYY)")
+	cmd.Flags().Bool("overdue", false, "Filter items past their due date that are not complete")
+	cmd.Flags().Bool("no-due", false, "Filter items without a due date")
+	cmd.Flags().String("created-since", "", "Filter items created on or after a date (e.g. today, fri, in 3 days, DD-MM-YYYY)")
+	cmd.Flags().String("edited-since", "", "Filter items edited on or after a date (e.g. today, fri, in 3 days, DD-MM-YYYY)")
+	cmd.Flags().StringSlice("sort", nil, "Sort by due, created, edited or title, optionally with :desc (repeat for tie-breakers)")
+	cmd.Flags().StringSlice("columns", nil, "Fields or database properties shown in table and CSV output; fields are "+strings.Join(output.Fields, ", ")+" and "+output.PositionField)
+	_ = cmd.RegisterFlagCompletionFunc("status", CompleteStatuses)
+	_ = cmd.RegisterFlagCompletionFunc("columns", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return append([]string{output.PositionField}, output.Fields...), cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("sort", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var specs []string
+		for _, key := range models.SortKeys {
+			specs = append(specs, key, key+":desc")
+		}
+		return specs, cobra.ShellCompDirectiveNoFileComp
+	})
+}
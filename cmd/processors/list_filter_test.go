@@ -0,0 +1,71 @@
+package processors
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/spf13/cobra"
+)
+
+// filterCommand returns a command with the filter flags, parsed from args
+func filterCommand(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{Use: "list"}
+	AddFilterFlags(cmd)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestApplyView(t *testing.T) {
+	view := models.View{
+		Name: "This week",
+		Filter: models.TodoFilter{
+			Statuses:  []string{"Not started"},
+			Tags:      []string{"work"},
+			DueBefore: "in 7 days",
+			Sorts:     []models.TodoSort{{Key: "due"}},
+		},
+	}
+	saved := view.Filter
+
+	// Flags replace the fields they set and keep the rest of the view
+	cmd := filterCommand(t, "--title", "report", "--due-before", "tomorrow", "--sort", "title:desc")
+	filter, err := applyFilterFlags(cmd, view.Filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.TodoFilter{
+		Statuses:      []string{"Not started"},
+		Tags:          []string{"work"},
+		TitleContains: "report",
+		DueBefore:     "tomorrow",
+		Sorts:         []models.TodoSort{{Key: "title", Descending: true}},
+	}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("filter = %+v, want %+v", filter, want)
+	}
+
+	// Dates resolve on the day the view is used, leaving the view relative
+	resolved, err := resolveFilter(view.Filter, dateSettings{locale: utility.DefaultDateLocale})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Now().AddDate(0, 0, 7).Format("2006-01-02"); resolved.DueBefore != want {
+		t.Errorf("due before = %q, want %q", resolved.DueBefore, want)
+	}
+	resolved.Statuses[0] = "Done"
+	if !reflect.DeepEqual(view.Filter, saved) {
+		t.Errorf("the view changed to %+v", view.Filter)
+	}
+
+	_, err = resolveFilter(models.TodoFilter{DueOn: "someday"}, dateSettings{locale: utility.DefaultDateLocale})
+	if err == nil || !strings.HasPrefix(err.Error(), "--due-on:") {
+		t.Errorf("invalid date: error = %v, want it to name --due-on", err)
+	}
+}
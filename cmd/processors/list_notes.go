@@ -0,0 +1,197 @@
+package processors
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/markdown"
+	"github.com/caffeines/notion-todo/service/notion"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// notesPane shows the page body of a todo and appends notes to it
+type notesPane struct {
+	todoID  string
+	title   string
+	body    string // Page body rendered as Markdown
+	loading bool
+	saving  bool
+	input   []rune
+	err     string
+}
+
+// Notes message for async page body loads
+type notesMsg struct {
+	success bool
+	todoID  string
+	body    string
+	message string
+}
+
+// Note message for async note appends
+type noteAddedMsg struct {
+	success bool
+	todoID  string
+	message string
+}
+
+// newNotesPane opens the notes of todo and starts loading them
+func newNotesPane(todo Todo) *notesPane {
+	return &notesPane{todoID: todo.ID, title: todo.Title, loading: true}
+}
+
+// updateNotes handles a key press while the notes pane is open
+func (m model) updateNotes(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pane := m.notes
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.notes = nil
+		return m, nil
+	case tea.KeyCtrlC:
+		m.cancel()
+		return m, tea.Quit
+	}
+	if pane.saving {
+		return m, nil
+	}
+
+	pane.err = ""
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if len(pane.input) > 0 {
+			pane.input = pane.input[:len(pane.input)-1]
+		}
+	case tea.KeyCtrlU:
+		pane.input = nil
+	case tea.KeySpace:
+		pane.input = append(pane.input, ' ')
+	case tea.KeyRunes:
+		pane.input = append(pane.input, msg.Runes...)
+	case tea.KeyEnter:
+		text := strings.TrimSpace(string(pane.input))
+		if text == "" {
+			return m, nil
+		}
+		pane.saving = true
+		return m, appendNoteCmd(m.ctx, m.timeout, pane.todoID, text)
+	}
+	return m, nil
+}
+
+// updateNotesMsg applies the result of a notes load or append
+func (m model) updateNotesMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case notesMsg:
+		if m.notes == nil || m.notes.todoID != msg.todoID {
+			return m, nil
+		}
+		m.notes.loading = false
+		if msg.success {
+			m.notes.body = msg.body
+		} else {
+			m.notes.err = msg.message
+		}
+	case noteAddedMsg:
+		if m.notes == nil || m.notes.todoID != msg.todoID {
+			m.message = msg.message
+			m.messageTime = time.Now()
+			return m, nil
+		}
+		m.notes.saving = false
+		if !msg.success {
+			m.notes.err = msg.message
+			return m, nil
+		}
+		m.notes.input = nil
+		m.notes.loading = true
+		return m, loadNotesCmd(m.ctx, m.timeout, msg.todoID)
+	}
+	return m, nil
+}
+
+// viewNotes renders the notes pane, keeping the end of long notes in view
+func (m model) viewNotes() string {
+	pane := m.notes
+	titleStyle := getTitleStyle(m.width)
+
+	body := pane.body
+	switch {
+	case pane.loading:
+		body = tpl.UpdatingStyle.Render("Loading notes...")
+	case strings.TrimSpace(body) == "":
+		body = tpl.EmptyStateStyle.Render("No notes yet")
+	default:
+		lines := strings.Split(strings.TrimRight(body, "\n"), "\n")
+		// Leave room for the title, input and help lines
+		if maxLines := m.height - 12; maxLines > 0 && len(lines) > maxLines {
+			lines = append([]string{tpl.HelpStyle.Render("...")}, lines[len(lines)-maxLines:]...)
+		}
+		for i, line := range lines {
+			lines[i] = truncateText(line, m.width-6)
+		}
+		body = strings.Join(lines, "\n")
+	}
+
+	input := tpl.SelectedItemStyle.Render("> " + string(pane.input) + "▏")
+	if pane.saving {
+		input = tpl.UpdatingStyle.Render("Saving note...")
+	}
+	content := titleStyle.Render(truncateText(pane.title, m.width-8)) + "\n\n" +
+		body + "\n\n" + input + "\n"
+	if pane.err != "" {
+		content += tpl.MessageStyle.Render(pane.err) + "\n"
+	}
+	content += "\n" + tpl.HelpStyle.Render("enter: append note • esc: back")
+
+	return getContainerStyle(m.width, m.height).Render(content)
+}
+
+// Load the page body of a todo using Notion API
+func loadNotesCmd(ctx context.Context, timeout time.Duration, todoID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
+		credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+		notionSvc := notion.NewNotionImpl(credService)
+
+		blocks, err := notionSvc.GetBlocks(ctx, todoID)
+		if err != nil {
+			return notesMsg{
+				success: false,
+				todoID:  todoID,
+				message: errorMessage("Failed to load notes", err),
+			}
+		}
+		body := ""
+		if len(blocks) > 0 {
+			body = markdown.Render(blocks)
+		}
+		return notesMsg{success: true, todoID: todoID, body: body}
+	}
+}
+
+// Append a note to the page body using Notion API
+func appendNoteCmd(ctx context.Context, timeout time.Duration, todoID, text string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
+		credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+		notionSvc := notion.NewNotionImpl(credService)
+
+		if err := notionSvc.AppendBlocks(ctx, todoID, markdown.ToBlocks(text)); err != nil {
+			return noteAddedMsg{
+				success: false,
+				todoID:  todoID,
+				message: errorMessage("Failed to add note", err),
+			}
+		}
+		return noteAddedMsg{success: true, todoID: todoID, message: "Note added"}
+	}
+}
@@ -1,78 +1,96 @@
 package processors
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/caffeines/notion-todo/cmd/output"
 	tpl "github.com/caffeines/notion-todo/cmd/template"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 
 	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
 	"github.com/caffeines/notion-todo/service/config"
 	"github.com/caffeines/notion-todo/service/files"
 	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/statuses"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/caffeines/notion-todo/service/views"
 	"github.com/charmbracelet/lipgloss"
 )
 
 // Todo represents a todo item.
 type Todo struct {
-	ID      string
-	Title   string
-	Status  string
-	DueDate *string
+	ID          string
+	Title       string
+	Status      string
+	StatusGroup string
+	DueDate     *string
+	DueDateEnd  *string
+	DueTimeZone *string
+	Priority    *string
+	// PriorityColor is the Notion color of the priority option
+	PriorityColor string
+	Tags          []string
+	Assignees     []string
 }
 
-// Fetch todos command for async operations
-func fetchTodosCmd(status string) tea.Cmd {
+// Fetch todos command for async operations. Todos are fetched one Notion
+// result page at a time; a non-empty cursor continues a previous fetch.
+func fetchTodosCmd(ctx context.Context, timeout time.Duration, filter models.TodoFilter, cursor string) tea.Cmd {
 	return func() tea.Msg {
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
 		// Try to fetch from Notion first
-		credService := config.NewCredentialSvc(files.NewFileService(consts.ConfigFileName))
+		credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
 		notionSvc := notion.NewNotionImpl(credService)
 
-		todos, err := notionSvc.QueryPages(status, "")
+		page, err := notionSvc.QueryPagesCursor(ctx, filter, cursor)
 		if err != nil {
 			return refreshMsg{
 				success: false,
 				todos:   nil,
-				message: "Failed to load todos: " + err.Error(),
+				append:  cursor != "",
+				message: errorMessage("Failed to load todos", err),
 			}
 		}
 
-		// Convert Notion todos to local Todo format
-		var result []Todo
-		for _, todo := range todos {
-			// Map Notion status to local status names
-			localStatus := todo.Status
-			switch todo.Status {
-			case "Todo":
-				localStatus = "Pending"
-			case "In Progress":
-				localStatus = "In Progress"
-			case "Done":
-				localStatus = "Done"
-			}
-
-			result = append(result, Todo{
-				ID:      todo.ID,
-				Title:   todo.Title,
-				Status:  localStatus,
-				DueDate: todo.DueDate,
-			})
-		}
-
 		return refreshMsg{
-			success: true,
-			todos:   result,
-			message: fmt.Sprintf("Loaded %d todos", len(result)),
+			success:    true,
+			todos:      toLocalTodos(page.Todos),
+			append:     cursor != "",
+			nextCursor: page.NextCursor,
+			hasMore:    page.HasMore,
 		}
 	}
 }
 
+// toLocalTodos converts Notion todos to local Todo format
+func toLocalTodos(todos []models.TodoItem) []Todo {
+	var result []Todo
+	for _, todo := range todos {
+		result = append(result, Todo{
+			ID:            todo.ID,
+			Title:         todo.Title,
+			Status:        todo.Status,
+			StatusGroup:   todo.StatusGroup,
+			DueDate:       todo.DueDate,
+			DueDateEnd:    todo.DueDateEnd,
+			DueTimeZone:   todo.DueTimeZone,
+			Priority:      todo.Priority,
+			PriorityColor: todo.PriorityColor,
+			Tags:          todo.Tags,
+			Assignees:     todo.Assignees,
+		})
+	}
+	return result
+}
+
 // Status update message for async operations
 type statusUpdateMsg struct {
 	success   bool
@@ -88,20 +106,30 @@ type deleteMsg struct {
 	message string
 }
 
-// Refresh message for async refresh operations
+// Refresh message for async refresh operations. Each message carries one
+// page of results; hasMore signals that another page should be fetched.
 type refreshMsg struct {
-	success bool
-	todos   []Todo
-	message string
+	success    bool
+	todos      []Todo
+	append     bool
+	nextCursor string
+	hasMore    bool
+	message    string
 }
 
 // Bubble Tea model
 type model struct {
+	// ctx is cancelled when the program quits, aborting in-flight requests
+	ctx                context.Context
+	cancel             context.CancelFunc
+	timeout            time.Duration
 	todos              []Todo
 	cursor             int
+	statuses           []models.StatusOption
 	statusList         []string
 	updating           bool
 	refreshing         bool
+	loadingMore        bool
 	message            string
 	messageTime        time.Time
 	showConfirmation   bool
@@ -110,7 +138,17 @@ type model struct {
 	pendingNewStatus   string
 	pendingOldStatus   string
 	pendingDeleteTitle string
-	statusFilter       string
+	dates              dateSettings // Date format and zone, read once at start
+	edit               *editForm    // Open inline edit form, if any
+	notes              *notesPane   // Open notes of a todo, if any
+	subTasks           map[string][]models.SubTask
+	subTaskErrors      map[string]string // Sub-task load failures by todo ID
+	expanded           string            // ID of the todo whose sub-tasks are shown
+	subCursor          int               // Selected sub-task of the expanded todo, -1 for the todo
+	filter             models.TodoFilter
+	baseFilter         models.TodoFilter // Filter from the command line flags
+	views              []models.View
+	viewName           string // Name of the active saved view, empty for flags
 	width              int
 	height             int
 	errorMsg           string
@@ -169,11 +207,16 @@ func getConfirmationContainerStyle(width int) lipgloss.Style {
 		Align(lipgloss.Center)
 }
 
-func initialModel(statusFilter string) model {
+func initialModel(ctx context.Context, timeout time.Duration, filter models.TodoFilter, statusOptions []models.StatusOption, savedViews []models.View, viewName string, dates dateSettings) model {
+	ctx, cancel := context.WithCancel(ctx)
 	return model{
+		ctx:                ctx,
+		cancel:             cancel,
+		timeout:            timeout,
 		todos:              []Todo{}, // Start with empty todos
 		cursor:             0,
-		statusList:         []string{"Pending", "In Progress", "Done"}, // Use actual status values that match the dummy data
+		statuses:           statusOptions,
+		statusList:         statuses.Names(statusOptions), // Status cycle order from the database schema
 		updating:           false,
 		refreshing:         true, // Set to true to show loading state
 		message:            "Loading todos...",
@@ -184,42 +227,41 @@ func initialModel(statusFilter string) model {
 		pendingNewStatus:   "",
 		pendingOldStatus:   "",
 		pendingDeleteTitle: "",
-		statusFilter:       statusFilter,
+		filter:             filter,
+		baseFilter:         filter,
+		views:              savedViews,
+		viewName:           viewName,
+		dates:              dates,
+		subTasks:           map[string][]models.SubTask{},
+		subTaskErrors:      map[string]string{},
+		subCursor:          -1,
 		width:              80, // Default width
 		height:             24, // Default height
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return fetchTodosCmd(m.statusFilter)
+	return fetchTodosCmd(m.ctx, m.timeout, m.filter, "")
 }
 
 // Update status using Notion API
-func updateStatusCmd(todoID, newStatus string) tea.Cmd {
+func updateStatusCmd(ctx context.Context, timeout time.Duration, todoID, newStatus string) tea.Cmd {
 	return func() tea.Msg {
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
 		// Initialize services
-		credService := config.NewCredentialSvc(files.NewFileService(consts.ConfigFileName))
+		credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
 		notionSvc := notion.NewNotionImpl(credService)
 
-		// Map local status to Notion status names
-		notionStatus := newStatus
-		switch newStatus {
-		case "Pending":
-			notionStatus = "Todo"
-		case "In Progress":
-			notionStatus = "In Progress"
-		case "Done":
-			notionStatus = "Done"
-		}
-
 		// Call Notion API to update status
-		err := notionSvc.UpdatePageStatus(todoID, notionStatus)
+		err := notionSvc.UpdatePageStatus(ctx, todoID, newStatus)
 		if err != nil {
 			return statusUpdateMsg{
 				success:   false,
 				todoID:    todoID,
 				newStatus: newStatus,
-				message:   fmt.Sprintf("Failed to update: %v", err),
+				message:   errorMessage("Failed to update", err),
 			}
 		}
 
@@ -232,50 +274,9 @@ func updateStatusCmd(todoID, newStatus string) tea.Cmd {
 	}
 }
 
-// Simulate async refresh
-func refreshTodosCmd(statusFilter string) tea.Cmd {
-	return func() tea.Msg {
-		// Fetch fresh data from Notion
-		credService := config.NewCredentialSvc(files.NewFileService(consts.ConfigFileName))
-		notionSvc := notion.NewNotionImpl(credService)
-
-		todos, err := notionSvc.QueryPages(statusFilter, "")
-		if err != nil {
-			return refreshMsg{
-				success: false,
-				todos:   nil,
-				message: "Failed to refresh: " + err.Error(),
-			}
-		}
-
-		// Convert Notion todos to local Todo format
-		var result []Todo
-		for _, todo := range todos {
-			// Map Notion status to local status names
-			localStatus := todo.Status
-			switch todo.Status {
-			case "Todo":
-				localStatus = "Pending"
-			case "In Progress":
-				localStatus = "In Progress"
-			case "Done":
-				localStatus = "Done"
-			}
-
-			result = append(result, Todo{
-				ID:      todo.ID,
-				Title:   todo.Title,
-				Status:  localStatus,
-				DueDate: todo.DueDate,
-			})
-		}
-
-		return refreshMsg{
-			success: true,
-			todos:   result,
-			message: fmt.Sprintf("Refreshed %d todos", len(result)),
-		}
-	}
+// Refresh todos from the first page
+func refreshTodosCmd(ctx context.Context, timeout time.Duration, filter models.TodoFilter) tea.Cmd {
+	return fetchTodosCmd(ctx, timeout, filter, "")
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -286,6 +287,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.edit != nil {
+			return m.updateEdit(msg)
+		}
+		if m.notes != nil {
+			return m.updateNotes(msg)
+		}
+
 		if m.showConfirmation {
 			switch msg.String() {
 			case "y", "Y", "enter":
@@ -293,7 +301,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.showConfirmation = false
 				m.updating = true
 				// Call API to update status (local update happens in statusUpdateMsg handler)
-				return m, updateStatusCmd(m.pendingTodoID, m.pendingNewStatus)
+				return m, updateStatusCmd(m.ctx, m.timeout, m.pendingTodoID, m.pendingNewStatus)
 			case "n", "N", "esc":
 				// Cancel the update
 				m.showConfirmation = false
@@ -311,7 +319,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.showDeleteConfirm = false
 				m.updating = true
 				// Call API to delete todo
-				return m, deleteTodoCmd(m.pendingTodoID)
+				return m, deleteTodoCmd(m.ctx, m.timeout, m.pendingTodoID)
 			case "n", "N", "esc":
 				// Cancel the delete
 				m.showDeleteConfirm = false
@@ -325,6 +333,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.updating || m.refreshing {
 			switch msg.String() {
 			case "ctrl+c", "q":
+				// Abort the in-flight request before quitting
+				m.cancel()
 				return m, tea.Quit
 			}
 			return m, nil
@@ -332,27 +342,26 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch msg.String() {
 		case "ctrl+c", "q", "esc":
+			m.cancel()
 			return m, tea.Quit
 		case "up", "k":
-			if m.cursor > 0 {
-				m.cursor--
-			}
+			m.moveCursor(-1)
 		case "down", "j":
-			if m.cursor < len(m.todos)-1 {
-				m.cursor++
+			m.moveCursor(1)
+		case "enter", " ", "x":
+			if m.selectedSubTask() != nil {
+				return m.toggleSubTask()
+			}
+			if msg.String() == "x" {
+				break
 			}
-		case "right", "l", "enter", " ":
+			fallthrough
+		case "right", "l":
 			if len(m.todos) > 0 && !m.updating {
 				// Cycle status forward
 				todo := &m.todos[m.cursor]
 				oldStatus := todo.Status
-				newStatus := ""
-				for i, s := range m.statusList {
-					if s == todo.Status {
-						newStatus = m.statusList[(i+1)%len(m.statusList)]
-						break
-					}
-				}
+				newStatus := m.nextStatus(todo.Status, 1)
 
 				if newStatus != "" && newStatus != oldStatus {
 					m.showConfirmation = true
@@ -366,13 +375,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Cycle status backward
 				todo := &m.todos[m.cursor]
 				oldStatus := todo.Status
-				newStatus := ""
-				for i, s := range m.statusList {
-					if s == todo.Status {
-						newStatus = m.statusList[(i-1+len(m.statusList))%len(m.statusList)]
-						break
-					}
-				}
+				newStatus := m.nextStatus(todo.Status, -1)
 
 				if newStatus != "" && newStatus != oldStatus {
 					m.showConfirmation = true
@@ -382,10 +385,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		case "r":
+			if m.loadingMore {
+				// Wait for the in-flight page load to finish
+				return m, nil
+			}
 			m.refreshing = true
 			m.message = "Syncing..."
 			m.messageTime = time.Now()
-			return m, refreshTodosCmd(m.statusFilter)
+			return m, refreshTodosCmd(m.ctx, m.timeout, m.filter)
 		case "d", "D":
 			if len(m.todos) > 0 && !m.updating {
 				// Show delete confirmation
@@ -394,7 +401,25 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.pendingTodoID = todo.ID
 				m.pendingDeleteTitle = todo.Title
 			}
-
+		case "v":
+			if len(m.views) > 0 && !m.loadingMore {
+				return m.nextView()
+			}
+		case "e":
+			if len(m.todos) > 0 && !m.updating {
+				// Open the inline edit form
+				m.edit = newEditForm(m.todos[m.cursor], m.dates)
+			}
+		case "s":
+			if len(m.todos) > 0 {
+				return m.toggleExpanded()
+			}
+		case "n":
+			if len(m.todos) > 0 {
+				// Open the notes of the selected todo
+				m.notes = newNotesPane(m.todos[m.cursor])
+				return m, loadNotesCmd(m.ctx, m.timeout, m.notes.todoID)
+			}
 		}
 
 	case statusUpdateMsg:
@@ -404,6 +429,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			for i := range m.todos {
 				if m.todos[i].ID == msg.todoID {
 					m.todos[i].Status = msg.newStatus
+					if option := m.statusOption(msg.newStatus); option != nil {
+						m.todos[i].StatusGroup = option.Group
+					}
 					break
 				}
 			}
@@ -414,6 +442,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.messageTime = time.Now()
 
+	case notesMsg, noteAddedMsg:
+		return m.updateNotesMsg(msg)
+
+	case subTasksMsg:
+		m.applySubTasks(msg)
+
+	case subTaskToggledMsg:
+		m.applySubTaskToggle(msg)
+
+	case editMsg:
+		m.updating = false
+		if msg.success {
+			m.applyEdit(msg)
+		}
+		m.message = msg.message
+		m.messageTime = time.Now()
+
 	case deleteMsg:
 		m.updating = false
 		if msg.success {
@@ -441,8 +486,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case refreshMsg:
 		m.refreshing = false
+		m.loadingMore = false
 		if msg.success {
-			m.todos = msg.todos
+			if msg.append {
+				m.todos = append(m.todos, msg.todos...)
+			} else {
+				m.todos = msg.todos
+			}
 			// Reset cursor if it's out of bounds
 			if m.cursor >= len(m.todos) {
 				m.cursor = len(m.todos) - 1
@@ -450,16 +500,83 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.cursor < 0 {
 				m.cursor = 0
 			}
-			m.message = msg.message
+			m.messageTime = time.Now()
+			m.subCursor = -1
+			var loadSubTasks tea.Cmd
+			if !msg.append {
+				loadSubTasks = m.reloadSubTasks()
+			}
+			if msg.hasMore && msg.nextCursor != "" {
+				// Keep the list interactive while the next page loads
+				m.loadingMore = true
+				m.message = fmt.Sprintf("Loaded %d todos, loading more...", len(m.todos))
+				return m, tea.Batch(fetchTodosCmd(m.ctx, m.timeout, m.filter, msg.nextCursor), loadSubTasks)
+			}
+			m.message = fmt.Sprintf("Loaded %d todos", len(m.todos))
+			return m, loadSubTasks
 		} else {
 			m.errorMsg = msg.message // Clear todos on refresh failure
 			m.message = msg.message
+			m.messageTime = time.Now()
 		}
-		m.messageTime = time.Now()
 	}
 	return m, nil
 }
 
+// nextView switches to the next saved view, returning to the command line
+// filter after the last one
+func (m model) nextView() (tea.Model, tea.Cmd) {
+	next := 0
+	for i, view := range m.views {
+		if view.Name == m.viewName {
+			next = i + 1
+		}
+	}
+
+	m.viewName = ""
+	m.filter = m.baseFilter
+	if next < len(m.views) {
+		filter, err := resolveFilter(m.views[next].Filter, m.dates)
+		m.viewName = m.views[next].Name
+		if err != nil {
+			m.message = fmt.Sprintf("View %s: %v", m.viewName, err)
+			m.messageTime = time.Now()
+			return m, nil
+		}
+		m.filter = filter
+	}
+
+	m.refreshing = true
+	m.cursor = 0
+	m.message = "Syncing..."
+	m.messageTime = time.Now()
+	return m, refreshTodosCmd(m.ctx, m.timeout, m.filter)
+}
+
+// nextStatus returns the status step places after current in the status
+// list. Todos without a known status move to the first status.
+func (m model) nextStatus(current string, step int) string {
+	if len(m.statusList) == 0 {
+		return ""
+	}
+	for i, s := range m.statusList {
+		if s == current {
+			return m.statusList[(i+step+len(m.statusList))%len(m.statusList)]
+		}
+	}
+	return m.statusList[0]
+}
+
+// statusOption returns the database status named name, if any
+func (m model) statusOption(name string) *models.StatusOption {
+	for i := range m.statuses {
+		if m.statuses[i].Name == name {
+			return &m.statuses[i]
+		}
+	}
+	return nil
+}
+
 func (m model) View() string {
 	containerStyle := getContainerStyle(m.width, m.height)
 	titleStyle := getTitleStyle(m.width)
@@ -504,6 +621,14 @@ func (m model) View() string {
 		return confirmationContainerStyle.Render(confirmation)
 	}
 
+	// Show the edit form if open
+	if m.edit != nil {
+		return m.viewEdit()
+	}
+	if m.notes != nil {
+		return m.viewNotes()
+	}
+
 	// Show delete confirmation dialog if needed
 	if m.showDeleteConfirm {
 		confirmationContainerStyle := getConfirmationContainerStyle(m.width)
@@ -515,8 +640,15 @@ func (m model) View() string {
 		return confirmationContainerStyle.Render(confirmation)
 	}
 
-	// Simple header
-	header := titleStyle.Render("Todo")
+	// Simple header, followed by the active view and filter
+	title := "Todo"
+	if m.viewName != "" {
+		title += " · " + m.viewName
+	}
+	header := titleStyle.Render(title)
+	if description := m.filter.String(); description != "" {
+		header += "\n" + tpl.HelpStyle.Render(truncateText(description, m.width-6))
+	}
 
 	// Todo list with minimal styling
 	var todoItems []string
@@ -529,40 +661,48 @@ func (m model) View() string {
 		cursor := " "
 		style := tpl.ItemStyle
 
-		if m.cursor == i {
+		if m.cursor == i && m.subCursor < 0 {
 			cursor = ">"
 			style = tpl.SelectedItemStyle
 		}
 
-		// Get status style - minimal colors only
-		statusStyle, exists := tpl.StatusStyles[todo.Status]
-		if !exists {
-			statusStyle = tpl.ItemStyle
+		// Get status style from the option color Notion returns
+		statusStyle := tpl.ItemStyle
+		if option := m.statusOption(todo.Status); option != nil {
+			statusStyle = tpl.StatusStyle(option.Color)
 		}
 
-		// Status prefix indicators
+		// Status prefix indicators by status group
 		statusPrefix := ""
-		switch todo.Status {
-		case "Pending":
+		switch todo.StatusGroup {
+		case models.StatusGroupTodo:
 			statusPrefix = "[ ]"
-		case "In Progress":
+		case models.StatusGroupInProgress:
 			statusPrefix = "[~]"
-		case "Done":
+		case models.StatusGroupComplete:
 			statusPrefix = "[✓]"
 		}
 
-		// Format the todo item with status prefix
+		// Format the todo item with status prefix, leaving room for badges
 		statusBadge := statusStyle.Render(statusPrefix)
-		truncatedTitle := truncateText(todo.Title, maxTitleWidth)
+		badges := formatBadges(todo, m.width)
+		titleWidth := maxTitleWidth - lipgloss.Width(badges)
+		if titleWidth < 10 {
+			titleWidth = 10
+		}
+		truncatedTitle := truncateText(todo.Title, titleWidth)
 
 		// Simple due date
-		dueDateText := formatDueDate(todo.DueDate, m.width)
+		dueDateText := formatDueDate(todo, m.width)
 
 		// Create clean todo line with status prefix
-		todoLine := fmt.Sprintf("%s %s %s%s", cursor, statusBadge, truncatedTitle, dueDateText)
+		todoLine := fmt.Sprintf("%s %s %s%s%s%s", cursor, statusBadge, truncatedTitle, m.subTaskProgress(todo), badges, dueDateText)
 		todoLine = style.Render(todoLine)
 
 		todoItems = append(todoItems, todoLine)
+		if todo.ID == m.expanded {
+			todoItems = append(todoItems, m.viewSubTasks(todo, maxTitleWidth)...)
+		}
 	}
 
 	todoList := strings.Join(todoItems, "\n")
@@ -573,14 +713,25 @@ func (m model) View() string {
 		statusMsg = "\n" + tpl.UpdatingStyle.Render("Updating...")
 	} else if m.refreshing {
 		statusMsg = "\n" + tpl.UpdatingStyle.Render("Refreshing...")
+	} else if m.loadingMore {
+		statusMsg = "\n" + tpl.UpdatingStyle.Render(m.message)
 	} else if m.message != "" && time.Since(m.messageTime) < 3*time.Second {
 		statusMsg = "\n" + tpl.MessageStyle.Render(m.message)
 	}
 
 	// Minimal help text
-	helpText := "↑↓: navigate • ←→: status • d: delete • r: refresh • q: quit"
+	helpText := "↑↓: navigate • ←→: status • s: sub-tasks • e: edit • n: notes • d: delete • r: refresh • q: quit"
+	if len(m.views) > 0 {
+		helpText = "↑↓: navigate • ←→: status • s: sub-tasks • e: edit • n: notes • d: delete • v: view • r: refresh • q: quit"
+	}
 	if m.width < 60 {
-		helpText = "↑↓←→ d r q"
+		helpText = "↑↓←→ s e n d r q"
+		if len(m.views) > 0 {
+			helpText = "↑↓←→ s e n d v r q"
+		}
+	}
+	if m.selectedSubTask() != nil {
+		helpText = "↑↓: navigate • space: check • s: collapse • q: quit"
 	}
 	help := tpl.HelpStyle.Render(helpText)
 
@@ -590,28 +741,46 @@ func (m model) View() string {
 	return containerStyle.Render(content)
 }
 
-// Simple date formatting for minimalistic design
-func formatDueDate(dateStr *string, screenWidth int) string {
-	if dateStr == nil || *dateStr == "" {
+// Simple date formatting for minimalistic design. Times and ranges are shown
+// in local time; a range is overdue once its end has passed.
+func formatDueDate(todo Todo, screenWidth int) string {
+	if todo.DueDate == nil || *todo.DueDate == "" {
 		return ""
 	}
+	zone := ""
+	if todo.DueTimeZone != nil {
+		zone = *todo.DueTimeZone
+	}
 
-	// Parse the ISO date (YYYY-MM-DD) from Notion
-	parsedDate, err := time.Parse("2006-01-02", *dateStr)
+	// Parse the ISO date (YYYY-MM-DD) or date-time from Notion
+	start, hasTime, err := utility.ParseNotionDate(*todo.DueDate, zone)
 	if err != nil {
 		return ""
 	}
+	end, endHasTime := start, hasTime
+	if todo.DueDateEnd != nil && *todo.DueDateEnd != "" {
+		if end, endHasTime, err = utility.ParseNotionDate(*todo.DueDateEnd, zone); err != nil {
+			end, endHasTime = start, hasTime
+		}
+	}
+	if hasTime {
+		start = start.Local()
+	}
+	if endHasTime {
+		end = end.Local()
+	}
 
 	// Check if date is overdue, today, or upcoming
 	now := time.Now()
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	dueDate := time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), 0, 0, 0, 0, parsedDate.Location())
+	startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, now.Location())
+	endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, now.Location())
 
 	var dateColor string
-	if dueDate.Before(today) {
+	if endDay.Before(today) || (endHasTime && end.Before(now)) {
 		dateColor = "#dc2626" // Red - overdue
-	} else if dueDate.Equal(today) {
-		dateColor = "#d97706" // Orange - due today
+	} else if !startDay.After(today) {
+		dateColor = "#d97706" // Orange - due today or in progress
 	} else {
 		dateColor = "#6b7280" // Gray - future
 	}
@@ -619,64 +788,196 @@ func formatDueDate(dateStr *string, screenWidth int) string {
 	dateStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(dateColor))
 
 	// Simple format based on screen width
-	if screenWidth < 60 {
-		return " " + dateStyle.Render(parsedDate.Format("1/2"))
-	} else {
-		if parsedDate.Year() == now.Year() {
-			return " " + dateStyle.Render(parsedDate.Format("Jan 2"))
+	format := func(t time.Time, withTime bool) string {
+		layout := "Jan 2, 06"
+		if screenWidth < 60 {
+			layout = "1/2"
+		} else if t.Year() == now.Year() {
+			layout = "Jan 2"
+		}
+		if withTime {
+			layout += " 15:04"
+		}
+		return t.Format(layout)
+	}
+	text := format(start, hasTime)
+	if todo.DueDateEnd != nil && !end.Equal(start) {
+		if hasTime && endHasTime && endDay.Equal(startDay) {
+			text += "–" + end.Format("15:04")
 		} else {
-			return " " + dateStyle.Render(parsedDate.Format("Jan 2, 06"))
+			text += " – " + format(end, endHasTime)
+		}
+	}
+	return " " + dateStyle.Render(text)
+}
+
+// formatBadges renders the priority, tags and assignees of a todo. Tags and
+// assignees are only shown on wide screens.
+func formatBadges(todo Todo, screenWidth int) string {
+	var badges []string
+	if todo.Priority != nil && *todo.Priority != "" {
+		badges = append(badges, tpl.StatusStyle(todo.PriorityColor).Render("!"+*todo.Priority))
+	}
+	if screenWidth >= 80 {
+		for _, tag := range todo.Tags {
+			badges = append(badges, tpl.HelpStyle.Render("#"+tag))
+		}
+		for _, assignee := range todo.Assignees {
+			// First names keep the row short
+			name := strings.Fields(assignee)
+			if len(name) > 0 {
+				badges = append(badges, tpl.HelpStyle.Render("@"+name[0]))
+			}
 		}
 	}
+	if len(badges) == 0 {
+		return ""
+	}
+	return " " + strings.Join(badges, " ")
 }
 
 // Helper function to truncate text for responsive design
 func truncateText(text string, maxWidth int) string {
-	if len(text) <= maxWidth {
+	runes := []rune(text)
+	if len(runes) <= maxWidth {
 		return text
 	}
 	if maxWidth <= 3 {
 		return "..."
 	}
-	return text[:maxWidth-3] + "..."
+	return string(runes[:maxWidth-3]) + "..."
 }
 
-func List(cmd *cobra.Command, args []string) {
-	status, _ := cmd.Flags().GetString("status")
+func List(cmd *cobra.Command, args []string) error {
+	credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+	viewSvc := views.NewViewSvc(credService)
 
-	// Validate status filter
-	if status != "" && !consts.IsValidStatus(status) {
-		fmt.Printf("Invalid status filter: '%s'. Valid statuses are: %s\n", status, consts.GetAllStatuses())
-		os.Exit(1)
+	// Start from the saved view, if any, and let flags override it
+	var filter models.TodoFilter
+	var columns []string
+	viewName, _ := cmd.Flags().GetString("view")
+	if viewName != "" {
+		view, err := viewSvc.Get(viewName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tpl.RenderError(err.Error()))
+			fmt.Fprintln(os.Stderr, tpl.RenderHelp("Use 'todo view list' to see saved views"))
+			// A missing view is a bad --view value rather than a missing todo
+			if isViewNotFound(err) {
+				err = usageError(err)
+			}
+			return Reported(err)
+		}
+		viewName = view.Name
+		filter = view.Filter
+		columns = view.Columns
+	}
+	dates := loadDateSettings()
+	filter, err := applyFilterFlags(cmd, filter)
+	if err == nil {
+		filter, err = resolveFilter(filter, dates)
+	}
+	if err != nil {
+		return usageError(err)
+	}
+	if cmd.Flags().Changed("columns") {
+		columns, _ = cmd.Flags().GetStringSlice("columns")
+	}
+	if err := output.ValidateColumns(columns); err != nil {
+		return usageError(err)
 	}
 
-	status = cases.Title(language.English).String(status) // Normalize status to title case
+	allDatabases, _ := cmd.Flags().GetBool("all-dbs")
+	if allDatabases && (cmd.Flags().Changed("db") || cmd.Flags().Changed("database")) {
+		return usagef("--all-dbs cannot be used with --db or --database")
+	}
+
+	outputFlag, _ := cmd.Flags().GetString("output")
+	format, nonInteractive, err := output.Resolve(outputFlag)
+	if err != nil {
+		return usageError(err)
+	}
+	if allDatabases {
+		// Statuses differ between databases, so they are matched as typed
+		// and the interactive view, which edits one database, is not used
+		if !nonInteractive {
+			format = output.FormatTable
+		}
+		return printAllTodos(cmd, format, filter, columns)
+	}
+
+	loadCtx, cancelLoad := commandContext(cmd)
+	statusOptions, err := normalizeStatuses(loadCtx, statusService(), &filter)
+	cancelLoad()
+	if err != nil {
+		return err
+	}
+
+	if nonInteractive {
+		return printTodos(cmd, format, filter, columns)
+	}
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
 
 	p := tea.NewProgram(
-		initialModel(status),
+		initialModel(ctx, commandTimeout(cmd), filter, statusOptions, savedViews(viewSvc), viewName, dates),
+		tea.WithContext(ctx),      // Stop the program when the command is interrupted
 		tea.WithAltScreen(),       // Use alternate screen buffer
 		tea.WithMouseCellMotion(), // Enable mouse support
 	)
 	if _, err := p.Run(); err != nil {
-		fmt.Println("Error running Bubble Tea program:", err)
-		os.Exit(1)
+		return fmt.Errorf("error running Bubble Tea program: %w", err)
+	}
+	return nil
+}
+
+// savedViews returns the saved views, or none when they cannot be read
+func savedViews(viewSvc views.Views) []models.View {
+	list, err := viewSvc.List()
+	if err != nil {
+		return nil
 	}
+	return list
+}
+
+// printTodos writes every todo matching filter to stdout in format
+func printTodos(cmd *cobra.Command, format output.Format, filter models.TodoFilter, columns []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+	notionSvc := notion.NewNotionImpl(credService)
+
+	todos, err := notionSvc.QueryPages(ctx, filter)
+	if err != nil {
+		return reportError("Failed to fetch todos", err)
+	}
+	if err := output.CheckColumns(todos, columns); err != nil {
+		return usageError(err)
+	}
+	if err := output.WriteTodos(os.Stdout, format, todos, columns); err != nil {
+		return fmt.Errorf("Failed to write output: %w", err)
+	}
+	return nil
 }
 
 // Delete todo using Notion API
-func deleteTodoCmd(todoID string) tea.Cmd {
+func deleteTodoCmd(ctx context.Context, timeout time.Duration, todoID string) tea.Cmd {
 	return func() tea.Msg {
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
 		// Initialize services
-		credService := config.NewCredentialSvc(files.NewFileService(consts.ConfigFileName))
+		credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
 		notionSvc := notion.NewNotionImpl(credService)
 
 		// Call Notion API to delete todo
-		err := notionSvc.DeletePage(todoID)
+		err := notionSvc.DeletePage(ctx, todoID)
 		if err != nil {
 			return deleteMsg{
 				success: false,
 				todoID:  todoID,
-				message: fmt.Sprintf("Failed to delete: %v", err),
+				message: errorMessage("Failed to delete", err),
 			}
 		}
 
@@ -0,0 +1,226 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/notion"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Sub-tasks message for async loads of the sub-tasks of one todo
+type subTasksMsg struct {
+	success  bool
+	todoID   string
+	subTasks []models.SubTask
+	message  string
+}
+
+// Sub-task message for async check and uncheck operations
+type subTaskToggledMsg struct {
+	success bool
+	todoID  string
+	blockID string
+	checked bool
+	message string
+}
+
+// toggleExpanded shows or hides the sub-tasks of the selected todo
+func (m model) toggleExpanded() (tea.Model, tea.Cmd) {
+	todo := m.todos[m.cursor]
+	m.subCursor = -1
+	if m.expanded == todo.ID {
+		m.expanded = ""
+		return m, nil
+	}
+	m.expanded = todo.ID
+	if _, ok := m.subTasks[todo.ID]; !ok {
+		// Sub-tasks are only read when asked for, as each todo takes a request
+		delete(m.subTaskErrors, todo.ID)
+		return m, loadSubTasksCmd(m.ctx, m.timeout, todo.ID)
+	}
+	return m, nil
+}
+
+// reloadSubTasks forgets loaded sub-tasks after the list is refreshed and
+// reloads those of the expanded todo, if it is still listed
+func (m *model) reloadSubTasks() tea.Cmd {
+	m.subTasks = map[string][]models.SubTask{}
+	m.subTaskErrors = map[string]string{}
+	for _, todo := range m.todos {
+		if todo.ID == m.expanded {
+			return loadSubTasksCmd(m.ctx, m.timeout, todo.ID)
+		}
+	}
+	m.expanded = ""
+	return nil
+}
+
+// expandedSubTasks returns the sub-tasks shown under the selected todo
+func (m model) expandedSubTasks() []models.SubTask {
+	if len(m.todos) == 0 || m.todos[m.cursor].ID != m.expanded {
+		return nil
+	}
+	return m.subTasks[m.expanded]
+}
+
+// moveCursor moves through todos and the sub-tasks of the expanded todo
+func (m *model) moveCursor(step int) {
+	subTasks := m.expandedSubTasks()
+	switch {
+	case step > 0 && m.subCursor < len(subTasks)-1:
+		m.subCursor++
+	case step < 0 && m.subCursor >= 0:
+		m.subCursor--
+	case step > 0 && m.cursor < len(m.todos)-1:
+		m.cursor++
+		m.subCursor = -1
+	case step < 0 && m.cursor > 0:
+		m.cursor--
+		m.subCursor = -1
+	}
+}
+
+// selectedSubTask returns the selected sub-task, or nil when a todo is selected
+func (m model) selectedSubTask() *models.SubTask {
+	subTasks := m.expandedSubTasks()
+	if m.subCursor < 0 || m.subCursor >= len(subTasks) {
+		return nil
+	}
+	return &subTasks[m.subCursor]
+}
+
+// toggleSubTask checks or unchecks the selected sub-task
+func (m model) toggleSubTask() (tea.Model, tea.Cmd) {
+	subTask := m.selectedSubTask()
+	m.updating = true
+	return m, toggleSubTaskCmd(m.ctx, m.timeout, m.expanded, subTask.ID, !subTask.Checked)
+}
+
+// applySubTasks stores loaded sub-tasks, or the reason they failed to load
+func (m *model) applySubTasks(msg subTasksMsg) {
+	if !msg.success {
+		m.subTaskErrors[msg.todoID] = msg.message
+		m.message = msg.message
+		m.messageTime = time.Now()
+		return
+	}
+	m.subTasks[msg.todoID] = msg.subTasks
+	if m.subCursor >= len(m.expandedSubTasks()) {
+		m.subCursor = len(m.expandedSubTasks()) - 1
+	}
+}
+
+// applySubTaskToggle updates the local sub-task after a check or uncheck
+func (m *model) applySubTaskToggle(msg subTaskToggledMsg) {
+	m.updating = false
+	m.message = msg.message
+	m.messageTime = time.Now()
+	if !msg.success {
+		return
+	}
+	for i := range m.subTasks[msg.todoID] {
+		if m.subTasks[msg.todoID][i].ID == msg.blockID {
+			m.subTasks[msg.todoID][i].Checked = msg.checked
+		}
+	}
+}
+
+// subTaskProgress renders the done/total sub-tasks of a todo, once they are
+// loaded and if it has any
+func (m model) subTaskProgress(todo Todo) string {
+	done, total := models.SubTaskProgress(m.subTasks[todo.ID])
+	if total == 0 {
+		return ""
+	}
+	return " " + tpl.HelpStyle.Render(fmt.Sprintf("%d/%d", done, total))
+}
+
+// viewSubTasks renders the sub-tasks of the expanded todo below its row
+func (m model) viewSubTasks(todo Todo, maxWidth int) []string {
+	subTasks, loaded := m.subTasks[todo.ID]
+	if message, failed := m.subTaskErrors[todo.ID]; failed && !loaded {
+		return []string{
+			tpl.ErrorStyle.Render("      " + truncateText(message, maxWidth)),
+			tpl.HelpStyle.Render("      Press 's' twice to retry"),
+		}
+	}
+	if !loaded {
+		return []string{tpl.HelpStyle.Render("      Loading sub-tasks...")}
+	}
+	if len(subTasks) == 0 {
+		return []string{tpl.HelpStyle.Render("      No sub-tasks")}
+	}
+
+	var lines []string
+	for i, subTask := range subTasks {
+		mark := "[ ]"
+		if subTask.Checked {
+			mark = "[x]"
+		}
+		line := fmt.Sprintf("%s %s", mark, truncateText(subTask.Text, maxWidth))
+		if i == m.subCursor {
+			lines = append(lines, tpl.SelectedItemStyle.Render("    > "+line))
+		} else {
+			lines = append(lines, tpl.ItemStyle.Render("      "+line))
+		}
+	}
+	return lines
+}
+
+// Load the sub-tasks of a todo using Notion API
+func loadSubTasksCmd(ctx context.Context, timeout time.Duration, todoID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
+		credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+		notionSvc := notion.NewNotionImpl(credService)
+
+		subTasks, err := notionSvc.ListSubTasks(ctx, todoID)
+		if err != nil {
+			return subTasksMsg{
+				success: false,
+				todoID:  todoID,
+				message: errorMessage("Failed to load sub-tasks", err),
+			}
+		}
+		return subTasksMsg{success: true, todoID: todoID, subTasks: subTasks}
+	}
+}
+
+// Check or uncheck a sub-task using Notion API
+func toggleSubTaskCmd(ctx context.Context, timeout time.Duration, todoID, blockID string, checked bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
+		credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+		notionSvc := notion.NewNotionImpl(credService)
+
+		if err := notionSvc.SetSubTaskChecked(ctx, blockID, checked); err != nil {
+			return subTaskToggledMsg{
+				success: false,
+				todoID:  todoID,
+				message: errorMessage("Failed to update sub-task", err),
+			}
+		}
+		message := "Sub-task checked"
+		if !checked {
+			message = "Sub-task unchecked"
+		}
+		return subTaskToggledMsg{
+			success: true,
+			todoID:  todoID,
+			blockID: blockID,
+			checked: checked,
+			message: message,
+		}
+	}
+}
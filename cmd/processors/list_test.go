@@ -0,0 +1,90 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/notion/fakenotion"
+	"github.com/caffeines/notion-todo/service/utility"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// nextRefresh runs cmd, and the commands it batches, returning the first
+// refreshMsg they produce
+func nextRefresh(t *testing.T, cmd tea.Cmd) refreshMsg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("no command to fetch todos")
+	}
+	switch msg := cmd().(type) {
+	case refreshMsg:
+		return msg
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			if cmd == nil {
+				continue
+			}
+			if refresh, ok := cmd().(refreshMsg); ok {
+				return refresh
+			}
+		}
+	}
+	t.Fatal("the command did not fetch todos")
+	return refreshMsg{}
+}
+
+func TestProgressiveLoading(t *testing.T) {
+	server, _ := newTestNotion(t, "3c4d5e6f708192a3b4c5d6e7f8091a2b", fakenotion.TodoSchema())
+	const total = 2*consts.PAGE_SIZE + 30
+	for i := 0; i < total; i++ {
+		title := map[string]interface{}{"title": []interface{}{map[string]interface{}{"text": map[string]interface{}{"content": fmt.Sprintf("Todo %03d", i)}}}}
+		if _, err := server.AddPage("3c4d5e6f708192a3b4c5d6e7f8091a2b", map[string]interface{}{"Title": title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := initialModel(context.Background(), 0, models.TodoFilter{}, nil, nil, "", dateSettings{locale: utility.DefaultDateLocale})
+	cmd := m.Init()
+	for page := 1; page <= 3; page++ {
+		updated, next := m.Update(nextRefresh(t, cmd))
+		m = updated.(model)
+		want := page * consts.PAGE_SIZE
+		if want > total {
+			want = total
+		}
+		if len(m.todos) != want {
+			t.Fatalf("page %d: %d todos listed, want %d", page, len(m.todos), want)
+		}
+		// Earlier pages stay usable while the next one loads
+		if m.refreshing || m.loadingMore != (page < 3) {
+			t.Errorf("page %d: refreshing %v, loading more %v", page, m.refreshing, m.loadingMore)
+		}
+		cmd = next
+	}
+	if !strings.Contains(m.message, fmt.Sprintf("Loaded %d todos", total)) {
+		t.Errorf("message = %q", m.message)
+	}
+	if cmd != nil {
+		t.Error("a fetch followed the last page")
+	}
+
+	// A failed page keeps the todos already loaded
+	m = initialModel(context.Background(), 0, models.TodoFilter{}, nil, nil, "", dateSettings{locale: utility.DefaultDateLocale})
+	updated, cmd := m.Update(nextRefresh(t, m.Init()))
+	m = updated.(model)
+	server.Fail(http.StatusServiceUnavailable, notion.CodeServiceUnavailable, "down", 1)
+	updated, _ = m.Update(nextRefresh(t, cmd))
+	m = updated.(model)
+	if len(m.todos) != consts.PAGE_SIZE || m.loadingMore {
+		t.Errorf("after a failed page: %d todos, loading more %v", len(m.todos), m.loadingMore)
+	}
+	if !strings.Contains(m.message, "Failed to load todos") {
+		t.Errorf("message = %q", m.message)
+	}
+}
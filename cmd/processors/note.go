@@ -0,0 +1,44 @@
+package processors
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/markdown"
+	"github.com/spf13/cobra"
+)
+
+// noteFlags reads the note from --note or --note-file, where "-" reads
+// standard input, and converts its Markdown to page content
+func noteFlags(cmd *cobra.Command) ([]models.BlockData, error) {
+	flags := cmd.Flags()
+	note, _ := flags.GetString("note")
+	path, _ := flags.GetString("note-file")
+	if note != "" && path != "" {
+		return nil, fmt.Errorf("pass either --note or --note-file, not both")
+	}
+
+	if path != "" {
+		var data []byte
+		var err error
+		if path == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read note: %v", err)
+		}
+		note = string(data)
+	}
+	if strings.TrimSpace(note) == "" {
+		if flags.Changed("note") || path != "" {
+			return nil, fmt.Errorf("the note is empty")
+		}
+		return nil, nil
+	}
+	return markdown.ToBlocks(note), nil
+}
@@ -0,0 +1,67 @@
+package processors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestNoteFlags(t *testing.T) {
+	dir := t.TempDir()
+	noteFile := filepath.Join(dir, "note.md")
+	if err := os.WriteFile(noteFile, []byte("# Plan\n\n- [ ] call\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	blankFile := filepath.Join(dir, "blank.md")
+	if err := os.WriteFile(blankFile, []byte("\n  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		// blocks are the types of the blocks made from the note
+		blocks  []string
+		wantErr string
+	}{
+		{name: "no note"},
+		{name: "note", args: []string{"--note", "Buy **oat** milk"}, blocks: []string{"paragraph"}},
+		{name: "note file", args: []string{"--note-file", noteFile}, blocks: []string{"heading_1", "to_do"}},
+		{name: "both", args: []string{"--note", "x", "--note-file", noteFile}, wantErr: "not both"},
+		{name: "empty note", args: []string{"--note", " "}, wantErr: "the note is empty"},
+		{name: "empty file", args: []string{"--note-file", blankFile}, wantErr: "the note is empty"},
+		{name: "missing file", args: []string{"--note-file", filepath.Join(dir, "missing.md")}, wantErr: "could not read note"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "add"}
+			cmd.Flags().String("note", "", "")
+			cmd.Flags().String("note-file", "", "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			blocks, err := noteFlags(cmd)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("noteFlags() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("noteFlags() error = %v", err)
+			}
+			var types []string
+			for _, block := range blocks {
+				types = append(types, fmt.Sprint(block["type"]))
+			}
+			if strings.Join(types, ",") != strings.Join(tt.blocks, ",") {
+				t.Errorf("noteFlags() blocks = %v, want %v", types, tt.blocks)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package processors
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/spf13/cobra"
+)
+
+// credentialService returns the config service
+func credentialService() config.Credential {
+	return config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+}
+
+// SelectConfig makes the --config, --profile, --token and --database flags,
+// and the --db flag of commands that have it, apply to every later config
+// lookup of this run
+func SelectConfig(cmd *cobra.Command, args []string) error {
+	if path, _ := cmd.Flags().GetString("config"); path != "" {
+		files.SetConfigFile(path)
+	}
+	credService := credentialService()
+	name, _ := cmd.Flags().GetString("profile")
+	if name != "" {
+		if err := config.ValidateProfileName(name); err != nil {
+			return usageError(err)
+		}
+		credService.SelectProfile(name)
+	}
+	if cmd.Flags().Lookup("db") != nil {
+		alias, _ := cmd.Flags().GetString("db")
+		credService.SelectDatabase(alias)
+		if alias != "" && cmd.Flags().Changed("database") {
+			return usagef("--database and --db cannot be used together")
+		}
+	}
+
+	// Flags take precedence over environment variables and the config file
+	for flag, key := range map[string]string{"token": config.KeyToken, "database": config.KeyDatabaseID} {
+		if !cmd.Flags().Changed(flag) {
+			continue
+		}
+		value, _ := cmd.Flags().GetString(flag)
+		if err := credService.SetOverride(key, value); err != nil {
+			return usagef("Invalid --%s: %s", flag, errors.Unwrap(err))
+		}
+	}
+	return nil
+}
+
+// ProfileList prints the stored profiles and marks the one in use
+func ProfileList(cmd *cobra.Command, args []string) error {
+	credService := credentialService()
+	names, err := credService.ListProfiles()
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Println("No profiles configured. Create one with: todo config [--profile NAME]")
+			return nil
+		}
+		return reportError("Failed to read profiles", err)
+	}
+	current, err := credService.Profile()
+	if err != nil {
+		return reportError("Failed to read profiles", err)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "\tNAME\tDATABASE")
+	for _, name := range names {
+		marker := ""
+		if name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", marker, name, profileDatabase(credService, name))
+	}
+	table.Flush()
+	return nil
+}
+
+// profileDatabase returns the database ID of a profile, "-" when unset
+func profileDatabase(credService config.Credential, name string) string {
+	current, _ := credService.Profile()
+	credService.SelectProfile(name)
+	defer credService.SelectProfile(current)
+
+	cfg, err := credService.GetSettings()
+	if err != nil || cfg.DatabaseID == "" {
+		return "-"
+	}
+	return cfg.DatabaseID
+}
+
+// ProfileUse makes a profile the active one
+func ProfileUse(cmd *cobra.Command, args []string) error {
+	if err := credentialService().UseProfile(args[0]); err != nil {
+		return reportProfileError("Failed to switch profile", err)
+	}
+	fmt.Println(tpl.RenderSuccess(fmt.Sprintf("Now using profile '%s'", args[0])))
+	return nil
+}
+
+// ProfileRemove deletes a named profile
+func ProfileRemove(cmd *cobra.Command, args []string) error {
+	if args[0] == consts.DefaultProfile {
+		return usagef("The default profile cannot be removed")
+	}
+	if err := credentialService().RemoveProfile(args[0]); err != nil {
+		return reportProfileError("Failed to remove profile", err)
+	}
+	fmt.Println(tpl.RenderSuccess(fmt.Sprintf("Removed profile '%s'", args[0])))
+	return nil
+}
+
+// reportProfileError prints err, pointing to the stored profiles when the
+// profile does not exist
+func reportProfileError(message string, err error) error {
+	fmt.Fprintln(os.Stderr, tpl.RenderError(message+": "+err.Error()))
+	if isProfileNotFound(err) {
+		fmt.Fprintln(os.Stderr, tpl.RenderHelp("See 'todo profile list' for the stored profiles."))
+	}
+	return Reported(err)
+}
+
+// CompleteProfiles completes stored profile names
+func CompleteProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := credentialService().ListProfiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
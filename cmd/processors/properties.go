@@ -0,0 +1,241 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/markdown"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/spf13/cobra"
+)
+
+// trimList trims the values of a list flag and drops empty ones
+func trimList(values []string) []string {
+	var trimmed []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
+	}
+	return trimmed
+}
+
+// displayList joins list values for display, showing (none) for no values
+func displayList(values []string) string {
+	if len(values) == 0 {
+		return "(none)"
+	}
+	return strings.Join(values, ", ")
+}
+
+// propAssignment is a --prop flag value, Name=value
+type propAssignment struct {
+	name  string
+	value string
+}
+
+// propFlags parses the --prop flags
+func propFlags(cmd *cobra.Command) ([]propAssignment, error) {
+	flags, _ := cmd.Flags().GetStringArray("prop")
+	var props []propAssignment
+	for _, flag := range flags {
+		name, value, ok := strings.Cut(flag, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("--prop %q must be written as Name=value", flag)
+		}
+		props = append(props, propAssignment{name: name, value: strings.TrimSpace(value)})
+	}
+	return props, nil
+}
+
+// encodeProps encodes --prop values by the type of the database property
+// they name, which replaces the name given. An empty value clears the
+// property.
+func encodeProps(ctx context.Context, notionSvc notion.Notion, props []propAssignment, dates dateSettings) (models.ItemData, error) {
+	if len(props) == 0 {
+		return nil, nil
+	}
+	database, err := notionSvc.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := models.ItemData{}
+	for i, prop := range props {
+		property, ok := findProperty(database, prop.name)
+		if !ok {
+			var names []string
+			for name := range database.Properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, usagef("unknown property %q, the database has: %s", prop.name, strings.Join(names, ", "))
+		}
+		value, err := encodePropValue(ctx, notionSvc, property, prop.value, dates)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", property.Name, err)
+		}
+		data[property.Name] = value
+		props[i].name = property.Name
+	}
+	return data, nil
+}
+
+// findProperty returns the property named name, ignoring case
+func findProperty(database *models.NotionDatabase, name string) (models.NotionDatabaseProperty, bool) {
+	if property, ok := database.Properties[name]; ok {
+		property.Name = name
+		return property, true
+	}
+	for key, property := range database.Properties {
+		if strings.EqualFold(key, name) {
+			property.Name = key
+			return property, true
+		}
+	}
+	return models.NotionDatabaseProperty{}, false
+}
+
+// findTodoProperty returns the name of the property of todo matching name,
+// ignoring case
+func findTodoProperty(todo models.TodoItem, name string) (string, bool) {
+	for key := range todo.Properties {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// encodePropValue returns the write-shaped value of a property
+func encodePropValue(ctx context.Context, notionSvc notion.Notion, property models.NotionDatabaseProperty, value string, dates dateSettings) (map[string]interface{}, error) {
+	kind := property.Type
+	switch kind {
+	case models.PropertyTypeTitle:
+		return nil, usagef("the title is set with the todo text or --title")
+	case models.PropertyTypeRichText:
+		return map[string]interface{}{kind: markdown.RichText(value)}, nil
+	case models.PropertyTypeNumber:
+		if value == "" {
+			return map[string]interface{}{kind: nil}, nil
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, usagef("%q is not a number", value)
+		}
+		return map[string]interface{}{kind: number}, nil
+	case models.PropertyTypeSelect:
+		return models.NewSelectValue(optionName(property.Select, value)), nil
+	case models.PropertyTypeStatus:
+		// Notion does not create status options, so the name must exist
+		var names []string
+		if property.Status != nil {
+			for _, option := range property.Status.Options {
+				if strings.EqualFold(option.Name, value) {
+					return map[string]interface{}{kind: models.Select{Name: option.Name}}, nil
+				}
+				names = append(names, option.Name)
+			}
+		}
+		return nil, &models.InvalidOptionError{Property: "status", Value: value, Options: names}
+	case models.PropertyTypeMultiSelect:
+		var names []string
+		for _, name := range trimList(strings.Split(value, ",")) {
+			names = append(names, optionName(property.MultiSelect, name))
+		}
+		return models.NewMultiSelectValue(names), nil
+	case models.PropertyTypeDate:
+		if value == "" {
+			return map[string]interface{}{kind: nil}, nil
+		}
+		start, end, _ := strings.Cut(value, " to ")
+		date, err := dates.dueDate(strings.TrimSpace(start), strings.TrimSpace(end), "")
+		if err != nil {
+			return nil, usageError(err)
+		}
+		return map[string]interface{}{kind: date}, nil
+	case models.PropertyTypeCheckbox:
+		checked, err := parseCheckbox(value)
+		if err != nil {
+			return nil, usageError(err)
+		}
+		return map[string]interface{}{kind: checked}, nil
+	case models.PropertyTypeURL, models.PropertyTypeEmail, models.PropertyTypePhoneNumber:
+		if value == "" {
+			return map[string]interface{}{kind: nil}, nil
+		}
+		return map[string]interface{}{kind: value}, nil
+	case models.PropertyTypePeople:
+		refs := trimList(strings.Split(value, ","))
+		var ids []string
+		if len(refs) > 0 {
+			users, err := notionSvc.ListUsers(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, ref := range refs {
+				id, err := utility.ResolveUserRef(users, ref)
+				if err != nil {
+					return nil, err
+				}
+				ids = append(ids, id)
+			}
+		}
+		return models.NewPeopleValue(ids), nil
+	case models.PropertyTypeRelation:
+		relations := []models.NotionRelation{}
+		for _, ref := range trimList(strings.Split(value, ",")) {
+			id, ok := pageID(ref)
+			if !ok {
+				return nil, usagef("%q is not a page ID or Notion page URL", ref)
+			}
+			relations = append(relations, models.NotionRelation{ID: id})
+		}
+		return map[string]interface{}{kind: relations}, nil
+	}
+	return nil, usagef("%s properties cannot be set", strings.ReplaceAll(kind, "_", " "))
+}
+
+// optionName returns the existing option matching name, ignoring case, or
+// name itself, which Notion adds as a new option
+func optionName(config *models.NotionSelectConfig, name string) string {
+	if config != nil {
+		for _, option := range config.Options {
+			if strings.EqualFold(option.Name, name) {
+				return option.Name
+			}
+		}
+	}
+	return name
+}
+
+// parseCheckbox reads the usual spellings of true and false
+func parseCheckbox(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "on", "1", "x", "checked", "done":
+		return true, nil
+	case "false", "no", "n", "off", "0", "", "unchecked":
+		return false, nil
+	}
+	return false, fmt.Errorf("%q is not a checkbox value, use true or false", value)
+}
+
+// pageIDPattern matches the 32 hex digit ID at the end of a page ID or URL
+var pageIDPattern = regexp.MustCompile(`([0-9a-fA-F]{8})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{12})$`)
+
+// pageID returns the dashed page ID of a page ID or Notion page URL
+func pageID(ref string) (string, bool) {
+	ref = strings.SplitN(strings.SplitN(ref, "?", 2)[0], "#", 2)[0]
+	match := pageIDPattern.FindStringSubmatch(ref)
+	if match == nil {
+		return "", false
+	}
+	return strings.ToLower(strings.Join(match[1:], "-")), true
+}
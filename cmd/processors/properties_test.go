@@ -0,0 +1,248 @@
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/notion/fakenotion"
+	"github.com/caffeines/notion-todo/service/statuses"
+	"github.com/caffeines/notion-todo/service/utility"
+)
+
+// newTestNotion starts a fake Notion server with a database of schema,
+// configures the commands for it through the environment and returns the
+// Notion service with the property mapping discovered
+func newTestNotion(t *testing.T, databaseID string, schema map[string]map[string]interface{}) (*fakenotion.Server, notion.Notion) {
+	t.Helper()
+	server := fakenotion.NewServer()
+	t.Cleanup(server.Close)
+	server.AddDatabase(databaseID, "Todo", schema)
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv(consts.EnvToken, "secret_test")
+	t.Setenv(consts.EnvDatabaseID, databaseID)
+	t.Setenv(consts.EnvAPIURL, server.APIURL())
+	t.Setenv(consts.EnvMaxRetries, "-1")
+
+	credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+	notionSvc := notion.NewNotionImpl(credService)
+	if _, err := notionSvc.DiscoverProperties(context.Background()); err != nil {
+		t.Fatalf("DiscoverProperties: %v", err)
+	}
+	return server, notionSvc
+}
+
+// propSchema has a property of every type --prop can set
+func propSchema() map[string]map[string]interface{} {
+	options := func(names ...string) map[string]interface{} {
+		var list []interface{}
+		for _, name := range names {
+			list = append(list, map[string]interface{}{"name": name})
+		}
+		return map[string]interface{}{"options": list}
+	}
+	return map[string]map[string]interface{}{
+		"Name":     {"type": "title"},
+		"State":    {"type": "status", "status": options("Not started", "In progress", "Done")},
+		"Priority": {"type": "select", "select": options("High", "Low")},
+		"Tags":     {"type": "multi_select", "multi_select": options("Home", "Work")},
+		"Due":      {"type": "date"},
+		"Done":     {"type": "checkbox"},
+		"Estimate": {"type": "number"},
+		"Link":     {"type": "url"},
+		"Notes":    {"type": "rich_text"},
+		"Owner":    {"type": "people"},
+		"Blocks":   {"type": "relation"},
+		"Created":  {"type": "created_time"},
+	}
+}
+
+func TestEncodeProps(t *testing.T) {
+	server, notionSvc := newTestNotion(t, "1a2b3c4d5e6f708192a3b4c5d6e7f801", propSchema())
+	server.AddUser("9f8e7d6c-5b4a-3928-1706-f5e4d3c2b1a0", "Ada Lovelace", "ada@example.com")
+	dates := dateSettings{locale: utility.LocaleDMY}
+
+	tests := []struct {
+		name    string
+		prop    string
+		value   string
+		want    string // JSON of the encoded property, keyed by its exact name
+		wantErr string
+	}{
+		{name: "status", prop: "state", value: "in PROGRESS", want: `{"State":{"status":{"name":"In progress"}}}`},
+		{name: "unknown status", prop: "State", value: "Blocked", wantErr: "Blocked"},
+		{name: "select option", prop: "Priority", value: "high", want: `{"Priority":{"select":{"name":"High"}}}`},
+		{name: "new select option", prop: "Priority", value: "Urgent", want: `{"Priority":{"select":{"name":"Urgent"}}}`},
+		{name: "clear select", prop: "Priority", value: "", want: `{"Priority":{"select":null}}`},
+		{name: "multi-select", prop: "Tags", value: "work, garden", want: `{"Tags":{"multi_select":[{"name":"Work"},{"name":"garden"}]}}`},
+		{name: "checked", prop: "Done", value: "yes", want: `{"Done":{"checkbox":true}}`},
+		{name: "unchecked", prop: "Done", value: "", want: `{"Done":{"checkbox":false}}`},
+		{name: "bad checkbox", prop: "Done", value: "maybe", wantErr: "not a checkbox value"},
+		{name: "number", prop: "Estimate", value: "2.5", want: `{"Estimate":{"number":2.5}}`},
+		{name: "bad number", prop: "Estimate", value: "two", wantErr: "not a number"},
+		{name: "date", prop: "Due", value: "14-03-2025", want: `{"Due":{"date":{"start":"2025-03-14"}}}`},
+		{name: "date range", prop: "Due", value: "14-03-2025 to 16-03-2025", want: `{"Due":{"date":{"end":"2025-03-16","start":"2025-03-14"}}}`},
+		{name: "clear date", prop: "Due", value: "", want: `{"Due":{"date":null}}`},
+		{name: "url", prop: "Link", value: "https://example.com", want: `{"Link":{"url":"https://example.com"}}`},
+		{name: "people", prop: "Owner", value: "ada@example.com", want: `{"Owner":{"people":[{"id":"9f8e7d6c-5b4a-3928-1706-f5e4d3c2b1a0","object":"user"}]}}`},
+		{name: "relation", prop: "Blocks", value: "https://www.notion.so/Plan-0123456789abcdef0123456789abcdef", want: `{"Blocks":{"relation":[{"id":"01234567-89ab-cdef-0123-456789abcdef"}]}}`},
+		{name: "title", prop: "Name", value: "x", wantErr: "--title"},
+		{name: "read-only", prop: "Created", value: "x", wantErr: "cannot be set"},
+		{name: "unknown property", prop: "Size", value: "x", wantErr: `unknown property "Size"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := []propAssignment{{name: tt.prop, value: tt.value}}
+			data, err := encodeProps(context.Background(), notionSvc, props, dates)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if code := ExitCode(err); code != consts.ExitUsage {
+					t.Errorf("exit code = %d, want %d", code, consts.ExitUsage)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := json.Marshal(data)
+			var gotValue, wantValue interface{}
+			_ = json.Unmarshal(got, &gotValue)
+			if err := json.Unmarshal([]byte(tt.want), &wantValue); err != nil {
+				t.Fatalf("bad JSON %s: %v", tt.want, err)
+			}
+			got, _ = json.Marshal(gotValue)
+			want, _ := json.Marshal(wantValue)
+			if string(got) != string(want) {
+				t.Errorf("got  %s\nwant %s", got, want)
+			}
+		})
+	}
+
+	// Encoded values are accepted by Notion when creating a page
+	props := []propAssignment{{name: "state", value: "done"}, {name: "tags", value: "home"}, {name: "done", value: "true"}}
+	data, err := encodeProps(context.Background(), notionSvc, props, dates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data["Name"] = map[string]interface{}{
+		"title": []interface{}{map[string]interface{}{"text": map[string]interface{}{"content": "Encoded"}}},
+	}
+	body, _ := json.Marshal(data)
+	var sent map[string]interface{}
+	_ = json.Unmarshal(body, &sent)
+	if _, err := server.AddPage("1a2b3c4d5e6f708192a3b4c5d6e7f801", sent); err != nil {
+		t.Errorf("the fake server rejected %s: %v", body, err)
+	}
+	for i, want := range []string{"State", "Tags", "Done"} {
+		if props[i].name != want {
+			t.Errorf("prop %d is named %q, want %q", i, props[i].name, want)
+		}
+	}
+}
+
+func TestResolveFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		locale  utility.DateLocale
+		filter  models.TodoFilter
+		want    models.TodoFilter
+		wantErr string
+	}{
+		{
+			name:   "day first",
+			locale: utility.LocaleDMY,
+			filter: models.TodoFilter{DueBefore: "02-03-2025", CreatedSince: "2025-01-31"},
+			want:   models.TodoFilter{DueBefore: "2025-03-02", CreatedSince: "2025-01-31"},
+		},
+		{
+			name:   "month first",
+			locale: utility.LocaleMDY,
+			filter: models.TodoFilter{DueAfter: "02-03-2025", EditedSince: "12/25/2024"},
+			want:   models.TodoFilter{DueAfter: "2025-02-03", EditedSince: "2024-12-25"},
+		},
+		{name: "invalid day", locale: utility.LocaleDMY, filter: models.TodoFilter{DueOn: "31-02-2025"}, wantErr: "--due-on"},
+		{name: "invalid month", locale: utility.LocaleMDY, filter: models.TodoFilter{DueOn: "31-01-2025"}, wantErr: "--due-on"},
+		{
+			name:    "contradictory",
+			locale:  utility.LocaleDMY,
+			filter:  models.TodoFilter{DueAfter: "10-03-2025", DueBefore: "01-03-2025"},
+			wantErr: "must be later",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveFilter(tt.filter, dateSettings{locale: tt.locale})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.DueBefore != tt.want.DueBefore || got.DueAfter != tt.want.DueAfter || got.DueOn != tt.want.DueOn ||
+				got.CreatedSince != tt.want.CreatedSince || got.EditedSince != tt.want.EditedSince {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// The statuses of the caller's filter are not changed
+	filter := models.TodoFilter{Statuses: []string{"todo"}}
+	resolved, err := resolveFilter(filter, dateSettings{locale: utility.LocaleDMY})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved.Statuses[0] = "Todo"
+	if filter.Statuses[0] != "todo" {
+		t.Errorf("resolveFilter shares the statuses of its argument")
+	}
+}
+
+func TestNormalizeStatuses(t *testing.T) {
+	server, notionSvc := newTestNotion(t, "2b3c4d5e6f708192a3b4c5d6e7f80912", fakenotion.TodoSchema())
+	credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+	statusSvc := statuses.NewStatusSvc(notionSvc, credService, files.NewFileService(files.CacheDir, consts.StatusCacheFileName))
+
+	filter := models.TodoFilter{Statuses: []string{"in progress", " DONE"}}
+	options, err := normalizeStatuses(context.Background(), statusSvc, &filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(filter.Statuses, ", "); got != "In Progress, Done" {
+		t.Errorf("statuses normalized to %q", got)
+	}
+	if got := strings.Join(statuses.Names(options), ", "); got != "Todo, In Progress, Done" {
+		t.Errorf("database statuses are %q", got)
+	}
+	filter = models.TodoFilter{Statuses: []string{"Archived"}}
+	if _, err := normalizeStatuses(context.Background(), statusSvc, &filter); ExitCode(err) != consts.ExitUsage {
+		t.Errorf("unknown status: error = %v, want a usage error", err)
+	}
+
+	// Statuses that cannot be loaded leave the filter unchanged
+	server.Close()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	filter = models.TodoFilter{Statuses: []string{"done"}}
+	if options, err := normalizeStatuses(context.Background(), statusSvc, &filter); err != nil || options != nil {
+		t.Errorf("got statuses %v from a stopped server", statuses.Names(options))
+	}
+	if filter.Statuses[0] != "done" {
+		t.Errorf("status changed to %q", filter.Statuses[0])
+	}
+}
@@ -7,20 +7,48 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// rootCommands lists the commands shown on the welcome screen
+var rootCommands = []struct {
+	name        string
+	description string
+}{
+	{"guide", "Interactive setup guide"},
+	{"config", "Configure Notion integration ('config set' for scripts)"},
+	{"add", "Add a new todo item"},
+	{"list", "View and manage todos"},
+	{"show", "Show a todo with its notes"},
+	{"edit", "Change the fields of a todo"},
+	{"done", "Mark todos as complete"},
+	{"start", "Mark todos as in progress"},
+	{"set-status", "Set todos to a named status"},
+	{"sub", "Add, list and check sub-tasks"},
+	{"view", "Save and reuse list filters"},
+	{"profile", "Switch between config profiles"},
+	{"db", "Manage the databases of a profile"},
+	{"version", "Show version information"},
+}
+
 func Root(cmd *cobra.Command, args []string) {
 	// Display welcome screen when no subcommand is provided
 	width := 80
-	height := 20
+	height := 40
+
+	commands := ""
+	for _, command := range rootCommands {
+		commands += "• " + tpl.AccentStyle.Render(fmt.Sprintf("%-10s", command.name)) + " - " + command.description + "\n"
+	}
 
 	content := tpl.RenderTitle("Notion Todo CLI", width) + "\n\n" +
 		tpl.AccentStyle.Render("📝 Welcome to Notion Todo!") + "\n\n" +
 		tpl.SubtitleStyle.Render("Available Commands:") + "\n" +
-		"• " + tpl.AccentStyle.Render("guide") + "   - Interactive setup guide\n" +
-		"• " + tpl.AccentStyle.Render("config") + "  - Configure Notion integration\n" +
-		"• " + tpl.AccentStyle.Render("add") + "     - Add a new todo item\n" +
-		"• " + tpl.AccentStyle.Render("list") + "    - View and manage todos\n" +
-		"• " + tpl.AccentStyle.Render("version") + " - Show version information\n\n" +
-		tpl.HelpStyle.Render("Get started:\n1. Run 'todo guide' for interactive setup\n2. Or run 'todo config' to set up manually\n3. Use 'todo add \"task\" --date DD-MM-YYYY' to create todos\n4. Use 'todo list' to view and update your todos\n\nFor detailed help: todo --help")
+		commands + "\n" +
+		tpl.HelpStyle.Render("Get started:\n"+
+			"1. Run 'todo guide' for interactive setup\n"+
+			"2. Or run 'todo config' to set up manually\n"+
+			"3. Use 'todo add \"task\" --date tomorrow' to create todos\n"+
+			"   Dates also read 'fri 17:00', 'in 3 days' or the configured format\n"+
+			"4. Use 'todo list' to view and update your todos\n\n"+
+			"For detailed help: todo --help or todo <command> --help")
 
 	fmt.Println(tpl.RenderContainer(content, width, height))
 }
@@ -0,0 +1,59 @@
+package processors
+
+import (
+	"fmt"
+	"strings"
+
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/markdown"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/spf13/cobra"
+)
+
+// Show prints the properties and page body of a single todo
+func Show(cmd *cobra.Command, args []string) error {
+	ref := strings.Join(args, " ")
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+	notionSvc := notion.NewNotionImpl(credService)
+
+	matches, err := resolveTodos(ctx, notionSvc, ref, false)
+	if err != nil {
+		return err
+	}
+	todo := matches[0]
+	blocks, err := notionSvc.GetBlocks(ctx, todo.ID)
+	if err != nil {
+		return reportError("Failed to load notes", err)
+	}
+
+	fmt.Println(tpl.AccentStyle.Bold(true).Render(todo.Title) + " " + tpl.HelpStyle.Render("("+utility.ShortID(todo.ID)+")"))
+	fmt.Println("Status:    " + displayStatus(todo.Status))
+	if todo.DueDate != nil {
+		fmt.Println("Due:       " + loadDateSettings().formatDue(*todo.DueDate, todo.DueDateEnd, todo.DueTimeZone))
+	}
+	if todo.Priority != nil {
+		fmt.Println("Priority:  " + *todo.Priority)
+	}
+	if len(todo.Tags) > 0 {
+		fmt.Println("Tags:      " + strings.Join(todo.Tags, ", "))
+	}
+	if len(todo.Assignees) > 0 {
+		fmt.Println("Assignees: " + strings.Join(todo.Assignees, ", "))
+	}
+	fmt.Println(tpl.HelpStyle.Render(todo.URL))
+	fmt.Println()
+
+	if len(blocks) == 0 {
+		fmt.Println(tpl.HelpStyle.Render("No notes. Add one with 'n' in 'todo list' or 'todo add --note'."))
+		return nil
+	}
+	fmt.Print(markdown.Render(blocks))
+	return nil
+}
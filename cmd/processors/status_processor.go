@@ -0,0 +1,111 @@
+package processors
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/statuses"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/spf13/cobra"
+)
+
+// Done marks the referenced todos with the first status of the Complete group
+func Done(cmd *cobra.Command, args []string) error {
+	return setStatusByGroup(cmd, strings.Join(args, " "), models.StatusGroupComplete)
+}
+
+// Start marks the referenced todos with the first status of the In progress group
+func Start(cmd *cobra.Command, args []string) error {
+	return setStatusByGroup(cmd, strings.Join(args, " "), models.StatusGroupInProgress)
+}
+
+// SetStatus sets the referenced todos to the status named by the first argument
+func SetStatus(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	option, options, err := statusService().Find(ctx, args[0])
+	if err != nil {
+		return reportError("Could not load statuses", err)
+	}
+	if option == nil {
+		return usagef("Invalid status '%s'. Valid statuses are: %s", args[0], strings.Join(statuses.Names(options), ", "))
+	}
+	return changeStatus(cmd, strings.Join(args[1:], " "), *option)
+}
+
+// setStatusByGroup resolves the status for group and applies it to ref
+func setStatusByGroup(cmd *cobra.Command, ref, group string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	options, err := statusService().GetStatuses(ctx)
+	if err != nil {
+		return reportError("Could not load statuses", err)
+	}
+	option := statuses.FirstInGroup(options, group)
+	if option == nil {
+		err := usagef("The database has no status in the %s group", group)
+		fmt.Fprintln(os.Stderr, tpl.RenderError(err.Error()))
+		fmt.Fprintln(os.Stderr, tpl.RenderHelp("Use 'todo set-status' with one of: "+strings.Join(statuses.Names(options), ", ")))
+		return Reported(err)
+	}
+	return changeStatus(cmd, ref, *option)
+}
+
+// changeStatus updates every todo matched by ref to status, honouring the
+// --all-matching and --dry-run flags
+func changeStatus(cmd *cobra.Command, ref string, status models.StatusOption) error {
+	allMatching, _ := cmd.Flags().GetBool("all-matching")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+	notionSvc := notion.NewNotionImpl(credService)
+
+	matches, err := resolveTodos(ctx, notionSvc, ref, allMatching)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, todo := range matches {
+		label := fmt.Sprintf("%s (%s)", todo.Title, utility.ShortID(todo.ID))
+		if todo.Status == status.Name {
+			fmt.Println(tpl.RenderInfo(fmt.Sprintf("%s is already %s", label, status.Name)))
+			continue
+		}
+		change := fmt.Sprintf("%s: %s → %s", label, displayStatus(todo.Status), status.Name)
+		if dryRun {
+			fmt.Println("Would update " + change)
+			continue
+		}
+		if err := notionSvc.UpdatePageStatus(ctx, todo.ID, status.Name); err != nil {
+			fmt.Fprintln(os.Stderr, tpl.RenderError(errorMessage("Failed to update "+label, err)))
+			failed++
+			continue
+		}
+		fmt.Println(tpl.RenderSuccess("Updated " + change))
+	}
+	if failed > 0 {
+		return Reported(fmt.Errorf("%d of %d todos were not updated", failed, len(matches)))
+	}
+	return nil
+}
+
+// displayStatus names an unset status
+func displayStatus(status string) string {
+	if status == "" {
+		return "(none)"
+	}
+	return status
+}
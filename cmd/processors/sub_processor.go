@@ -0,0 +1,123 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/markdown"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/utility"
+	"github.com/spf13/cobra"
+)
+
+// SubAdd appends unchecked sub-tasks to the page of a todo
+func SubAdd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	notionSvc := notion.NewNotionImpl(config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName)))
+
+	matches, err := resolveTodos(ctx, notionSvc, args[0], false)
+	if err != nil {
+		return err
+	}
+	todo := matches[0]
+	var blocks []models.BlockData
+	for _, text := range args[1:] {
+		blocks = append(blocks, markdown.ToDo(text, false))
+	}
+	if err := notionSvc.AppendBlocks(ctx, todo.ID, blocks); err != nil {
+		return reportError("Failed to add sub-tasks", err)
+	}
+
+	fmt.Println(tpl.RenderSuccess(fmt.Sprintf("Added %d sub-task(s) to %s (%s)", len(blocks), todo.Title, utility.ShortID(todo.ID))))
+	return printSubTasks(ctx, notionSvc, todo)
+}
+
+// SubList prints the sub-tasks of a todo with its progress
+func SubList(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	notionSvc := notion.NewNotionImpl(config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName)))
+
+	matches, err := resolveTodos(ctx, notionSvc, args[0], false)
+	if err != nil {
+		return err
+	}
+	todo := matches[0]
+	fmt.Println(todo.Title + " " + tpl.HelpStyle.Render("("+utility.ShortID(todo.ID)+")"))
+	return printSubTasks(ctx, notionSvc, todo)
+}
+
+// SubCheck checks, or with --uncheck unchecks, sub-tasks of a todo
+func SubCheck(cmd *cobra.Command, args []string) error {
+	uncheck, _ := cmd.Flags().GetBool("uncheck")
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	notionSvc := notion.NewNotionImpl(config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName)))
+
+	matches, err := resolveTodos(ctx, notionSvc, args[0], false)
+	if err != nil {
+		return err
+	}
+	todo := matches[0]
+	subTasks, err := notionSvc.ListSubTasks(ctx, todo.ID)
+	if err != nil {
+		return reportError("Failed to load sub-tasks", err)
+	}
+
+	// Resolve every reference before changing anything
+	var indexes []int
+	for _, ref := range args[1:] {
+		index, err := utility.ResolveSubTaskRef(subTasks, ref)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tpl.RenderError(err.Error()))
+			fmt.Fprintln(os.Stderr, tpl.RenderHelp(fmt.Sprintf("Use 'todo sub list %s' to see the sub-tasks", args[0])))
+			if _, ok := err.(*utility.SubTaskNotFoundError); !ok {
+				err = usageError(err)
+			}
+			return Reported(err)
+		}
+		indexes = append(indexes, index)
+	}
+
+	verb := "Checked"
+	if uncheck {
+		verb = "Unchecked"
+	}
+	for _, index := range indexes {
+		subTask := subTasks[index]
+		if err := notionSvc.SetSubTaskChecked(ctx, subTask.ID, !uncheck); err != nil {
+			return reportError("Failed to update "+subTask.Text, err)
+		}
+		fmt.Println(tpl.RenderSuccess(verb + " " + subTask.Text))
+	}
+	return printSubTasks(ctx, notionSvc, todo)
+}
+
+// printSubTasks prints the numbered sub-tasks of todo and its progress
+func printSubTasks(ctx context.Context, notionSvc notion.Notion, todo models.TodoItem) error {
+	subTasks, err := notionSvc.ListSubTasks(ctx, todo.ID)
+	if err != nil {
+		return reportError("Failed to load sub-tasks", err)
+	}
+	if len(subTasks) == 0 {
+		fmt.Println(tpl.HelpStyle.Render("No sub-tasks. Add some with 'todo sub add <todo> <text>...'"))
+		return nil
+	}
+	for i, subTask := range subTasks {
+		mark := "[ ]"
+		if subTask.Checked {
+			mark = "[x]"
+		}
+		fmt.Printf("  %d. %s %s\n", i+1, mark, subTask.Text)
+	}
+	done, total := models.SubTaskProgress(subTasks)
+	fmt.Println(tpl.HelpStyle.Render(fmt.Sprintf("%d/%d done", done, total)))
+	return nil
+}
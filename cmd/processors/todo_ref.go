@@ -0,0 +1,63 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/utility"
+)
+
+// resolveTodos returns the todos matched by ref, failing when it matches none,
+// or several without allMatching. A full page ID is fetched directly rather
+// than searched for.
+func resolveTodos(ctx context.Context, notionSvc notion.Notion, ref string, allMatching bool) ([]models.TodoItem, error) {
+	if utility.IsPageID(ref) {
+		todo, err := notionSvc.GetPage(ctx, strings.TrimSpace(ref))
+		if notion.IsNotFound(err) {
+			return nil, reportRefError(&utility.NotFoundRefError{Ref: ref})
+		}
+		if err != nil {
+			return nil, reportError("Failed to fetch todo", err)
+		}
+		return []models.TodoItem{*todo}, nil
+	}
+
+	// Indexes refer to rows of the unfiltered list, as numbered by
+	// 'todo list -o table'
+	todos, err := notionSvc.QueryPages(ctx, models.TodoFilter{})
+	if err != nil {
+		return nil, reportError("Failed to fetch todos", err)
+	}
+
+	matches, err := utility.ResolveTodoRef(todos, ref, allMatching)
+	if err != nil {
+		return nil, reportRefError(err)
+	}
+	return matches, nil
+}
+
+// reportError prints a failed operation with its hint and returns err
+// marked as reported
+func reportError(prefix string, err error) error {
+	fmt.Fprintln(os.Stderr, tpl.RenderError(prefix+": "+err.Error()))
+	fmt.Fprintln(os.Stderr, tpl.RenderHelp(errorHint(err)))
+	return Reported(err)
+}
+
+// reportRefError prints a todo reference that matched no or several todos,
+// listing the matches of an ambiguous one
+func reportRefError(err error) error {
+	fmt.Fprintln(os.Stderr, tpl.RenderError(err.Error()))
+	if ambiguous, ok := err.(*utility.AmbiguousRefError); ok {
+		for _, todo := range ambiguous.Matches {
+			fmt.Fprintf(os.Stderr, "  %s  %s\n", utility.ShortID(todo.ID), todo.Title)
+		}
+		fmt.Fprintln(os.Stderr, tpl.RenderHelp("Use a short ID to pick one, or --all-matching to change them all"))
+	}
+	return Reported(err)
+}
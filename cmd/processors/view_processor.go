@@ -0,0 +1,107 @@
+package processors
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/caffeines/notion-todo/cmd/output"
+	tpl "github.com/caffeines/notion-todo/cmd/template"
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/views"
+	"github.com/spf13/cobra"
+)
+
+// viewService returns the saved view service
+func viewService() views.Views {
+	return views.NewViewSvc(config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName)))
+}
+
+// ViewSave saves the filter and sort flags as a named view
+func ViewSave(cmd *cobra.Command, args []string) error {
+	filter, err := applyFilterFlags(cmd, models.TodoFilter{})
+	if err == nil {
+		// Validate now, but keep dates as entered so the view stays relative
+		_, err = resolveFilter(filter, loadDateSettings())
+	}
+	columns, _ := cmd.Flags().GetStringSlice("columns")
+	if err == nil {
+		err = output.ValidateColumns(columns)
+	}
+	if err != nil {
+		return usageError(err)
+	}
+
+	ctx, cancel := commandContext(cmd)
+	_, err = normalizeStatuses(ctx, statusService(), &filter)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	view := models.View{Name: args[0], Filter: filter, Columns: columns}
+	if err := viewService().Save(view); err != nil {
+		fmt.Fprintln(os.Stderr, tpl.RenderError("Failed to save view: "+err.Error()))
+		return Reported(err)
+	}
+	fmt.Println(tpl.RenderSuccess(fmt.Sprintf("Saved view '%s'", view.Name)))
+	fmt.Println(tpl.RenderHelp(fmt.Sprintf("Open it with: todo list --view %q", view.Name)))
+	return nil
+}
+
+// ViewList prints the saved views
+func ViewList(cmd *cobra.Command, args []string) error {
+	list, err := viewService().List()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tpl.RenderError("Failed to read views: "+err.Error()))
+		return Reported(err)
+	}
+	if len(list) == 0 {
+		fmt.Println("No saved views. Save one with: todo view save <name> [filters]")
+		return nil
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tFILTER\tCOLUMNS")
+	for _, view := range list {
+		description := view.Filter.String()
+		if description == "" {
+			description = "-"
+		}
+		columns := strings.Join(view.Columns, ",")
+		if columns == "" {
+			columns = "-"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", view.Name, description, columns)
+	}
+	table.Flush()
+	return nil
+}
+
+// ViewDelete deletes a saved view
+func ViewDelete(cmd *cobra.Command, args []string) error {
+	if err := viewService().Delete(args[0]); err != nil {
+		fmt.Fprintln(os.Stderr, tpl.RenderError("Failed to delete view: "+err.Error()))
+		return Reported(err)
+	}
+	fmt.Println(tpl.RenderSuccess(fmt.Sprintf("Deleted view '%s'", args[0])))
+	return nil
+}
+
+// CompleteViews completes saved view names
+func CompleteViews(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	_ = SelectConfig(cmd, args)
+	list, err := viewService().List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(list))
+	for _, view := range list {
+		names = append(names, view.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/caffeines/notion-todo/cmd/processors"
+
+	"github.com/spf13/cobra"
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage config profiles",
+	Long: `Keep the token, database and saved views of several workspaces side by side.
+Create a profile with 'todo config --profile <name>'. Commands use the profile given with --profile,
+then the TODO_PROFILE environment variable, then the one chosen with 'todo profile use'.`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List profiles, marking the one in use",
+	RunE:    processors.ProfileList,
+	Args:    cobra.NoArgs,
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:               "use <name>",
+	Short:             "Use a profile by default",
+	RunE:              processors.ProfileUse,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: processors.CompleteProfiles,
+	Example: `todo profile use work
+todo profile use default`,
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:               "remove <name>",
+	Aliases:           []string{"rm"},
+	Short:             "Remove a profile",
+	RunE:              processors.ProfileRemove,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: processors.CompleteProfiles,
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd, profileUseCmd, profileRemoveCmd)
+}
@@ -1,8 +1,15 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/caffeines/notion-todo/cmd/output"
 	"github.com/caffeines/notion-todo/cmd/processors"
 	"github.com/spf13/cobra"
 )
@@ -13,17 +20,74 @@ var rootCmd = &cobra.Command{
 	Short: "CLI for Todo with Notion database",
 	Long:  `A modern command line interface for managing todos with Notion database integration.`,
 	Run:   processors.Root,
+	Args:  rootArgs,
+	// Suggest commands within two edits of an unknown one, as cobra does
+	SuggestionsMinimumDistance: 2,
+	// Every command reads its config through the selected profile
+	PersistentPreRunE: processors.SelectConfig,
+	// Execute reports errors with the exit code matching them
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Interrupts cancel the command context so in-flight Notion requests stop.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	markUsageErrors(rootCmd)
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	stop()
 	if err != nil {
-		os.Exit(1)
+		processors.Report(cmd, err)
+		os.Exit(processors.ExitCode(err))
+	}
+}
+
+// rootArgs rejects arguments that name no command, suggesting the closest
+// commands
+func rootArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("unknown command %q for %q", args[0], cmd.CommandPath())
+	if suggestions := cmd.SuggestionsFor(args[0]); len(suggestions) > 0 {
+		msg += ", did you mean " + strings.Join(suggestions, " or ") + "?"
+	}
+	return errors.New(msg)
+}
+
+// markUsageErrors makes the argument errors of cmd and its subcommands
+// usage errors, so they exit with consts.ExitUsage
+func markUsageErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validate(cmd, args); err != nil {
+				return &processors.UsageError{Err: err}
+			}
+			return nil
+		}
+	}
+	for _, child := range cmd.Commands() {
+		markUsageErrors(child)
 	}
 }
 
 func init() {
+	// Subcommands inherit the flag error func of the root command
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &processors.UsageError{Err: err}
+	})
 	// Global flags and configuration can be added here
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Cancel Notion requests after this duration (e.g. 30s, 2m)")
+	rootCmd.PersistentFlags().String("config", "", "Path of the config file (default $XDG_CONFIG_HOME/notion-todo/config.json)")
+	rootCmd.PersistentFlags().String("profile", "", "Config profile to use (default $TODO_PROFILE or the active profile)")
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", processors.CompleteProfiles)
+	rootCmd.PersistentFlags().String("token", "", "Notion integration token for this run (default $NOTION_TOKEN or the profile's token)")
+	rootCmd.PersistentFlags().String("database", "", "Notion database ID for this run (default $NOTION_DATABASE_ID or the profile's database)")
+	rootCmd.PersistentFlags().StringP("output", "o", "", "Print results as "+output.FormatNames()+" instead of the interactive view (default table when not a terminal)")
+	_ = rootCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return strings.Split(output.FormatNames(), "|"), cobra.ShellCompDirectiveNoFileComp
+	})
 }
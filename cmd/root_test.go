@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/caffeines/notion-todo/cmd/processors"
+	"github.com/caffeines/notion-todo/consts"
+)
+
+func TestUsageErrors(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	markUsageErrors(rootCmd)
+
+	tests := [][]string{
+		{"lst"},
+		{"add", "--nope"},
+		{"list", "--timeout", "soon"},
+		{"done"},
+		{"view", "save"},
+		{"profile", "use", "a", "b"},
+		{"--profile", "../work", "list"},
+	}
+	for _, args := range tests {
+		rootCmd.SetArgs(args)
+		_, err := rootCmd.ExecuteC()
+		if code := processors.ExitCode(err); code != consts.ExitUsage {
+			t.Errorf("todo %v: exit code %d, want %d (error %v)", args, code, consts.ExitUsage, err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/caffeines/notion-todo/cmd/processors"
+
+	"github.com/spf13/cobra"
+)
+
+// setStatusCmd represents the set-status command
+var setStatusCmd = &cobra.Command{
+	Use:   "set-status <status> <todo>",
+	Short: "Set the status of a todo",
+	Long: `Set the status of a todo to any status of the database without opening the list view.
+The todo is referenced by its number or short ID in 'todo list -o table', its page ID, or part of its title.`,
+	RunE: processors.SetStatus,
+	Args: cobra.MinimumNArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return processors.CompleteStatuses(cmd, args, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	Example: `todo set-status "In review" 4
+todo set-status Blocked "deploy" --all-matching
+todo set-status Done 1a2b3c4d --dry-run`,
+}
+
+func init() {
+	rootCmd.AddCommand(setStatusCmd)
+	addStatusChangeFlags(setStatusCmd)
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/caffeines/notion-todo/cmd/processors"
+
+	"github.com/spf13/cobra"
+)
+
+// showCmd represents the show command
+var showCmd = &cobra.Command{
+	Use:   "show <todo>",
+	Short: "Show a todo with its notes",
+	Long: `Show the status, due date and page body of a todo.
+The page body is printed as Markdown: paragraphs, headings, lists, to-dos, quotes and code.
+The todo is referenced by its number or short ID in 'todo list -o table', its page ID, or part of its title.`,
+	RunE: processors.Show,
+	Args: cobra.MinimumNArgs(1),
+	Example: `todo show 3
+todo show "login bug"`,
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/caffeines/notion-todo/cmd/processors"
+
+	"github.com/spf13/cobra"
+)
+
+// startCmd represents the start command
+var startCmd = &cobra.Command{
+	Use:   "start <todo>",
+	Short: "Mark a todo as in progress",
+	Long: `Mark a todo as in progress without opening the list view.
+The todo is referenced by its number or short ID in 'todo list -o table', its page ID, or part of its title.
+The first status in the database's In progress group is used.`,
+	RunE: processors.Start,
+	Args: cobra.MinimumNArgs(1),
+	Example: `todo start 2
+todo start "write report"`,
+}
+
+func init() {
+	rootCmd.AddCommand(startCmd)
+	addStatusChangeFlags(startCmd)
+}
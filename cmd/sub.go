@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/caffeines/notion-todo/cmd/processors"
+
+	"github.com/spf13/cobra"
+)
+
+// subCmd represents the sub command
+var subCmd = &cobra.Command{
+	Use:   "sub",
+	Short: "Manage the sub-tasks of a todo",
+	Long: `Manage sub-tasks, the checkboxes (to_do blocks) in the page body of a todo.
+The todo is referenced by its number or short ID in 'todo list -o table', its page ID, or part of its title.`,
+}
+
+var subAddCmd = &cobra.Command{
+	Use:   "add <todo> <sub-task>...",
+	Short: "Add unchecked sub-tasks to a todo",
+	RunE:  processors.SubAdd,
+	Args:  cobra.MinimumNArgs(2),
+	Example: `todo sub add "release" "Tag version" "Write changelog"
+todo sub add 3 "Email **finance**"`,
+}
+
+var subListCmd = &cobra.Command{
+	Use:     "list <todo>",
+	Aliases: []string{"ls"},
+	Short:   "List the sub-tasks of a todo",
+	RunE:    processors.SubList,
+	Args:    cobra.ExactArgs(1),
+}
+
+var subCheckCmd = &cobra.Command{
+	Use:   "check <todo> <sub-task>...",
+	Short: "Check sub-tasks off",
+	Long: `Check sub-tasks off, or uncheck them with --uncheck.
+Sub-tasks are referenced by their number in 'todo sub list' or part of their text.`,
+	RunE: processors.SubCheck,
+	Args: cobra.MinimumNArgs(2),
+	Example: `todo sub check "release" 1 2
+todo sub check 3 changelog --uncheck`,
+}
+
+func init() {
+	rootCmd.AddCommand(subCmd)
+	subCmd.AddCommand(subAddCmd, subListCmd, subCheckCmd)
+	subCheckCmd.Flags().BoolP("uncheck", "u", false, "Uncheck the sub-tasks instead")
+}
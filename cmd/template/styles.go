@@ -99,22 +99,18 @@ var (
 			Dark:  "#d1d5db", // Gray-300
 		})
 
-	StatusStyles = map[string]lipgloss.Style{
-		"Pending": lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{
-				Light: "#92400e", // Amber-800
-				Dark:  "#fbbf24", // Amber-400
-			}),
-		"In Progress": lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{
-				Light: "#1e40af", // Blue-800
-				Dark:  "#60a5fa", // Blue-400
-			}),
-		"Done": lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{
-				Light: "#065f46", // Emerald-800
-				Dark:  "#34d399", // Emerald-400
-			}),
+	// NotionColors maps Notion option colors to adaptive terminal colors
+	NotionColors = map[string]lipgloss.AdaptiveColor{
+		"default": {Light: "#374151", Dark: "#d1d5db"}, // Gray-700 / Gray-300
+		"gray":    {Light: "#6b7280", Dark: "#9ca3af"}, // Gray-500 / Gray-400
+		"brown":   {Light: "#78350f", Dark: "#d97706"}, // Amber-900 / Amber-600
+		"orange":  {Light: "#9a3412", Dark: "#fb923c"}, // Orange-800 / Orange-400
+		"yellow":  {Light: "#92400e", Dark: "#fbbf24"}, // Amber-800 / Amber-400
+		"green":   {Light: "#065f46", Dark: "#34d399"}, // Emerald-800 / Emerald-400
+		"blue":    {Light: "#1e40af", Dark: "#60a5fa"}, // Blue-800 / Blue-400
+		"purple":  {Light: "#6b21a8", Dark: "#c084fc"}, // Purple-800 / Purple-400
+		"pink":    {Light: "#9d174d", Dark: "#f472b6"}, // Pink-800 / Pink-400
+		"red":     {Light: "#dc2626", Dark: "#f87171"}, // Red-600 / Red-400
 	}
 
 	MessageStyle = lipgloss.NewStyle().
@@ -191,6 +187,15 @@ func RenderContainer(content string, width, height int) string {
 		Render(content)
 }
 
+// StatusStyle returns the style for a Notion option color such as "blue"
+func StatusStyle(color string) lipgloss.Style {
+	adaptive, ok := NotionColors[color]
+	if !ok {
+		adaptive = NotionColors["default"]
+	}
+	return lipgloss.NewStyle().Foreground(adaptive)
+}
+
 func RenderHelp(text string) string {
 	return HelpStyle.Render(text)
 }
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/caffeines/notion-todo/cmd/processors"
+
+	"github.com/spf13/cobra"
+)
+
+// viewCmd represents the view command
+var viewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Manage saved list views",
+	Long: `Save named combinations of list filters, sorting and columns, and open them with 'todo list --view <name>'.
+Dates such as "today" are stored as entered, so views stay relative to the day they are opened.`,
+}
+
+var viewSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save the given filters as a named view",
+	RunE:  processors.ViewSave,
+	Args:  cobra.ExactArgs(1),
+	Example: `todo view save overdue --overdue --sort due
+todo view save doing --status "In progress" --sort edited:desc
+todo view save today --due-on today --columns id,status,title`,
+}
+
+var viewListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List saved views",
+	RunE:    processors.ViewList,
+	Args:    cobra.NoArgs,
+}
+
+var viewDeleteCmd = &cobra.Command{
+	Use:               "delete <name>",
+	Aliases:           []string{"rm"},
+	Short:             "Delete a saved view",
+	RunE:              processors.ViewDelete,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: processors.CompleteViews,
+}
+
+func init() {
+	rootCmd.AddCommand(viewCmd)
+	viewCmd.AddCommand(viewSaveCmd, viewListCmd, viewDeleteCmd)
+	processors.AddFilterFlags(viewSaveCmd)
+}
@@ -1,5 +1,39 @@
 package consts
 
+import "time"
+
 const (
 	ConfigFileName = "config.json"
+	// StatusCacheFileName caches the status options of the database
+	StatusCacheFileName = "statuses.json"
+	// StatusCacheTTL is how long cached statuses are used before refetching
+	StatusCacheTTL = 24 * time.Hour
+	// DefaultProfile names the profile stored at the top level of the config
+	DefaultProfile = "default"
+	// DefaultDatabase is the alias of the database set with 'todo config'
+	DefaultDatabase = "default"
+	// SecretsFileName holds the tokens encrypted with a passphrase
+	SecretsFileName = "secrets.enc"
+)
+
+// Environment variables that override values from the config file
+const (
+	// EnvToken overrides the integration token
+	EnvToken = "NOTION_TOKEN"
+	// EnvDatabaseID overrides the default database of the profile
+	EnvDatabaseID = "NOTION_DATABASE_ID"
+	// EnvAPIURL overrides the Notion API base URL
+	EnvAPIURL = "NOTION_API_URL"
+	// EnvRequestTimeout overrides the per-request timeout in seconds
+	EnvRequestTimeout = "TODO_REQUEST_TIMEOUT"
+	// EnvMaxRetries overrides the retries of failed requests
+	EnvMaxRetries = "TODO_MAX_RETRIES"
+	// EnvDateFormat overrides the order of numeric dates
+	EnvDateFormat = "TODO_DATE_FORMAT"
+	// EnvTimeZone overrides the time zone times are entered in
+	EnvTimeZone = "TODO_TIME_ZONE"
+	// EnvProfile selects the config profile when --profile is not given
+	EnvProfile = "TODO_PROFILE"
+	// EnvPassphrase is the passphrase of the encrypted token file
+	EnvPassphrase = "TODO_PASSPHRASE"
 )
@@ -0,0 +1,25 @@
+package consts
+
+// Process exit codes so scripts can branch on the kind of failure
+const (
+	// ExitOK means the command succeeded
+	ExitOK = 0
+	// ExitError is a generic failure
+	ExitError = 1
+	// ExitUsage means the command was called with invalid arguments
+	ExitUsage = 2
+	// ExitUnauthorized means the token is invalid or lacks access
+	ExitUnauthorized = 3
+	// ExitNotFound means the database or page does not exist or is not shared
+	ExitNotFound = 4
+	// ExitRateLimited means Notion kept rate limiting after all retries
+	ExitRateLimited = 5
+	// ExitValidation means Notion rejected the request payload
+	ExitValidation = 6
+	// ExitUnavailable means Notion or the network failed
+	ExitUnavailable = 7
+	// ExitTimeout means the request exceeded --timeout
+	ExitTimeout = 124
+	// ExitCancelled means the command was interrupted
+	ExitCancelled = 130
+)
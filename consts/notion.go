@@ -1,7 +1,27 @@
 package consts
 
+import "time"
+
 const (
 	NOTION_VERSION = "2022-06-28"
 	API_URL        = "https://api.notion.com/v1"
 	CONTENT_TYPE   = "application/json"
+	// PAGE_SIZE is the number of results requested per page (Notion's maximum)
+	PAGE_SIZE = 100
+	// MAX_RICH_TEXT_LENGTH is the most UTF-16 code units in one rich text item
+	MAX_RICH_TEXT_LENGTH = 2000
+	// MAX_BLOCK_CHILDREN is the most blocks appended in one request
+	MAX_BLOCK_CHILDREN = 100
+)
+
+// HTTP transport defaults, overridable through the config file
+const (
+	// DEFAULT_TIMEOUT bounds a single request attempt
+	DEFAULT_TIMEOUT = 30 * time.Second
+	// DEFAULT_MAX_RETRIES is the number of retries after the first attempt
+	DEFAULT_MAX_RETRIES = 3
+	// RETRY_BASE_DELAY is the initial backoff, doubled on every retry
+	RETRY_BASE_DELAY = 500 * time.Millisecond
+	// RETRY_MAX_DELAY caps both backoff and Retry-After waits
+	RETRY_MAX_DELAY = 30 * time.Second
 )
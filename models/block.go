@@ -0,0 +1,136 @@
+package models
+
+// Block types read and written as page content
+const (
+	BlockTypeParagraph        = "paragraph"
+	BlockTypeHeading1         = "heading_1"
+	BlockTypeHeading2         = "heading_2"
+	BlockTypeHeading3         = "heading_3"
+	BlockTypeToDo             = "to_do"
+	BlockTypeBulletedListItem = "bulleted_list_item"
+	BlockTypeNumberedListItem = "numbered_list_item"
+	BlockTypeQuote            = "quote"
+	BlockTypeCode             = "code"
+	BlockTypeDivider          = "divider"
+)
+
+// BlockData is a write-shaped block, as sent in page children
+type BlockData map[string]interface{}
+
+// BlockContent is the write-shaped content of a text block
+type BlockContent struct {
+	RichText []RichTextData `json:"rich_text"`
+	// Checked is used by to_do blocks
+	Checked *bool `json:"checked,omitempty"`
+	// Language is used by code blocks
+	Language string      `json:"language,omitempty"`
+	Children []BlockData `json:"children,omitempty"`
+}
+
+// NewBlock returns a block of blockType with content, which is a
+// *BlockContent for text blocks
+func NewBlock(blockType string, content interface{}) BlockData {
+	return BlockData{
+		"object":  "block",
+		"type":    blockType,
+		blockType: content,
+	}
+}
+
+// Content returns the content of a text block built with NewBlock, or nil
+// for other blocks
+func (b BlockData) Content() *BlockContent {
+	blockType, _ := b["type"].(string)
+	content, _ := b[blockType].(*BlockContent)
+	return content
+}
+
+// WithoutChildren returns a copy of the block without its nested blocks, and
+// those blocks
+func (b BlockData) WithoutChildren() (BlockData, []BlockData) {
+	content := b.Content()
+	if content == nil || len(content.Children) == 0 {
+		return b, nil
+	}
+	stripped := *content
+	stripped.Children = nil
+	blockType, _ := b["type"].(string)
+	return NewBlock(blockType, &stripped), content.Children
+}
+
+// RichTextData is a write-shaped rich text item
+type RichTextData struct {
+	Type        string       `json:"type"`
+	Text        Text         `json:"text"`
+	Annotations *Annotations `json:"annotations,omitempty"`
+}
+
+// BlockText is the content of a text block
+type BlockText struct {
+	RichText []NotionTextContent `json:"rich_text"`
+	// Checked is set for to_do blocks
+	Checked bool `json:"checked,omitempty"`
+	// Language is set for code blocks
+	Language string `json:"language,omitempty"`
+}
+
+// PlainText returns the text of the block without formatting
+func (t BlockText) PlainText() string {
+	text := ""
+	for _, part := range t.RichText {
+		text += part.PlainText
+	}
+	return text
+}
+
+// NotionBlock is a block of page content
+type NotionBlock struct {
+	Object           string     `json:"object"`
+	ID               string     `json:"id"`
+	Type             string     `json:"type"`
+	HasChildren      bool       `json:"has_children"`
+	Paragraph        *BlockText `json:"paragraph,omitempty"`
+	Heading1         *BlockText `json:"heading_1,omitempty"`
+	Heading2         *BlockText `json:"heading_2,omitempty"`
+	Heading3         *BlockText `json:"heading_3,omitempty"`
+	ToDo             *BlockText `json:"to_do,omitempty"`
+	BulletedListItem *BlockText `json:"bulleted_list_item,omitempty"`
+	NumberedListItem *BlockText `json:"numbered_list_item,omitempty"`
+	Quote            *BlockText `json:"quote,omitempty"`
+	Code             *BlockText `json:"code,omitempty"`
+	// Children are the nested blocks, fetched separately
+	Children []NotionBlock `json:"-"`
+}
+
+// Text returns the content of a text block, or nil for other types
+func (b NotionBlock) Text() *BlockText {
+	switch b.Type {
+	case BlockTypeParagraph:
+		return b.Paragraph
+	case BlockTypeHeading1:
+		return b.Heading1
+	case BlockTypeHeading2:
+		return b.Heading2
+	case BlockTypeHeading3:
+		return b.Heading3
+	case BlockTypeToDo:
+		return b.ToDo
+	case BlockTypeBulletedListItem:
+		return b.BulletedListItem
+	case BlockTypeNumberedListItem:
+		return b.NumberedListItem
+	case BlockTypeQuote:
+		return b.Quote
+	case BlockTypeCode:
+		return b.Code
+	}
+	return nil
+}
+
+// NotionBlockList is a page of block children
+type NotionBlockList struct {
+	Object     string        `json:"object"`
+	Results    []NotionBlock `json:"results"`
+	NextCursor *string       `json:"next_cursor"`
+	HasMore    bool          `json:"has_more"`
+}
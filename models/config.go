@@ -1,6 +1,51 @@
 package models
 
-type Config struct {
+// Profile holds the credentials and database settings of one workspace
+type Profile struct {
 	DatabaseID string `json:"databaseId"`
-	Token      string `json:"token"`
+	// Token is empty when tokens are kept in a secret store
+	Token string `json:"token,omitempty"`
+	// Properties is discovered from the database schema on first use
+	Properties *PropertyMapping `json:"properties,omitempty"`
+	// Views are the saved list filters, in the order they were saved
+	Views []View `json:"views,omitempty"`
+	// Databases are further databases of the profile, keyed by alias
+	Databases map[string]Database `json:"databases,omitempty"`
+	// DefaultDatabase is the alias used when none is selected, empty for
+	// the database above
+	DefaultDatabase string `json:"defaultDatabase,omitempty"`
+}
+
+// Database is a database registered under an alias
+type Database struct {
+	ID string `json:"id"`
+	// Properties is discovered from the database schema on first use
+	Properties *PropertyMapping `json:"properties,omitempty"`
+}
+
+// Config is the config file. The default profile is stored at the top
+// level, as before profiles existed; the other settings are shared by all
+// profiles.
+type Config struct {
+	Profile
+	// APIURL overrides the Notion API base URL, e.g. for a local fake server
+	APIURL string `json:"apiUrl,omitempty"`
+	// RequestTimeout is the per-request timeout in seconds, 0 uses the default
+	RequestTimeout int `json:"requestTimeout,omitempty"`
+	// MaxRetries caps retries of failed requests, 0 uses the default and a
+	// negative value disables retries
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// DateFormat is the order of numeric dates typed and shown, e.g.
+	// DD-MM-YYYY (the default), MM-DD-YYYY or YYYY-MM-DD
+	DateFormat string `json:"dateFormat,omitempty"`
+	// TimeZone is the IANA zone times are entered in, empty for local time
+	TimeZone string `json:"timeZone,omitempty"`
+	// Profiles are the named profiles besides the default one
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+	// SecretStore is where the tokens are kept: keyring, file or plain.
+	// Empty configs keep them in this file until they are migrated.
+	SecretStore string `json:"secretStore,omitempty"`
+	// ActiveProfile is used when no profile is selected, empty for the
+	// default profile
+	ActiveProfile string `json:"activeProfile,omitempty"`
 }
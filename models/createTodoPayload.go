@@ -7,4 +7,6 @@ type Parent struct {
 type CreateTodoPayload struct {
 	Parent     Parent   `json:"parent"`
 	Properties ItemData `json:"properties"`
+	// Children is the initial page content
+	Children []BlockData `json:"children,omitempty"`
 }
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Notion property types used to track todos
+const (
+	PropertyTypeTitle    = "title"
+	PropertyTypeRichText = "rich_text"
+	PropertyTypeSelect   = "select"
+	PropertyTypeStatus   = "status"
+	PropertyTypeDate     = "date"
+	PropertyTypeCheckbox = "checkbox"
+	// Optional todo properties
+	PropertyTypeMultiSelect = "multi_select"
+	PropertyTypePeople      = "people"
+)
+
+// Status groups, as Notion groups the options of a status property
+const (
+	StatusGroupTodo       = "To-do"
+	StatusGroupInProgress = "In progress"
+	StatusGroupComplete   = "Complete"
+)
+
+// Status names used when completion is tracked by a checkbox property
+const (
+	CheckboxStatusTodo = "Todo"
+	CheckboxStatusDone = "Done"
+)
+
+// Option names that imply a status group for select properties, which have
+// no groups of their own
+var (
+	completeStatusNames   = []string{"done", "complete", "completed", "finished", "closed", "cancelled", "canceled", "archived", "resolved"}
+	inProgressStatusNames = []string{"in progress", "doing", "started", "active", "in review", "review", "blocked", "on hold", "wip"}
+)
+
+// PropertyMapping maps todo fields to properties of the Notion database
+type PropertyMapping struct {
+	Title string `json:"title"`
+	// Status is the property tracking progress, of type StatusType
+	Status     string `json:"status"`
+	StatusType string `json:"statusType"`
+	// DefaultStatus is the status given to new todos
+	DefaultStatus string `json:"defaultStatus,omitempty"`
+	// StatusGroups maps status options to their group, from the schema of
+	// status properties
+	StatusGroups map[string]string `json:"statusGroups,omitempty"`
+	// DueDate is empty when the database has no date property
+	DueDate string `json:"dueDate,omitempty"`
+	// Priority is an optional select property with options PriorityOptions
+	Priority        string   `json:"priority,omitempty"`
+	PriorityOptions []string `json:"priorityOptions,omitempty"`
+	// Tags is an optional multi_select property
+	Tags string `json:"tags,omitempty"`
+	// Assignee is an optional people property
+	Assignee string `json:"assignee,omitempty"`
+}
+
+// StatusGroup returns the group of a status: To-do, In progress or Complete
+func (m PropertyMapping) StatusGroup(status string) string {
+	if status == "" {
+		return ""
+	}
+	for name, group := range m.StatusGroups {
+		if strings.EqualFold(name, status) {
+			return group
+		}
+	}
+	if m.StatusType == PropertyTypeCheckbox {
+		if strings.EqualFold(status, CheckboxStatusDone) {
+			return StatusGroupComplete
+		}
+		return StatusGroupTodo
+	}
+	// Select options have no groups, so infer one from the name
+	for _, name := range completeStatusNames {
+		if strings.EqualFold(status, name) {
+			return StatusGroupComplete
+		}
+	}
+	for _, name := range inProgressStatusNames {
+		if strings.EqualFold(status, name) {
+			return StatusGroupInProgress
+		}
+	}
+	return StatusGroupTodo
+}
+
+// PriorityOption returns the priority option named name, ignoring case.
+// Any name is accepted when the options are not known.
+func (m PropertyMapping) PriorityOption(name string) (string, error) {
+	if m.Priority == "" {
+		return "", errors.New("the database has no priority property, run 'todo config --refresh' if one was added")
+	}
+	if len(m.PriorityOptions) == 0 {
+		return name, nil
+	}
+	for _, option := range m.PriorityOptions {
+		if strings.EqualFold(option, name) {
+			return option, nil
+		}
+	}
+	return "", &InvalidOptionError{Property: "priority", Value: name, Options: m.PriorityOptions}
+}
+
+// InvalidOptionError reports a value that is not an option of a property
+type InvalidOptionError struct {
+	Property string
+	Value    string
+	Options  []string
+}
+
+func (e *InvalidOptionError) Error() string {
+	return fmt.Sprintf("unknown %s %q, expected one of: %s", e.Property, e.Value, strings.Join(e.Options, ", "))
+}
+
+// DefaultPropertyMapping matches the Notion Todo template database
+func DefaultPropertyMapping() PropertyMapping {
+	return PropertyMapping{
+		Title:         "Title",
+		Status:        "Status",
+		StatusType:    PropertyTypeSelect,
+		DefaultStatus: "Todo",
+		DueDate:       "Due Date",
+	}
+}
+
+// Response models for Notion database API
+type NotionSelectConfig struct {
+	Options []NotionSelectOption `json:"options"`
+}
+
+type NotionStatusGroup struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Color     string   `json:"color"`
+	OptionIDs []string `json:"option_ids"`
+}
+
+type NotionStatusConfig struct {
+	Options []NotionSelectOption `json:"options"`
+	Groups  []NotionStatusGroup  `json:"groups"`
+}
+
+type NotionDatabaseProperty struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Type        string              `json:"type"`
+	Select      *NotionSelectConfig `json:"select,omitempty"`
+	MultiSelect *NotionSelectConfig `json:"multi_select,omitempty"`
+	Status      *NotionStatusConfig `json:"status,omitempty"`
+}
+
+type NotionDatabase struct {
+	Object     string                            `json:"object"`
+	ID         string                            `json:"id"`
+	Title      []NotionTextContent               `json:"title"`
+	Properties map[string]NotionDatabaseProperty `json:"properties"`
+	URL        string                            `json:"url"`
+}
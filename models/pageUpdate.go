@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// PageUpdate lists the changes to make to a todo page. Nil fields are left
+// unchanged.
+type PageUpdate struct {
+	Title *string
+	// DueDate replaces the due date, including its end and time zone
+	DueDate *DateValue
+	// ClearDueDate removes the due date
+	ClearDueDate bool
+	Status       *string
+	// Priority sets the priority option, or clears it when empty
+	Priority *string
+	// Tags replaces the tags of the todo
+	Tags *[]string
+	// Assignees replaces the assigned users, given by ID once resolved by
+	// the Notion client from names or emails
+	Assignees *[]string
+	// Extra holds further write-shaped values keyed by property name,
+	// applied after the fields above
+	Extra ItemData
+}
+
+// IsEmpty reports whether the update changes nothing
+func (u PageUpdate) IsEmpty() bool {
+	return u.Title == nil && u.DueDate == nil && !u.ClearDueDate && u.Status == nil &&
+		u.Priority == nil && u.Tags == nil && u.Assignees == nil && len(u.Extra) == 0
+}
+
+// Properties returns the write-shaped property values of the update for the
+// database mapping
+func (u PageUpdate) Properties(mapping PropertyMapping) (ItemData, error) {
+	data := ItemData{}
+
+	if u.Title != nil {
+		if strings.TrimSpace(*u.Title) == "" {
+			return nil, errors.New("title cannot be empty")
+		}
+		data[mapping.Title] = Title{
+			Titles: []TextTitle{
+				{
+					Text: Text{
+						Content: *u.Title,
+					},
+				},
+			},
+		}
+	}
+
+	if u.DueDate != nil || u.ClearDueDate {
+		if mapping.DueDate == "" {
+			return nil, errors.New("the database has no date property for due dates")
+		}
+		if u.DueDate != nil && u.ClearDueDate {
+			return nil, errors.New("cannot both set and clear the due date")
+		}
+		if u.DueDate != nil && (u.DueDate.Start == nil || *u.DueDate.Start == "") {
+			return nil, errors.New("due date needs a start date")
+		}
+		// A null date clears the property
+		data[mapping.DueDate] = map[string]interface{}{
+			PropertyTypeDate: u.DueDate,
+		}
+	}
+
+	if u.Status != nil {
+		data[mapping.Status] = NewStatusValue(mapping, *u.Status)
+	}
+
+	if u.Priority != nil {
+		priority := ""
+		if *u.Priority != "" {
+			var err error
+			if priority, err = mapping.PriorityOption(*u.Priority); err != nil {
+				return nil, err
+			}
+		} else if mapping.Priority == "" {
+			return nil, errors.New("the database has no priority property")
+		}
+		data[mapping.Priority] = NewSelectValue(priority)
+	}
+
+	if u.Tags != nil {
+		if mapping.Tags == "" {
+			return nil, errors.New("the database has no tags property, run 'todo config --refresh' if one was added")
+		}
+		data[mapping.Tags] = NewMultiSelectValue(*u.Tags)
+	}
+
+	if u.Assignees != nil {
+		if mapping.Assignee == "" {
+			return nil, errors.New("the database has no assignee property, run 'todo config --refresh' if one was added")
+		}
+		data[mapping.Assignee] = NewPeopleValue(*u.Assignees)
+	}
+
+	for name, value := range u.Extra {
+		data[name] = value
+	}
+
+	return data, nil
+}
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Notion property types without a dedicated todo field
+const (
+	PropertyTypeNumber         = "number"
+	PropertyTypeURL            = "url"
+	PropertyTypeEmail          = "email"
+	PropertyTypePhoneNumber    = "phone_number"
+	PropertyTypeRelation       = "relation"
+	PropertyTypeFiles          = "files"
+	PropertyTypeFormula        = "formula"
+	PropertyTypeRollup         = "rollup"
+	PropertyTypeCreatedTime    = "created_time"
+	PropertyTypeLastEditedTime = "last_edited_time"
+	PropertyTypeCreatedBy      = "created_by"
+	PropertyTypeLastEditedBy   = "last_edited_by"
+	PropertyTypeUniqueID       = "unique_id"
+)
+
+// NotionRelation references a related page
+type NotionRelation struct {
+	ID string `json:"id"`
+}
+
+// NotionFile is a file attached to a files property
+type NotionFile struct {
+	Name string `json:"name"`
+}
+
+// NotionFormula is the computed value of a formula property
+type NotionFormula struct {
+	Type    string           `json:"type"`
+	String  *string          `json:"string,omitempty"`
+	Number  *float64         `json:"number,omitempty"`
+	Boolean *bool            `json:"boolean,omitempty"`
+	Date    *NotionDateValue `json:"date,omitempty"`
+}
+
+// NotionRollup is the computed value of a rollup property
+type NotionRollup struct {
+	Type   string                `json:"type"`
+	Number *float64              `json:"number,omitempty"`
+	Date   *NotionDateValue      `json:"date,omitempty"`
+	Array  []NotionPropertyValue `json:"array,omitempty"`
+}
+
+// NotionUniqueID is the value of an auto-incrementing ID property
+type NotionUniqueID struct {
+	Prefix *string  `json:"prefix"`
+	Number *float64 `json:"number"`
+}
+
+// Value decodes the property to a plain value: a string, float64, bool or
+// []string, or nil when unset. Dates are ISO 8601, with ranges written as
+// start/end; people are names, relations page IDs and files file names.
+func (v NotionPropertyValue) Value() interface{} {
+	switch v.Type {
+	case PropertyTypeTitle, PropertyTypeRichText:
+		return v.PlainText()
+	case PropertyTypeSelect, PropertyTypeStatus:
+		if option := v.Option(); option != nil {
+			return option.Name
+		}
+	case PropertyTypeMultiSelect:
+		names := []string{}
+		for _, option := range v.MultiSelect {
+			names = append(names, option.Name)
+		}
+		return names
+	case PropertyTypeDate:
+		return dateText(v.Date)
+	case PropertyTypeCheckbox:
+		return v.Checkbox
+	case PropertyTypePeople:
+		names := []string{}
+		for _, user := range v.People {
+			names = append(names, user.DisplayName())
+		}
+		return names
+	case PropertyTypeNumber:
+		if v.Number != nil {
+			return *v.Number
+		}
+	case PropertyTypeURL:
+		return stringValue(v.URL)
+	case PropertyTypeEmail:
+		return stringValue(v.Email)
+	case PropertyTypePhoneNumber:
+		return stringValue(v.PhoneNumber)
+	case PropertyTypeRelation:
+		ids := []string{}
+		for _, relation := range v.Relation {
+			ids = append(ids, relation.ID)
+		}
+		return ids
+	case PropertyTypeFiles:
+		names := []string{}
+		for _, file := range v.Files {
+			names = append(names, file.Name)
+		}
+		return names
+	case PropertyTypeFormula:
+		return v.Formula.value()
+	case PropertyTypeRollup:
+		return v.Rollup.value()
+	case PropertyTypeCreatedTime:
+		return v.CreatedTime
+	case PropertyTypeLastEditedTime:
+		return v.LastEditedTime
+	case PropertyTypeCreatedBy:
+		if v.CreatedBy != nil {
+			return v.CreatedBy.DisplayName()
+		}
+	case PropertyTypeLastEditedBy:
+		if v.LastEditedBy != nil {
+			return v.LastEditedBy.DisplayName()
+		}
+	case PropertyTypeUniqueID:
+		if v.UniqueID != nil && v.UniqueID.Number != nil {
+			number := strconv.FormatFloat(*v.UniqueID.Number, 'f', -1, 64)
+			if v.UniqueID.Prefix != nil && *v.UniqueID.Prefix != "" {
+				return *v.UniqueID.Prefix + "-" + number
+			}
+			return number
+		}
+	}
+	return nil
+}
+
+func (f *NotionFormula) value() interface{} {
+	if f == nil {
+		return nil
+	}
+	switch {
+	case f.String != nil:
+		return *f.String
+	case f.Number != nil:
+		return *f.Number
+	case f.Boolean != nil:
+		return *f.Boolean
+	case f.Date != nil:
+		return dateText(f.Date)
+	}
+	return nil
+}
+
+// value returns the number or date of a rollup, or its items as text
+func (r *NotionRollup) value() interface{} {
+	if r == nil {
+		return nil
+	}
+	switch {
+	case r.Number != nil:
+		return *r.Number
+	case r.Date != nil:
+		return dateText(r.Date)
+	case r.Type == "array":
+		items := []string{}
+		for _, item := range r.Array {
+			switch value := item.Value().(type) {
+			case nil:
+			case []string:
+				items = append(items, value...)
+			case float64:
+				items = append(items, strconv.FormatFloat(value, 'f', -1, 64))
+			default:
+				items = append(items, fmt.Sprint(value))
+			}
+		}
+		return items
+	}
+	return nil
+}
+
+// dateText returns the start of a date, or start/end for a range
+func dateText(date *NotionDateValue) interface{} {
+	if date == nil || date.Start == nil {
+		return nil
+	}
+	if date.End != nil && *date.End != "" {
+		return *date.Start + "/" + *date.End
+	}
+	return *date.Start
+}
+
+func stringValue(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPropertyValue(t *testing.T) {
+	tests := []struct {
+		name string
+		// value is the read-shaped property as returned by Notion
+		value string
+		want  interface{}
+	}{
+		{name: "title", value: `{"type":"title","title":[{"plain_text":"Buy "},{"plain_text":"milk"}]}`, want: "Buy milk"},
+		{name: "rich text", value: `{"type":"rich_text","rich_text":[]}`, want: ""},
+		{name: "select", value: `{"type":"select","select":{"name":"High"}}`, want: "High"},
+		{name: "empty select", value: `{"type":"select","select":null}`, want: nil},
+		{name: "status", value: `{"type":"status","status":{"name":"Done"}}`, want: "Done"},
+		{name: "multi select", value: `{"type":"multi_select","multi_select":[{"name":"home"},{"name":"work"}]}`, want: []string{"home", "work"}},
+		{name: "date", value: `{"type":"date","date":{"start":"2025-06-10"}}`, want: "2025-06-10"},
+		{name: "date range", value: `{"type":"date","date":{"start":"2025-06-10","end":"2025-06-12"}}`, want: "2025-06-10/2025-06-12"},
+		{name: "empty date", value: `{"type":"date","date":null}`, want: nil},
+		{name: "checkbox", value: `{"type":"checkbox","checkbox":true}`, want: true},
+		{name: "people", value: `{"type":"people","people":[{"id":"u1","name":"Ada"},{"id":"u2","person":{"email":"g@example.com"}}]}`, want: []string{"Ada", "g@example.com"}},
+		{name: "number", value: `{"type":"number","number":2.5}`, want: 2.5},
+		{name: "empty number", value: `{"type":"number","number":null}`, want: nil},
+		{name: "url", value: `{"type":"url","url":"https://example.com"}`, want: "https://example.com"},
+		{name: "empty email", value: `{"type":"email","email":null}`, want: nil},
+		{name: "phone number", value: `{"type":"phone_number","phone_number":"+49 30 1234"}`, want: "+49 30 1234"},
+		{name: "relation", value: `{"type":"relation","relation":[{"id":"p1"},{"id":"p2"}]}`, want: []string{"p1", "p2"}},
+		{name: "files", value: `{"type":"files","files":[{"name":"plan.pdf"}]}`, want: []string{"plan.pdf"}},
+		{name: "string formula", value: `{"type":"formula","formula":{"type":"string","string":"late"}}`, want: "late"},
+		{name: "date formula", value: `{"type":"formula","formula":{"type":"date","date":{"start":"2025-06-10"}}}`, want: "2025-06-10"},
+		{name: "number rollup", value: `{"type":"rollup","rollup":{"type":"number","number":3}}`, want: 3.0},
+		{
+			name:  "array rollup",
+			value: `{"type":"rollup","rollup":{"type":"array","array":[{"type":"title","title":[{"plain_text":"Spec"}]},{"type":"number","number":2},{"type":"multi_select","multi_select":[{"name":"a"}]}]}}`,
+			want:  []string{"Spec", "2", "a"},
+		},
+		{name: "created time", value: `{"type":"created_time","created_time":"2025-06-01T08:00:00.000Z"}`, want: "2025-06-01T08:00:00.000Z"},
+		{name: "last edited by", value: `{"type":"last_edited_by","last_edited_by":{"id":"u1","name":"Ada"}}`, want: "Ada"},
+		{name: "unique ID", value: `{"type":"unique_id","unique_id":{"prefix":"TASK","number":42}}`, want: "TASK-42"},
+		{name: "unique ID without prefix", value: `{"type":"unique_id","unique_id":{"prefix":null,"number":7}}`, want: "7"},
+		{name: "unsupported", value: `{"type":"button","button":{}}`, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value NotionPropertyValue
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			if got := value.Value(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Value() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// StatusOption is a status of the database with its Notion color and group
+type StatusOption struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Group string `json:"group"`
+}
+
+// StatusCache is the locally cached list of statuses of a database
+type StatusCache struct {
+	DatabaseID string         `json:"databaseId"`
+	FetchedAt  time.Time      `json:"fetchedAt"`
+	Statuses   []StatusOption `json:"statuses"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// SubTask is a to_do block in the body of a todo page
+type SubTask struct {
+	ID      string `json:"id"`
+	Text    string `json:"text"`
+	Checked bool   `json:"checked"`
+}
+
+// SubTasksOf returns the top-level to_do blocks of page content in order
+func SubTasksOf(blocks []NotionBlock) []SubTask {
+	var subTasks []SubTask
+	for _, block := range blocks {
+		if block.Type != BlockTypeToDo || block.ToDo == nil {
+			continue
+		}
+		subTasks = append(subTasks, SubTask{
+			ID:      block.ID,
+			Text:    block.ToDo.PlainText(),
+			Checked: block.ToDo.Checked,
+		})
+	}
+	return subTasks
+}
+
+// SubTaskProgress returns how many sub-tasks are checked out of the total
+func SubTaskProgress(subTasks []SubTask) (done, total int) {
+	for _, subTask := range subTasks {
+		if subTask.Checked {
+			done++
+		}
+	}
+	return done, len(subTasks)
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sort keys accepted by TodoSort
+const (
+	SortKeyDue     = "due"
+	SortKeyCreated = "created"
+	SortKeyEdited  = "edited"
+	SortKeyTitle   = "title"
+)
+
+// SortKeys lists the supported sort keys
+var SortKeys = []string{SortKeyDue, SortKeyCreated, SortKeyEdited, SortKeyTitle}
+
+// TodoSort orders todos by a sort key
+type TodoSort struct {
+	Key        string `json:"key"`
+	Descending bool   `json:"descending,omitempty"`
+}
+
+// String returns the sort in the key[:desc] form accepted by ParseTodoSort
+func (s TodoSort) String() string {
+	if s.Descending {
+		return s.Key + ":desc"
+	}
+	return s.Key
+}
+
+// ParseTodoSort parses a sort written as key, key:asc or key:desc
+func ParseTodoSort(spec string) (TodoSort, error) {
+	key, direction, _ := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), ":")
+	sort := TodoSort{Key: key}
+	switch direction {
+	case "", "asc":
+	case "desc":
+		sort.Descending = true
+	default:
+		return sort, fmt.Errorf("invalid sort direction %q in %q, use asc or desc", direction, spec)
+	}
+	for _, known := range SortKeys {
+		if key == known {
+			return sort, nil
+		}
+	}
+	return sort, fmt.Errorf("invalid sort key %q, use one of: %s", key, strings.Join(SortKeys, ", "))
+}
+
+// TodoFilter selects and orders todos. Zero values do not filter. Dates are
+// YYYY-MM-DD; CreatedSince and EditedSince may also be ISO 8601 date-times.
+type TodoFilter struct {
+	// Statuses matches todos with any of the statuses
+	Statuses      []string `json:"statuses,omitempty"`
+	TitleContains string   `json:"titleContains,omitempty"`
+	DueBefore     string   `json:"dueBefore,omitempty"`
+	DueAfter      string   `json:"dueAfter,omitempty"`
+	DueOn         string   `json:"dueOn,omitempty"`
+	Overdue       bool     `json:"overdue,omitempty"`
+	NoDueDate     bool     `json:"noDueDate,omitempty"`
+	CreatedSince  string   `json:"createdSince,omitempty"`
+	EditedSince   string   `json:"editedSince,omitempty"`
+	// Priorities matches todos with any of the priorities
+	Priorities []string `json:"priorities,omitempty"`
+	// Tags matches todos having every tag
+	Tags []string `json:"tags,omitempty"`
+	// Assignees matches todos assigned to any of the users, given by name,
+	// email or ID
+	Assignees []string   `json:"assignees,omitempty"`
+	Sorts     []TodoSort `json:"sorts,omitempty"`
+}
+
+// HasDueFilter reports whether the filter constrains the due date
+func (f TodoFilter) HasDueFilter() bool {
+	return f.DueBefore != "" || f.DueAfter != "" || f.DueOn != "" || f.Overdue || f.NoDueDate
+}
+
+// Validate reports contradictory conditions
+func (f TodoFilter) Validate() error {
+	if f.NoDueDate && (f.DueBefore != "" || f.DueAfter != "" || f.DueOn != "" || f.Overdue) {
+		return errors.New("no due date cannot be combined with other due date filters")
+	}
+	if f.DueOn != "" && (f.DueBefore != "" || f.DueAfter != "") {
+		return errors.New("due on cannot be combined with due before or after")
+	}
+	if f.DueBefore != "" && f.DueAfter != "" && f.DueBefore <= f.DueAfter {
+		return fmt.Errorf("due before %s must be later than due after %s", f.DueBefore, f.DueAfter)
+	}
+	for _, sort := range f.Sorts {
+		if _, err := ParseTodoSort(sort.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String describes the filter for display, empty when nothing is filtered
+func (f TodoFilter) String() string {
+	var parts []string
+	if len(f.Statuses) > 0 {
+		parts = append(parts, "status: "+strings.Join(f.Statuses, " or "))
+	}
+	if f.TitleContains != "" {
+		parts = append(parts, fmt.Sprintf("title: %q", f.TitleContains))
+	}
+	if len(f.Priorities) > 0 {
+		parts = append(parts, "priority: "+strings.Join(f.Priorities, " or "))
+	}
+	if len(f.Tags) > 0 {
+		parts = append(parts, "tags: "+strings.Join(f.Tags, " and "))
+	}
+	if len(f.Assignees) > 0 {
+		parts = append(parts, "assignee: "+strings.Join(f.Assignees, " or "))
+	}
+	if f.Overdue {
+		parts = append(parts, "overdue")
+	}
+	if f.NoDueDate {
+		parts = append(parts, "no due date")
+	}
+	if f.DueOn != "" {
+		parts = append(parts, "due "+f.DueOn)
+	}
+	if f.DueAfter != "" {
+		parts = append(parts, "due after "+f.DueAfter)
+	}
+	if f.DueBefore != "" {
+		parts = append(parts, "due before "+f.DueBefore)
+	}
+	if f.CreatedSince != "" {
+		parts = append(parts, "created since "+f.CreatedSince)
+	}
+	if f.EditedSince != "" {
+		parts = append(parts, "edited since "+f.EditedSince)
+	}
+	if len(f.Sorts) > 0 {
+		sorts := make([]string, len(f.Sorts))
+		for i, sort := range f.Sorts {
+			sorts[i] = sort.String()
+		}
+		parts = append(parts, "sorted by "+strings.Join(sorts, ", "))
+	}
+	return strings.Join(parts, " • ")
+}
@@ -1,11 +1,25 @@
 package models
 
-import (
-	"github.com/caffeines/notion-todo/consts"
-)
+import "errors"
 
 type Text struct {
 	Content string `json:"content"`
+	Link    *Link  `json:"link,omitempty"`
+}
+
+// Link is the target of linked text
+type Link struct {
+	URL string `json:"url"`
+}
+
+// Annotations are the formatting of rich text
+type Annotations struct {
+	Bold          bool   `json:"bold,omitempty"`
+	Italic        bool   `json:"italic,omitempty"`
+	Strikethrough bool   `json:"strikethrough,omitempty"`
+	Underline     bool   `json:"underline,omitempty"`
+	Code          bool   `json:"code,omitempty"`
+	Color         string `json:"color,omitempty"`
 }
 
 type TextTitle struct {
@@ -18,72 +32,139 @@ type Title struct {
 type Select struct {
 	Name string `json:"name"`
 }
-type Status struct {
-	Select Select `json:"select"`
-}
 
+// DateValue is a write-shaped date: a date or date-time, an optional end for
+// ranges, and an optional IANA time zone for date-times without offset
 type DateValue struct {
-	Start *string `json:"start,omitempty"`
+	Start    *string `json:"start,omitempty"`
+	End      *string `json:"end,omitempty"`
+	TimeZone *string `json:"time_zone,omitempty"`
 }
 
 type Date struct {
 	Value DateValue `json:"date"`
 }
 
-type ItemData struct {
-	ItemName   Title  `json:"Title"`
-	ItemStatus Status `json:"Status"`
-	ItemDate   *Date  `json:"Due Date,omitempty"`
-}
+// ItemData holds write-shaped property values keyed by property name
+type ItemData map[string]interface{}
 
-type Properties struct {
-	Item ItemData `json:"properties"`
+// NewTodo describes a todo to create. Optional fields are left empty.
+type NewTodo struct {
+	Title    string
+	Due      *DateValue
+	Priority string
+	Tags     []string
+	// Assignees are users by name, email or ID; the Notion client resolves
+	// them to IDs
+	Assignees []string
+	// Extra holds further write-shaped values keyed by property name,
+	// applied after the fields above
+	Extra ItemData
 }
 
-// NewProperties returns a new Properties
-func NewProperties(title, date string) ItemData {
+// NewProperties returns the properties of a new todo for the database mapping
+func NewProperties(mapping PropertyMapping, todo NewTodo) (ItemData, error) {
 	data := ItemData{
-		ItemName: Title{
+		mapping.Title: Title{
 			Titles: []TextTitle{
 				{
 					Text: Text{
-						Content: title,
+						Content: todo.Title,
 					},
 				},
 			},
 		},
-		ItemStatus: Status{
-			Select: Select{
-				Name: consts.StatusTodo,
-			},
-		},
-		ItemDate: nil,
 	}
 
-	if date != "" {
-		data.ItemDate = &Date{
-			Value: DateValue{
-				Start: &date,
-			},
+	if mapping.DefaultStatus != "" {
+		data[mapping.Status] = NewStatusValue(mapping, mapping.DefaultStatus)
+	}
+
+	if todo.Due != nil && mapping.DueDate != "" {
+		data[mapping.DueDate] = &Date{
+			Value: *todo.Due,
+		}
+	}
+
+	if todo.Priority != "" {
+		priority, err := mapping.PriorityOption(todo.Priority)
+		if err != nil {
+			return nil, err
 		}
+		data[mapping.Priority] = NewSelectValue(priority)
+	}
+	if len(todo.Tags) > 0 {
+		if mapping.Tags == "" {
+			return nil, errors.New("the database has no tags property, run 'todo config --refresh' if one was added")
+		}
+		data[mapping.Tags] = NewMultiSelectValue(todo.Tags)
+	}
+	if len(todo.Assignees) > 0 {
+		if mapping.Assignee == "" {
+			return nil, errors.New("the database has no assignee property, run 'todo config --refresh' if one was added")
+		}
+		data[mapping.Assignee] = NewPeopleValue(todo.Assignees)
+	}
+	for name, value := range todo.Extra {
+		data[name] = value
+	}
+	return data, nil
+}
+
+// NewSelectValue returns the write-shaped value of a select property. An
+// empty name clears it.
+func NewSelectValue(name string) map[string]interface{} {
+	if name == "" {
+		return map[string]interface{}{PropertyTypeSelect: nil}
+	}
+	return map[string]interface{}{PropertyTypeSelect: Select{Name: name}}
+}
+
+// NewMultiSelectValue returns the write-shaped value of a multi_select
+// property, replacing its options with names
+func NewMultiSelectValue(names []string) map[string]interface{} {
+	options := []Select{}
+	for _, name := range names {
+		options = append(options, Select{Name: name})
+	}
+	return map[string]interface{}{PropertyTypeMultiSelect: options}
+}
+
+// NewPeopleValue returns the write-shaped value of a people property,
+// replacing its users with the given IDs
+func NewPeopleValue(userIDs []string) map[string]interface{} {
+	people := []UserRef{}
+	for _, id := range userIDs {
+		people = append(people, UserRef{Object: "user", ID: id})
+	}
+	return map[string]interface{}{PropertyTypePeople: people}
+}
+
+// NewStatusValue returns the write-shaped value of the status property.
+// Checkbox properties are checked for the Done status.
+func NewStatusValue(mapping PropertyMapping, status string) map[string]interface{} {
+	if mapping.StatusType == PropertyTypeCheckbox {
+		return map[string]interface{}{
+			PropertyTypeCheckbox: mapping.StatusGroup(status) == StatusGroupComplete,
+		}
+	}
+	return map[string]interface{}{
+		mapping.StatusType: Select{
+			Name: status,
+		},
 	}
-	return data
 }
 
 // Response models for Notion query API
 type NotionTextContent struct {
 	Type string `json:"type"`
 	Text struct {
-		Content string  `json:"content"`
-		Link    *string `json:"link"`
+		Content string `json:"content"`
+		Link    *Link  `json:"link"`
 	} `json:"text"`
-	PlainText string `json:"plain_text"`
-}
-
-type NotionTitleProperty struct {
-	ID    string              `json:"id"`
-	Type  string              `json:"type"`
-	Title []NotionTextContent `json:"title"`
+	Annotations Annotations `json:"annotations"`
+	PlainText   string      `json:"plain_text"`
+	Href        *string     `json:"href"`
 }
 
 type NotionSelectOption struct {
@@ -92,37 +173,69 @@ type NotionSelectOption struct {
 	Color string `json:"color"`
 }
 
-type NotionSelectProperty struct {
-	ID     string              `json:"id"`
-	Type   string              `json:"type"`
-	Select *NotionSelectOption `json:"select"`
-}
-
 type NotionDateValue struct {
 	Start    *string `json:"start"`
 	End      *string `json:"end"`
 	TimeZone *string `json:"time_zone"`
 }
 
-type NotionDateProperty struct {
-	ID   string           `json:"id"`
-	Type string           `json:"type"`
-	Date *NotionDateValue `json:"date"`
+// NotionPropertyValue is a page property value of any supported type
+type NotionPropertyValue struct {
+	ID             string               `json:"id"`
+	Type           string               `json:"type"`
+	Title          []NotionTextContent  `json:"title,omitempty"`
+	RichText       []NotionTextContent  `json:"rich_text,omitempty"`
+	Select         *NotionSelectOption  `json:"select,omitempty"`
+	Status         *NotionSelectOption  `json:"status,omitempty"`
+	Date           *NotionDateValue     `json:"date,omitempty"`
+	Checkbox       bool                 `json:"checkbox,omitempty"`
+	MultiSelect    []NotionSelectOption `json:"multi_select,omitempty"`
+	People         []NotionUser         `json:"people,omitempty"`
+	Number         *float64             `json:"number,omitempty"`
+	URL            *string              `json:"url,omitempty"`
+	Email          *string              `json:"email,omitempty"`
+	PhoneNumber    *string              `json:"phone_number,omitempty"`
+	Relation       []NotionRelation     `json:"relation,omitempty"`
+	Files          []NotionFile         `json:"files,omitempty"`
+	Formula        *NotionFormula       `json:"formula,omitempty"`
+	Rollup         *NotionRollup        `json:"rollup,omitempty"`
+	CreatedTime    string               `json:"created_time,omitempty"`
+	LastEditedTime string               `json:"last_edited_time,omitempty"`
+	CreatedBy      *NotionUser          `json:"created_by,omitempty"`
+	LastEditedBy   *NotionUser          `json:"last_edited_by,omitempty"`
+	UniqueID       *NotionUniqueID      `json:"unique_id,omitempty"`
 }
 
-type NotionPageProperties struct {
-	Title   NotionTitleProperty  `json:"Title"`
-	Status  NotionSelectProperty `json:"Status"`
-	DueDate NotionDateProperty   `json:"Due Date"`
+// PlainText returns the text of a title or rich text value
+func (v NotionPropertyValue) PlainText() string {
+	parts := v.Title
+	if v.Type == PropertyTypeRichText {
+		parts = v.RichText
+	}
+	text := ""
+	for _, part := range parts {
+		text += part.PlainText
+	}
+	return text
+}
+
+// Option returns the selected option of a select or status value
+func (v NotionPropertyValue) Option() *NotionSelectOption {
+	if v.Type == PropertyTypeStatus {
+		return v.Status
+	}
+	return v.Select
 }
 
 type NotionPage struct {
-	Object         string               `json:"object"`
-	ID             string               `json:"id"`
-	CreatedTime    string               `json:"created_time"`
-	LastEditedTime string               `json:"last_edited_time"`
-	Properties     NotionPageProperties `json:"properties"`
-	URL            string               `json:"url"`
+	Object         string                         `json:"object"`
+	ID             string                         `json:"id"`
+	CreatedTime    string                         `json:"created_time"`
+	LastEditedTime string                         `json:"last_edited_time"`
+	Properties     map[string]NotionPropertyValue `json:"properties"`
+	URL            string                         `json:"url"`
+	Archived       bool                           `json:"archived"`
+	Parent         Parent                         `json:"parent"`
 }
 
 type NotionQueryResponse struct {
@@ -134,33 +247,83 @@ type NotionQueryResponse struct {
 
 // TodoItem represents a simplified todo item from Notion
 type TodoItem struct {
-	ID      string  `json:"id"`
-	Title   string  `json:"title"`
-	Status  string  `json:"status"`
-	DueDate *string `json:"due_date"`
-	URL     string  `json:"url"`
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	// StatusGroup is To-do, In progress or Complete
+	StatusGroup string  `json:"status_group"`
+	DueDate     *string `json:"due_date"`
+	// DueDateEnd is the end of a date range
+	DueDateEnd *string `json:"due_date_end"`
+	// DueTimeZone is the IANA zone of due date-times written without offset
+	DueTimeZone *string `json:"due_time_zone"`
+	Priority    *string `json:"priority"`
+	// PriorityColor is the Notion color of the priority option
+	PriorityColor  string   `json:"-"`
+	Tags           []string `json:"tags"`
+	Assignees      []string `json:"assignees"`
+	URL            string   `json:"url"`
+	CreatedTime    string   `json:"created_time"`
+	LastEditedTime string   `json:"last_edited_time"`
+	// Properties holds the decoded value of every database property, keyed
+	// by property name
+	Properties map[string]interface{} `json:"properties"`
+	// Source is the alias of the database of the todo when listing several
+	Source string `json:"source,omitempty"`
 }
 
-// Convert NotionPage to TodoItem
-func (p *NotionPage) ToTodoItem() TodoItem {
+// Convert NotionPage to TodoItem using the database property mapping
+func (p *NotionPage) ToTodoItem(mapping PropertyMapping) TodoItem {
 	item := TodoItem{
-		ID:  p.ID,
-		URL: p.URL,
+		ID:             p.ID,
+		URL:            p.URL,
+		CreatedTime:    p.CreatedTime,
+		LastEditedTime: p.LastEditedTime,
+		Properties:     map[string]interface{}{},
+	}
+	for name, value := range p.Properties {
+		item.Properties[name] = value.Value()
 	}
 
 	// Extract title
-	if len(p.Properties.Title.Title) > 0 {
-		item.Title = p.Properties.Title.Title[0].PlainText
+	if title, ok := p.Properties[mapping.Title]; ok {
+		item.Title = title.PlainText()
 	}
 
 	// Extract status
-	if p.Properties.Status.Select != nil {
-		item.Status = p.Properties.Status.Select.Name
+	if status, ok := p.Properties[mapping.Status]; ok {
+		if status.Type == PropertyTypeCheckbox {
+			item.Status = CheckboxStatusTodo
+			if status.Checkbox {
+				item.Status = CheckboxStatusDone
+			}
+		} else if status.Option() != nil {
+			item.Status = status.Option().Name
+		}
 	}
+	item.StatusGroup = mapping.StatusGroup(item.Status)
 
 	// Extract due date
-	if p.Properties.DueDate.Date != nil && p.Properties.DueDate.Date.Start != nil {
-		item.DueDate = p.Properties.DueDate.Date.Start
+	if dueDate, ok := p.Properties[mapping.DueDate]; ok && dueDate.Date != nil && dueDate.Date.Start != nil {
+		item.DueDate = dueDate.Date.Start
+		item.DueDateEnd = dueDate.Date.End
+		item.DueTimeZone = dueDate.Date.TimeZone
+	}
+
+	// Extract the optional priority, tags and assignees
+	if priority, ok := p.Properties[mapping.Priority]; ok && mapping.Priority != "" && priority.Select != nil {
+		item.Priority = &priority.Select.Name
+		item.PriorityColor = priority.Select.Color
+	}
+	if tags, ok := p.Properties[mapping.Tags]; ok && mapping.Tags != "" {
+		for _, tag := range tags.MultiSelect {
+			item.Tags = append(item.Tags, tag.Name)
+		}
+	}
+	if people, ok := p.Properties[mapping.Assignee]; ok && mapping.Assignee != "" {
+		for _, user := range people.People {
+			item.Assignees = append(item.Assignees, user.DisplayName())
+		}
 	}
 
 	return item
@@ -172,5 +335,15 @@ type QueryFilter struct {
 }
 
 type QueryRequest struct {
-	Filter *QueryFilter `json:"filter,omitempty"`
+	Filter      *QueryFilter             `json:"filter,omitempty"`
+	Sorts       []map[string]interface{} `json:"sorts,omitempty"`
+	StartCursor string                   `json:"start_cursor,omitempty"`
+	PageSize    int                      `json:"page_size,omitempty"`
+}
+
+// TodoPage is a single page of results from a paginated query
+type TodoPage struct {
+	Todos      []TodoItem
+	NextCursor string
+	HasMore    bool
 }
@@ -0,0 +1,47 @@
+package models
+
+// Notion user types
+const (
+	UserTypePerson = "person"
+	UserTypeBot    = "bot"
+)
+
+// NotionPerson holds the details of a user who is a person
+type NotionPerson struct {
+	Email string `json:"email"`
+}
+
+// NotionUser is a workspace member or bot, as listed by the users API and
+// referenced by people properties
+type NotionUser struct {
+	Object string        `json:"object"`
+	ID     string        `json:"id"`
+	Type   string        `json:"type,omitempty"`
+	Name   string        `json:"name,omitempty"`
+	Person *NotionPerson `json:"person,omitempty"`
+}
+
+// DisplayName returns the name of the user, falling back to the email
+// address and then the ID
+func (u NotionUser) DisplayName() string {
+	if u.Name != "" {
+		return u.Name
+	}
+	if u.Person != nil && u.Person.Email != "" {
+		return u.Person.Email
+	}
+	return u.ID
+}
+
+type NotionUserList struct {
+	Object     string       `json:"object"`
+	Results    []NotionUser `json:"results"`
+	NextCursor *string      `json:"next_cursor"`
+	HasMore    bool         `json:"has_more"`
+}
+
+// UserRef is the write-shaped reference to a user in a people property
+type UserRef struct {
+	Object string `json:"object"`
+	ID     string `json:"id"`
+}
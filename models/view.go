@@ -0,0 +1,10 @@
+package models
+
+// View is a named, saved filter with its sort order and table columns
+type View struct {
+	Name string `json:"name"`
+	// Filter keeps dates as entered, e.g. "today", so views stay relative
+	Filter TodoFilter `json:"filter"`
+	// Columns are the output fields shown in tables and CSV, empty for the default
+	Columns []string `json:"columns,omitempty"`
+}
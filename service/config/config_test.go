@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/secrets"
+)
+
+// newTestCredential returns a credential service reading the config file
+// stored, written to a temporary config directory unless empty. The
+// environment variables of every key are cleared, secret stores are opened
+// afresh and no keyring is available.
+func newTestCredential(t *testing.T, stored string) *credentialImpl {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("XDG_DATA_HOME", dir)
+	for _, key := range Keys {
+		t.Setenv(key.Env, "")
+	}
+	t.Setenv(consts.EnvProfile, "")
+	t.Setenv(consts.EnvPassphrase, "")
+
+	available, run, output := keyringAvailable, runKeyringTool, warnings
+	t.Cleanup(func() {
+		keyringAvailable, runKeyringTool, warnings = available, run, output
+		stores = map[string]secrets.Store{}
+		warnPlain = sync.Once{}
+	})
+	keyringAvailable = func() bool { return false }
+	stores = map[string]secrets.Store{}
+	warnPlain = sync.Once{}
+
+	c := &credentialImpl{file: files.NewFileService(files.ConfigDir, consts.ConfigFileName)}
+	if stored != "" {
+		if err := c.file.SaveFile([]byte(stored)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+// storedConfig returns the config file as written
+func storedConfig(t *testing.T, c *credentialImpl) *models.Config {
+	t.Helper()
+	data, err := c.file.ReadFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored models.Config
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+	return &stored
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -3,6 +3,56 @@ package config
 import "github.com/caffeines/notion-todo/models"
 
 type Credential interface {
+	// SetConfig saves the token and database ID of the profile in use,
+	// creating the profile if needed
 	SetConfig(token string, databaseID string) error
+	// GetConfig returns the settings of the profile in use
 	GetConfig() (*models.Config, error)
+	// GetSettings returns the settings of the profile in use without
+	// reading the token, so the secret store is not opened
+	GetSettings() (*models.Config, error)
+	// UpdateConfig applies update to the settings of the profile in use and
+	// saves the result
+	UpdateConfig(update func(cfg *models.Config) error) error
+	// SelectProfile chooses the profile for this run, taking precedence over
+	// the TODO_PROFILE environment variable and the active profile. An empty
+	// name keeps them.
+	SelectProfile(name string)
+	// Profile returns the name of the profile in use
+	Profile() (string, error)
+	// ListProfiles returns the stored profile names, default first
+	ListProfiles() ([]string, error)
+	// UseProfile makes a stored profile the active one
+	UseProfile(name string) error
+	// RemoveProfile deletes a named profile
+	RemoveProfile(name string) error
+	// SelectDatabase chooses the database alias of the profile for this
+	// run, taking precedence over its default database. An empty alias
+	// keeps the default.
+	SelectDatabase(alias string)
+	// Database returns the alias of the database in use
+	Database() (string, error)
+	// Databases returns the database IDs of the profile in use by alias
+	Databases() (map[string]string, error)
+	// AddDatabase registers a database of the profile in use under alias
+	AddDatabase(alias string, databaseID string) error
+	// UseDatabase makes an alias the default database of the profile
+	UseDatabase(alias string) error
+	// RemoveDatabase deletes a database alias of the profile
+	RemoveDatabase(alias string) error
+	// Set validates and saves a config key of the profile and database in
+	// use, creating the profile if needed
+	Set(key string, value string) error
+	// SetOverride gives a config key for this run only, taking precedence
+	// over its environment variable and the config file
+	SetOverride(key string, value string) error
+	// SaveProperties saves the property mapping of every stored database of
+	// the profile with the given ID, and keeps it for this run
+	SaveProperties(databaseID string, mapping *models.PropertyMapping) error
+	// SetSecretStore moves the tokens of every profile to backend: keyring,
+	// file or plain
+	SetSecretStore(backend string) error
+	// ForDatabase returns a service for the same profile bound to alias,
+	// to work with several databases at once
+	ForDatabase(alias string) Credential
 }
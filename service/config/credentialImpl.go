@@ -1,18 +1,35 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"os"
+	"sync"
+
 	"github.com/caffeines/notion-todo/models"
 	"github.com/caffeines/notion-todo/service/files"
 )
 
 type credentialImpl struct {
 	file files.File
+	// profile is the profile selected for this run, empty when not selected
+	profile string
+	// database is the database alias selected for this run, empty when not
+	// selected
+	database string
+	// bound is set for services of ForDatabase, which use their database
+	// even when another one is given by flag or environment
+	bound bool
+	// overrides are the config keys given by flag for this run
+	overrides map[string]string
 }
 
 var (
 	credential Credential
+	// configMu serializes changes to the config file, which services bound
+	// to different databases may make concurrently
+	configMu sync.RWMutex
 )
 
 func NewCredentialSvc(file files.File) Credential {
@@ -34,20 +51,156 @@ func (c *credentialImpl) SetConfig(token string, databaseID string) error {
 	if c.file == nil {
 		return errors.New("file storage not initialized")
 	}
+	configMu.Lock()
+	defer configMu.Unlock()
 
-	cfg := models.Config{
-		Token:      token,
-		DatabaseID: databaseID,
+	// Keep any other settings already stored in the config file
+	stored := &models.Config{}
+	if existing, err := c.readConfig(); err == nil {
+		stored = existing
+	}
+	name := c.profileName(stored)
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	cfg, err := profileConfig(stored, name)
+	if err != nil {
+		// A new profile starts out empty
+		cfg = &models.Config{}
+		*cfg = *stored
+		cfg.Profile = models.Profile{}
+	}
+	if cfg.DatabaseID != databaseID {
+		// The property mapping belongs to the previous database
+		cfg.Properties = nil
 	}
+	cfg.DatabaseID = databaseID
+	storeProfile(stored, name, cfg)
+
+	// New and plaintext configs move to the preferred secret store
+	if err := storeToken(stored, name, token); err != nil {
+		return err
+	}
+	return c.saveConfig(stored)
+}
+
+func (c *credentialImpl) UpdateConfig(update func(cfg *models.Config) error) error {
+	return c.updateProfile(func(profile *models.Config) error {
+		alias := c.databaseAlias(profile)
+		cfg, err := databaseConfig(profile, alias)
+		if err != nil {
+			return err
+		}
+		if err := update(cfg); err != nil {
+			return err
+		}
+		storeDatabase(profile, alias, cfg)
+		return nil
+	})
+}
+
+// updateProfile applies update to the settings of the profile in use, with
+// its own database in place, and saves the result
+func (c *credentialImpl) updateProfile(update func(profile *models.Config) error) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	stored, err := c.readConfig()
+	if os.IsNotExist(err) {
+		stored, err = &models.Config{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	before, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	name := c.profileName(stored)
+	profile, err := profileConfig(stored, name)
+	if err != nil {
+		return err
+	}
+	if err := update(profile); err != nil {
+		return err
+	}
+	storeProfile(stored, name, profile)
+
+	after, err := json.Marshal(stored)
+	if err != nil || bytes.Equal(before, after) {
+		return err
+	}
+	return c.file.SaveFile(after)
+}
+
+func (c *credentialImpl) saveConfig(cfg *models.Config) error {
 	data, err := json.Marshal(cfg)
 	if err != nil {
 		return err
 	}
-	err = c.file.SaveFile(data)
-	return err
+	return c.file.SaveFile(data)
 }
 
+// GetConfig returns the settings of the profile and database in use. Keys
+// given by flag take precedence over environment variables, which take
+// precedence over the config file.
 func (c *credentialImpl) GetConfig() (*models.Config, error) {
+	return c.getConfig(true)
+}
+
+func (c *credentialImpl) GetSettings() (*models.Config, error) {
+	return c.getConfig(false)
+}
+
+// getConfig returns the settings in use, reading the token from the secret
+// store only when withToken is set
+func (c *credentialImpl) getConfig(withToken bool) (*models.Config, error) {
+	configMu.RLock()
+	stored, err := c.readConfig()
+	configMu.RUnlock()
+	if os.IsNotExist(err) && c.selfConfigured() {
+		// Flags or environment variables may configure a run on their own
+		stored, err = &models.Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if withToken && hasPlainTokens(stored) {
+		migrated, err := c.migrateTokens()
+		if err != nil {
+			return nil, err
+		}
+		if migrated != nil {
+			stored = migrated
+		}
+	}
+	name := c.profileName(stored)
+	profile, err := profileConfig(stored, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, ok := c.override(KeyToken); withToken && !ok {
+		// Only open the secret store when the token is needed from it
+		if profile.Token, err = storedToken(stored, name); err != nil {
+			return nil, err
+		}
+	}
+	alias := c.databaseAlias(profile)
+	cfg, err := databaseConfig(profile, alias)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.applyOverrides(cfg, alias); err != nil {
+		return nil, err
+	}
+	if cfg.Properties == nil {
+		cfg.Properties = knownProperties(profile, cfg.DatabaseID)
+	}
+	return cfg, nil
+}
+
+// readConfig returns the config exactly as stored in the config file
+func (c *credentialImpl) readConfig() (*models.Config, error) {
 	if c.file == nil {
 		return nil, errors.New("file storage not initialized")
 	}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+)
+
+// DatabaseNotFoundError reports a database alias missing from the profile
+type DatabaseNotFoundError struct {
+	Alias string
+}
+
+func (e *DatabaseNotFoundError) Error() string {
+	return fmt.Sprintf("database %q not found", e.Alias)
+}
+
+// DatabaseConflictError reports a database ID given by environment variable
+// while another database alias is selected with --db
+type DatabaseConflictError struct {
+	// Source is the environment variable giving the database ID
+	Source string
+	Alias  string
+}
+
+func (e *DatabaseConflictError) Error() string {
+	return fmt.Sprintf("%s cannot be used together with --db %s", e.Source, e.Alias)
+}
+
+// ValidateDatabaseAlias reports aliases that are not letters, digits,
+// dashes and underscores
+func ValidateDatabaseAlias(alias string) error {
+	if alias == "" {
+		return errors.New("database alias cannot be empty")
+	}
+	if strings.Trim(strings.ToLower(alias), "abcdefghijklmnopqrstuvwxyz0123456789-_") != "" {
+		return fmt.Errorf("invalid database alias %q, use letters, digits, - and _", alias)
+	}
+	return nil
+}
+
+func (c *credentialImpl) SelectDatabase(alias string) {
+	c.database = alias
+}
+
+func (c *credentialImpl) Database() (string, error) {
+	profile, err := c.profileSettings()
+	if err != nil {
+		return "", err
+	}
+	return c.databaseAlias(profile), nil
+}
+
+func (c *credentialImpl) Databases() (map[string]string, error) {
+	profile, err := c.profileSettings()
+	if err != nil {
+		return nil, err
+	}
+	databases := map[string]string{}
+	if profile.DatabaseID != "" {
+		databases[consts.DefaultDatabase] = profile.DatabaseID
+	}
+	if databaseID, _, ok := c.override(KeyDatabaseID); ok {
+		databases[consts.DefaultDatabase] = databaseID
+	}
+	for alias, database := range profile.Databases {
+		databases[alias] = database.ID
+	}
+	return databases, nil
+}
+
+func (c *credentialImpl) AddDatabase(alias string, databaseID string) error {
+	if err := ValidateDatabaseAlias(alias); err != nil {
+		return err
+	}
+	if alias == consts.DefaultDatabase {
+		return fmt.Errorf("the %q database is set with 'todo config'", alias)
+	}
+	if databaseID == "" {
+		return errors.New("database ID cannot be empty")
+	}
+	return c.updateProfile(func(profile *models.Config) error {
+		databases := map[string]models.Database{}
+		for key, database := range profile.Databases {
+			databases[key] = database
+		}
+		database := databases[alias]
+		if database.ID != databaseID {
+			// The property mapping belongs to the previous database
+			database = models.Database{ID: databaseID}
+		}
+		databases[alias] = database
+		profile.Databases = databases
+		return nil
+	})
+}
+
+func (c *credentialImpl) UseDatabase(alias string) error {
+	return c.updateProfile(func(profile *models.Config) error {
+		if _, err := databaseConfig(profile, alias); err != nil {
+			return err
+		}
+		profile.DefaultDatabase = alias
+		if alias == consts.DefaultDatabase {
+			profile.DefaultDatabase = ""
+		}
+		return nil
+	})
+}
+
+func (c *credentialImpl) RemoveDatabase(alias string) error {
+	if alias == consts.DefaultDatabase {
+		return fmt.Errorf("the %q database cannot be removed", alias)
+	}
+	return c.updateProfile(func(profile *models.Config) error {
+		if _, ok := profile.Databases[alias]; !ok {
+			return &DatabaseNotFoundError{Alias: alias}
+		}
+		databases := map[string]models.Database{}
+		for key, database := range profile.Databases {
+			if key != alias {
+				databases[key] = database
+			}
+		}
+		profile.Databases = databases
+		if profile.DefaultDatabase == alias {
+			profile.DefaultDatabase = ""
+		}
+		return nil
+	})
+}
+
+func (c *credentialImpl) ForDatabase(alias string) Credential {
+	return &credentialImpl{
+		file:      c.file,
+		profile:   c.profile,
+		database:  alias,
+		bound:     true,
+		overrides: c.overrides,
+	}
+}
+
+// profileSettings returns the stored settings of the profile in use
+func (c *credentialImpl) profileSettings() (*models.Config, error) {
+	configMu.RLock()
+	stored, err := c.readConfig()
+	configMu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return profileConfig(stored, c.profileName(stored))
+}
+
+// databaseAlias returns the database alias in use: the selected one, then
+// the default database when its ID is given by flag or environment, then
+// the default database of the profile
+func (c *credentialImpl) databaseAlias(profile *models.Config) string {
+	if c.database != "" {
+		return c.database
+	}
+	if _, _, ok := c.override(KeyDatabaseID); ok {
+		return consts.DefaultDatabase
+	}
+	if profile.DefaultDatabase != "" {
+		return profile.DefaultDatabase
+	}
+	return consts.DefaultDatabase
+}
+
+// databaseConfig returns the settings of a profile, as returned by
+// profileConfig, with the aliased database in place of its own
+func databaseConfig(profile *models.Config, alias string) (*models.Config, error) {
+	cfg := *profile
+	if alias != consts.DefaultDatabase {
+		database, ok := profile.Databases[alias]
+		if !ok {
+			return nil, &DatabaseNotFoundError{Alias: alias}
+		}
+		cfg.DatabaseID = database.ID
+		cfg.Properties = database.Properties
+	}
+	return &cfg, nil
+}
+
+// storeDatabase writes cfg, as returned by databaseConfig, back to profile
+func storeDatabase(profile *models.Config, alias string, cfg *models.Config) {
+	if alias == consts.DefaultDatabase {
+		*profile = *cfg
+		return
+	}
+	databaseID, properties := profile.DatabaseID, profile.Properties
+	databases := map[string]models.Database{}
+	for key, database := range profile.Databases {
+		databases[key] = database
+	}
+	databases[alias] = models.Database{ID: cfg.DatabaseID, Properties: cfg.Properties}
+
+	*profile = *cfg
+	profile.DatabaseID = databaseID
+	profile.Properties = properties
+	profile.Databases = databases
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+)
+
+// databasesConfig has a mapped default database and a sprint database,
+// with a work profile holding its own
+const databasesConfig = `{
+	"secretStore": "plain",
+	"token": "secret_default",
+	"databaseId": "db-default",
+	"properties": {"title": "Name"},
+	"databases": {"sprint": {"id": "db-sprint", "properties": {"title": "Task"}}},
+	"profiles": {
+		"work": {"token": "secret_work", "databaseId": "db-work", "databases": {"ops": {"id": "db-ops"}}}
+	}
+}`
+
+func TestDatabaseSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   string
+		use       string // alias made the default with UseDatabase
+		selected  string // --db
+		want      string
+		wantTitle string
+		wantAlias string
+	}{
+		{name: "default", want: "db-default", wantTitle: "Name", wantAlias: "default"},
+		{name: "selected", selected: "sprint", want: "db-sprint", wantTitle: "Task", wantAlias: "sprint"},
+		{name: "profile default", use: "sprint", want: "db-sprint", wantTitle: "Task", wantAlias: "sprint"},
+		{name: "selected over profile default", use: "sprint", selected: "default", want: "db-default", wantTitle: "Name", wantAlias: "default"},
+		{name: "other profile", profile: "work", selected: "ops", want: "db-ops", wantAlias: "ops"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCredential(t, databasesConfig)
+			c.SelectProfile(tt.profile)
+			if tt.use != "" {
+				if err := c.UseDatabase(tt.use); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c.SelectDatabase(tt.selected)
+
+			cfg, err := c.GetConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.DatabaseID != tt.want {
+				t.Errorf("database = %q, want %q", cfg.DatabaseID, tt.want)
+			}
+			title := ""
+			if cfg.Properties != nil {
+				title = cfg.Properties.Title
+			}
+			if title != tt.wantTitle {
+				t.Errorf("title property = %q, want the mapping of %s", title, tt.want)
+			}
+			if alias, err := c.Database(); err != nil || alias != tt.wantAlias {
+				t.Errorf("Database() = %q, %v, want %q", alias, err, tt.wantAlias)
+			}
+		})
+	}
+
+	c := newTestCredential(t, databasesConfig)
+	c.SelectDatabase("ops")
+	var notFound *DatabaseNotFoundError
+	if _, err := c.GetConfig(); !errors.As(err, &notFound) || notFound.Alias != "ops" {
+		t.Errorf("alias of another profile: error = %v, want a DatabaseNotFoundError", err)
+	}
+}
+
+func TestManageDatabases(t *testing.T) {
+	c := newTestCredential(t, databasesConfig)
+
+	if err := c.AddDatabase("backlog", "db-backlog"); err != nil {
+		t.Fatal(err)
+	}
+	databases, err := c.Databases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"default": "db-default", "sprint": "db-sprint", "backlog": "db-backlog"}
+	if len(databases) != len(want) {
+		t.Errorf("Databases() = %v, want %v", databases, want)
+	}
+	for alias, id := range want {
+		if databases[alias] != id {
+			t.Errorf("Databases()[%s] = %q, want %q", alias, databases[alias], id)
+		}
+	}
+
+	// Pointing an alias at another database drops its property mapping
+	if err := c.AddDatabase("sprint", "db-sprint"); err != nil {
+		t.Fatal(err)
+	}
+	if stored := storedConfig(t, c); stored.Databases["sprint"].Properties == nil {
+		t.Error("re-adding the same database dropped its mapping")
+	}
+	if err := c.AddDatabase("sprint", "db-sprint-2"); err != nil {
+		t.Fatal(err)
+	}
+	if stored := storedConfig(t, c); stored.Databases["sprint"] != (models.Database{ID: "db-sprint-2"}) {
+		t.Errorf("sprint = %+v, want the new database without a mapping", stored.Databases["sprint"])
+	}
+
+	for _, tt := range []struct{ alias, id string }{{"default", "db"}, {"two words", "db"}, {"next", ""}} {
+		if err := c.AddDatabase(tt.alias, tt.id); err == nil {
+			t.Errorf("AddDatabase(%q, %q) succeeded", tt.alias, tt.id)
+		}
+	}
+
+	if err := c.UseDatabase("backlog"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RemoveDatabase("backlog"); err != nil {
+		t.Fatal(err)
+	}
+	stored := storedConfig(t, c)
+	if _, ok := stored.Databases["backlog"]; ok || stored.DefaultDatabase != "" {
+		t.Errorf("after removing the default database: %+v", stored.Profile)
+	}
+	var notFound *DatabaseNotFoundError
+	if err := c.UseDatabase("backlog"); !errors.As(err, &notFound) {
+		t.Errorf("using a removed database: error = %v", err)
+	}
+	if err := c.RemoveDatabase("backlog"); !errors.As(err, &notFound) {
+		t.Errorf("removing a missing database: error = %v", err)
+	}
+	if err := c.RemoveDatabase("default"); err == nil {
+		t.Error("RemoveDatabase removed the default database")
+	}
+
+	// The work profile keeps its own databases
+	c.SelectProfile("work")
+	if databases, err := c.Databases(); err != nil || len(databases) != 2 || databases["ops"] != "db-ops" {
+		t.Errorf("work databases = %v, %v", databases, err)
+	}
+}
+
+func TestForDatabase(t *testing.T) {
+	c := newTestCredential(t, databasesConfig)
+	c.SelectDatabase("sprint")
+	if err := c.SetOverride("dateFormat", "YYYY-MM-DD"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := c.ForDatabase(consts.DefaultDatabase).GetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DatabaseID != "db-default" || cfg.DateFormat != "YYYY-MM-DD" {
+		t.Errorf("ForDatabase(default) = %+v, want db-default with the flag's date format", cfg)
+	}
+	// The service it was made from keeps its database
+	if cfg, err := c.GetConfig(); err != nil || cfg.DatabaseID != "db-sprint" {
+		t.Errorf("GetConfig() = %v, %v, want db-sprint", cfg, err)
+	}
+
+	// Updates are written to the bound database
+	err = c.ForDatabase(consts.DefaultDatabase).UpdateConfig(func(cfg *models.Config) error {
+		cfg.Properties = &models.PropertyMapping{Title: "Title"}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := storedConfig(t, c)
+	if stored.Properties.Title != "Title" || stored.Databases["sprint"].Properties.Title != "Task" {
+		t.Errorf("mappings = %+v and %+v", stored.Properties, stored.Databases["sprint"].Properties)
+	}
+}
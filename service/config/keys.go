@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/utility"
+)
+
+// Names of the keys with special handling
+const (
+	KeyToken      = "token"
+	KeyDatabaseID = "databaseId"
+)
+
+// Key is a config key that can be set with 'todo config set', overridden
+// by an environment variable and, for some, by a flag
+type Key struct {
+	// Name is the key in the config file
+	Name string
+	// Env is the environment variable overriding the key
+	Env string
+	// Usage describes the value
+	Usage string
+	// set validates value and stores it in cfg
+	set func(cfg *models.Config, value string) error
+}
+
+// Keys lists the config keys in the order shown in help
+var Keys = []Key{
+	{Name: KeyToken, Env: consts.EnvToken, Usage: "Notion integration token", set: setToken},
+	{Name: KeyDatabaseID, Env: consts.EnvDatabaseID, Usage: "ID of the default Notion database", set: setDatabaseID},
+	{Name: "apiUrl", Env: consts.EnvAPIURL, Usage: "Notion API base URL, empty for the default", set: setAPIURL},
+	{Name: "requestTimeout", Env: consts.EnvRequestTimeout, Usage: "Per-request timeout in seconds, 0 for the default", set: setRequestTimeout},
+	{Name: "maxRetries", Env: consts.EnvMaxRetries, Usage: "Retries of failed requests, 0 for the default and negative to disable", set: setMaxRetries},
+	{Name: "dateFormat", Env: consts.EnvDateFormat, Usage: "Order of numeric dates: DD-MM-YYYY, MM-DD-YYYY or YYYY-MM-DD", set: setDateFormat},
+	{Name: "timeZone", Env: consts.EnvTimeZone, Usage: "IANA time zone times are entered in, empty for local time", set: setTimeZone},
+}
+
+// InvalidValueError reports a value that a config key does not accept
+type InvalidValueError struct {
+	// Source is where the value came from, such as an environment variable
+	Source string
+	Err    error
+}
+
+func (e *InvalidValueError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", e.Source, e.Err)
+}
+
+func (e *InvalidValueError) Unwrap() error {
+	return e.Err
+}
+
+// FindKey returns the key named name, ignoring case, dashes and underscores
+func FindKey(name string) (*Key, error) {
+	normalize := strings.NewReplacer("-", "", "_", "")
+	for i := range Keys {
+		if strings.EqualFold(normalize.Replace(name), Keys[i].Name) {
+			return &Keys[i], nil
+		}
+	}
+	names := make([]string, len(Keys))
+	for i, key := range Keys {
+		names[i] = key.Name
+	}
+	return nil, &InvalidValueError{Source: "config key", Err: fmt.Errorf("unknown key %q, use any of: %s", name, strings.Join(names, ", "))}
+}
+
+// Validate reports whether value is accepted by the key
+func (k *Key) Validate(value string) error {
+	return k.set(&models.Config{}, value)
+}
+
+func setToken(cfg *models.Config, value string) error {
+	if value == "" {
+		return errors.New("token cannot be empty")
+	}
+	cfg.Token = value
+	return nil
+}
+
+func setDatabaseID(cfg *models.Config, value string) error {
+	if value == "" {
+		return errors.New("database ID cannot be empty")
+	}
+	if cfg.DatabaseID != value {
+		// The property mapping belongs to the previous database
+		cfg.Properties = nil
+	}
+	cfg.DatabaseID = value
+	return nil
+}
+
+func setAPIURL(cfg *models.Config, value string) error {
+	if value != "" {
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("%q is not an absolute URL", value)
+		}
+	}
+	cfg.APIURL = value
+	return nil
+}
+
+func setRequestTimeout(cfg *models.Config, value string) error {
+	seconds, err := parseInt(value)
+	if err != nil || seconds < 0 {
+		return fmt.Errorf("%q is not a number of seconds", value)
+	}
+	cfg.RequestTimeout = seconds
+	return nil
+}
+
+func setMaxRetries(cfg *models.Config, value string) error {
+	retries, err := parseInt(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", value)
+	}
+	cfg.MaxRetries = retries
+	return nil
+}
+
+func setDateFormat(cfg *models.Config, value string) error {
+	if value == "" {
+		cfg.DateFormat = ""
+		return nil
+	}
+	locale, err := utility.ParseDateLocale(value)
+	if err != nil {
+		return err
+	}
+	cfg.DateFormat = string(locale)
+	return nil
+}
+
+func setTimeZone(cfg *models.Config, value string) error {
+	if value != "" {
+		if _, err := time.LoadLocation(value); err != nil {
+			return fmt.Errorf("unknown time zone %q, use an IANA name such as Europe/Berlin", value)
+		}
+	}
+	cfg.TimeZone = value
+	return nil
+}
+
+// parseInt parses a whole number, treating an empty value as zero
+func parseInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(strings.TrimSpace(value))
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+)
+
+// sessionProperties keeps, by database ID, the property mappings of
+// databases given by flag or environment that are not in the config file
+var sessionProperties sync.Map
+
+func (c *credentialImpl) SetOverride(name, value string) error {
+	key, err := FindKey(name)
+	if err != nil {
+		return err
+	}
+	if err := key.Validate(value); err != nil {
+		return &InvalidValueError{Source: key.Name, Err: err}
+	}
+	overrides := map[string]string{key.Name: value}
+	for name, value := range c.overrides {
+		if name != key.Name {
+			overrides[name] = value
+		}
+	}
+	c.overrides = overrides
+	return nil
+}
+
+func (c *credentialImpl) Set(name, value string) error {
+	key, err := FindKey(name)
+	if err != nil {
+		return err
+	}
+	if err := key.Validate(value); err != nil {
+		return &InvalidValueError{Source: key.Name, Err: err}
+	}
+	if err := c.ensureProfile(); err != nil {
+		return err
+	}
+	if key.Name == KeyToken {
+		configMu.Lock()
+		defer configMu.Unlock()
+		stored, err := c.readConfig()
+		if os.IsNotExist(err) {
+			stored, err = &models.Config{}, nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := storeToken(stored, c.profileName(stored), value); err != nil {
+			return err
+		}
+		return c.saveConfig(stored)
+	}
+	return c.UpdateConfig(func(cfg *models.Config) error {
+		return key.set(cfg, value)
+	})
+}
+
+func (c *credentialImpl) SaveProperties(databaseID string, mapping *models.PropertyMapping) error {
+	sessionProperties.Store(databaseID, mapping)
+	err := c.updateProfile(func(profile *models.Config) error {
+		if profile.DatabaseID == databaseID {
+			profile.Properties = mapping
+		}
+		databases := map[string]models.Database{}
+		for alias, database := range profile.Databases {
+			if database.ID == databaseID {
+				database.Properties = mapping
+			}
+			databases[alias] = database
+		}
+		if len(databases) > 0 {
+			profile.Databases = databases
+		}
+		return nil
+	})
+	var notFound *ProfileNotFoundError
+	if errors.As(err, &notFound) {
+		// A run configured only by flags or environment has no profile
+		return nil
+	}
+	return err
+}
+
+// ensureProfile creates the profile in use in the config file, along with
+// the file, if they do not exist yet
+func (c *credentialImpl) ensureProfile() error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	stored, err := c.readConfig()
+	if os.IsNotExist(err) {
+		stored, err = &models.Config{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	name := c.profileName(stored)
+	if _, err := profileConfig(stored, name); err == nil {
+		return nil
+	}
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	storeProfile(stored, name, &models.Config{})
+	return c.saveConfig(stored)
+}
+
+// override returns the value of a key given by flag, then by environment
+// variable, and where it came from
+func (c *credentialImpl) override(name string) (value, source string, ok bool) {
+	if value, ok := c.overrides[name]; ok {
+		return value, name, true
+	}
+	key, err := FindKey(name)
+	if err != nil {
+		return "", "", false
+	}
+	if value := os.Getenv(key.Env); value != "" {
+		return value, key.Env, true
+	}
+	return "", "", false
+}
+
+// applyOverrides sets the keys given by flag or environment on cfg. The
+// database ID only replaces the default database of the profile; given
+// along with another alias selected with --db it is a
+// *DatabaseConflictError.
+func (c *credentialImpl) applyOverrides(cfg *models.Config, alias string) error {
+	for _, key := range Keys {
+		value, source, ok := c.override(key.Name)
+		if !ok {
+			continue
+		}
+		if key.Name == KeyDatabaseID && alias != consts.DefaultDatabase {
+			if c.bound {
+				continue
+			}
+			return &DatabaseConflictError{Source: source, Alias: alias}
+		}
+		if err := key.set(cfg, value); err != nil {
+			return &InvalidValueError{Source: source, Err: err}
+		}
+	}
+	return nil
+}
+
+// selfConfigured reports whether flags or environment give both a token and
+// a database, so no config file is needed
+func (c *credentialImpl) selfConfigured() bool {
+	_, _, token := c.override(KeyToken)
+	_, _, database := c.override(KeyDatabaseID)
+	return token && database
+}
+
+// knownProperties returns the stored or discovered property mapping of a
+// database of the profile by ID, or nil
+func knownProperties(profile *models.Config, databaseID string) *models.PropertyMapping {
+	if profile.DatabaseID == databaseID && profile.Properties != nil {
+		return profile.Properties
+	}
+	for _, database := range profile.Databases {
+		if database.ID == databaseID && database.Properties != nil {
+			return database.Properties
+		}
+	}
+	if mapping, ok := sessionProperties.Load(databaseID); ok {
+		return mapping.(*models.PropertyMapping)
+	}
+	return nil
+}
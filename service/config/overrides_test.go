@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+)
+
+// overrideConfig sets every overridable key in the config file, with a
+// second database registered as sprint
+const overrideConfig = `{
+	"secretStore": "plain",
+	"token": "secret_file",
+	"databaseId": "db-file",
+	"databases": {"sprint": {"id": "db-sprint"}},
+	"apiUrl": "https://file.example.com",
+	"requestTimeout": 10,
+	"maxRetries": 1,
+	"dateFormat": "DD-MM-YYYY",
+	"timeZone": "Europe/Berlin"
+}`
+
+// settingValue returns the value of the config key name in cfg, as typed
+func settingValue(cfg *models.Config, name string) string {
+	switch name {
+	case KeyToken:
+		return cfg.Token
+	case KeyDatabaseID:
+		return cfg.DatabaseID
+	case "apiUrl":
+		return cfg.APIURL
+	case "requestTimeout":
+		return strconv.Itoa(cfg.RequestTimeout)
+	case "maxRetries":
+		return strconv.Itoa(cfg.MaxRetries)
+	case "dateFormat":
+		return cfg.DateFormat
+	case "timeZone":
+		return cfg.TimeZone
+	}
+	return ""
+}
+
+func TestOverridePrecedence(t *testing.T) {
+	tests := []struct {
+		key       string
+		wantFile  string
+		env       string
+		flag      string
+		invalidIn string // a value the key rejects, empty when any is accepted
+	}{
+		{key: KeyToken, wantFile: "secret_file", env: "secret_env", flag: "secret_flag"},
+		{key: KeyDatabaseID, wantFile: "db-file", env: "db-env", flag: "db-flag"},
+		{key: "apiUrl", wantFile: "https://file.example.com", env: "https://env.example.com", flag: "https://flag.example.com", invalidIn: "not a url"},
+		{key: "requestTimeout", wantFile: "10", env: "20", flag: "30", invalidIn: "-5"},
+		{key: "maxRetries", wantFile: "1", env: "-1", flag: "5", invalidIn: "many"},
+		{key: "dateFormat", wantFile: "DD-MM-YYYY", env: "MM-DD-YYYY", flag: "YYYY-MM-DD", invalidIn: "DD.MM.YY"},
+		{key: "timeZone", wantFile: "Europe/Berlin", env: "Asia/Tokyo", flag: "America/New_York", invalidIn: "Mars/Olympus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			key, err := FindKey(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			value := func(c *credentialImpl) string {
+				t.Helper()
+				cfg, err := c.GetConfig()
+				if err != nil {
+					t.Fatal(err)
+				}
+				return settingValue(cfg, tt.key)
+			}
+
+			c := newTestCredential(t, overrideConfig)
+			if got := value(c); got != tt.wantFile {
+				t.Errorf("from the file: %q, want %q", got, tt.wantFile)
+			}
+			t.Setenv(key.Env, tt.env)
+			if got := value(c); got != tt.env {
+				t.Errorf("%s over the file: %q, want %q", key.Env, got, tt.env)
+			}
+			if err := c.SetOverride(tt.key, tt.flag); err != nil {
+				t.Fatal(err)
+			}
+			if got := value(c); got != tt.flag {
+				t.Errorf("flag over %s: %q, want %q", key.Env, got, tt.flag)
+			}
+			// Overrides are not written to the config file
+			if stored := storedConfig(t, c); settingValue(stored, tt.key) != tt.wantFile {
+				t.Errorf("the config file now holds %q", settingValue(stored, tt.key))
+			}
+
+			if tt.invalidIn == "" {
+				return
+			}
+			var valueErr *InvalidValueError
+			if err := c.SetOverride(tt.key, tt.invalidIn); !errors.As(err, &valueErr) {
+				t.Errorf("flag %q: error = %v, want an InvalidValueError", tt.invalidIn, err)
+			}
+			c = newTestCredential(t, overrideConfig)
+			t.Setenv(key.Env, tt.invalidIn)
+			if _, err := c.GetConfig(); !errors.As(err, &valueErr) || valueErr.Source != key.Env {
+				t.Errorf("%s=%q: error = %v, want an InvalidValueError from %s", key.Env, tt.invalidIn, err, key.Env)
+			}
+		})
+	}
+}
+
+func TestDatabaseOverride(t *testing.T) {
+	tests := []struct {
+		name      string
+		stored    string
+		env       string // NOTION_DATABASE_ID
+		flag      string // --database
+		db        string // --db
+		want      string
+		wantAlias string
+		conflict  bool
+	}{
+		{name: "file", stored: overrideConfig, want: "db-file", wantAlias: "default"},
+		{name: "db flag", stored: overrideConfig, db: "sprint", want: "db-sprint", wantAlias: "sprint"},
+		{name: "env", stored: overrideConfig, env: "db-env", want: "db-env", wantAlias: "default"},
+		{name: "database flag over env", stored: overrideConfig, env: "db-env", flag: "db-flag", want: "db-flag", wantAlias: "default"},
+		{name: "env with db default", stored: overrideConfig, env: "db-env", db: "default", want: "db-env", wantAlias: "default"},
+		{name: "env with db alias", stored: overrideConfig, env: "db-env", db: "sprint", conflict: true},
+		{
+			// The env replaces the default database even when the profile
+			// defaults to another one
+			name:      "env over the default alias",
+			stored:    `{"secretStore":"plain","token":"t","databaseId":"db-file","databases":{"sprint":{"id":"db-sprint"}},"defaultDatabase":"sprint"}`,
+			env:       "db-env",
+			want:      "db-env",
+			wantAlias: "default",
+		},
+		{name: "no config file", env: "db-env", want: "db-env", wantAlias: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCredential(t, tt.stored)
+			t.Setenv(consts.EnvToken, "secret_env")
+			t.Setenv(consts.EnvDatabaseID, tt.env)
+			if tt.flag != "" {
+				if err := c.SetOverride(KeyDatabaseID, tt.flag); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c.SelectDatabase(tt.db)
+
+			cfg, err := c.GetConfig()
+			if tt.conflict {
+				var conflict *DatabaseConflictError
+				if !errors.As(err, &conflict) || conflict.Source != consts.EnvDatabaseID || conflict.Alias != tt.db {
+					t.Fatalf("error = %v, want a DatabaseConflictError for %s and --db %s", err, consts.EnvDatabaseID, tt.db)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.DatabaseID != tt.want {
+				t.Errorf("database = %q, want %q", cfg.DatabaseID, tt.want)
+			}
+			if alias, err := c.Database(); tt.stored != "" && (err != nil || alias != tt.wantAlias) {
+				t.Errorf("alias = %q, %v, want %q", alias, err, tt.wantAlias)
+			}
+		})
+	}
+
+	// Listing every database keeps the stored ID of each alias, with the
+	// env in place of the default
+	c := newTestCredential(t, overrideConfig)
+	t.Setenv(consts.EnvDatabaseID, "db-env")
+	databases, err := c.Databases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for alias, want := range map[string]string{"default": "db-env", "sprint": "db-sprint"} {
+		if databases[alias] != want {
+			t.Errorf("Databases()[%s] = %q, want %q", alias, databases[alias], want)
+		}
+		cfg, err := c.ForDatabase(alias).GetConfig()
+		if err != nil || cfg.DatabaseID != want {
+			t.Errorf("ForDatabase(%s) = %v, %v, want %s", alias, cfg, err, want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/secrets"
+)
+
+// ProfileNotFoundError reports a profile missing from the config file
+type ProfileNotFoundError struct {
+	Name string
+}
+
+func (e *ProfileNotFoundError) Error() string {
+	return fmt.Sprintf("profile %q not found", e.Name)
+}
+
+// ValidateProfileName reports names that are not letters, digits, dashes
+// and underscores
+func ValidateProfileName(name string) error {
+	if name == "" {
+		return errors.New("profile name cannot be empty")
+	}
+	if strings.Trim(strings.ToLower(name), "abcdefghijklmnopqrstuvwxyz0123456789-_") != "" {
+		return fmt.Errorf("invalid profile name %q, use letters, digits, - and _", name)
+	}
+	return nil
+}
+
+func (c *credentialImpl) SelectProfile(name string) {
+	c.profile = name
+}
+
+func (c *credentialImpl) Profile() (string, error) {
+	stored, err := c.readConfig()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		stored = &models.Config{}
+	}
+	return c.profileName(stored), nil
+}
+
+func (c *credentialImpl) ListProfiles() ([]string, error) {
+	stored, err := c.readConfig()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range stored.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{consts.DefaultProfile}, names...), nil
+}
+
+func (c *credentialImpl) UseProfile(name string) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	stored, err := c.readConfig()
+	if err != nil {
+		return err
+	}
+	if _, err := profileConfig(stored, name); err != nil {
+		return err
+	}
+	stored.ActiveProfile = name
+	if name == consts.DefaultProfile {
+		stored.ActiveProfile = ""
+	}
+	return c.saveConfig(stored)
+}
+
+func (c *credentialImpl) RemoveProfile(name string) error {
+	if name == consts.DefaultProfile {
+		return errors.New("the default profile cannot be removed")
+	}
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	stored, err := c.readConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := stored.Profiles[name]; !ok {
+		return &ProfileNotFoundError{Name: name}
+	}
+	delete(stored.Profiles, name)
+	if stored.ActiveProfile == name {
+		stored.ActiveProfile = ""
+	}
+	if err := c.saveConfig(stored); err != nil {
+		return err
+	}
+	if stored.SecretStore != "" && stored.SecretStore != secrets.BackendPlain {
+		if store, err := openStore(stored.SecretStore); err == nil {
+			_ = store.Delete(name)
+		}
+	}
+	return nil
+}
+
+// profileName returns the profile in use: the selected one, then
+// TODO_PROFILE, then the active profile of the config file
+func (c *credentialImpl) profileName(stored *models.Config) string {
+	for _, name := range []string{c.profile, os.Getenv(consts.EnvProfile), stored.ActiveProfile} {
+		if name != "" {
+			return name
+		}
+	}
+	return consts.DefaultProfile
+}
+
+// profileConfig returns the stored config with the settings of the named
+// profile in place of the default profile
+func profileConfig(stored *models.Config, name string) (*models.Config, error) {
+	cfg := *stored
+	if name != consts.DefaultProfile {
+		profile, ok := stored.Profiles[name]
+		if !ok {
+			return nil, &ProfileNotFoundError{Name: name}
+		}
+		cfg.Profile = profile
+	}
+	return &cfg, nil
+}
+
+// storeProfile writes cfg, as returned by profileConfig, back to stored
+func storeProfile(stored *models.Config, name string, cfg *models.Config) {
+	if name == consts.DefaultProfile {
+		*stored = *cfg
+		return
+	}
+	defaultProfile := stored.Profile
+	profiles := map[string]models.Profile{}
+	for key, profile := range stored.Profiles {
+		profiles[key] = profile
+	}
+	profiles[name] = cfg.Profile
+
+	*stored = *cfg
+	stored.Profile = defaultProfile
+	stored.Profiles = profiles
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+)
+
+// profilesConfig has a default profile, two named ones and work active
+const profilesConfig = `{
+	"secretStore": "plain",
+	"token": "secret_default",
+	"databaseId": "db-default",
+	"dateFormat": "DD-MM-YYYY",
+	"activeProfile": "work",
+	"profiles": {
+		"work": {"token": "secret_work", "databaseId": "db-work"},
+		"home": {"token": "secret_home", "databaseId": "db-home"}
+	}
+}`
+
+func TestProfileSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		stored   string
+		env      string // TODO_PROFILE
+		selected string // --profile
+		want     string
+		wantDB   string
+	}{
+		{name: "active profile", stored: profilesConfig, want: "work", wantDB: "db-work"},
+		{name: "env over the active profile", stored: profilesConfig, env: "home", want: "home", wantDB: "db-home"},
+		{name: "flag over env", stored: profilesConfig, env: "home", selected: "default", want: "default", wantDB: "db-default"},
+		{name: "flag", stored: profilesConfig, selected: "home", want: "home", wantDB: "db-home"},
+		{
+			name:   "no active profile",
+			stored: `{"secretStore":"plain","token":"t","databaseId":"db-default","profiles":{"work":{"token":"w","databaseId":"db-work"}}}`,
+			want:   "default", wantDB: "db-default",
+		},
+		{name: "no config file", env: "home", want: "home"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCredential(t, tt.stored)
+			t.Setenv(consts.EnvProfile, tt.env)
+			c.SelectProfile(tt.selected)
+
+			name, err := c.Profile()
+			if err != nil || name != tt.want {
+				t.Fatalf("Profile() = %q, %v, want %q", name, err, tt.want)
+			}
+			if tt.wantDB == "" {
+				return
+			}
+			cfg, err := c.GetConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.DatabaseID != tt.wantDB {
+				t.Errorf("database = %q, want %q", cfg.DatabaseID, tt.wantDB)
+			}
+			// Settings outside the profiles are shared
+			if cfg.DateFormat != "DD-MM-YYYY" && tt.stored == profilesConfig {
+				t.Errorf("date format = %q, want the shared DD-MM-YYYY", cfg.DateFormat)
+			}
+		})
+	}
+
+	c := newTestCredential(t, profilesConfig)
+	c.SelectProfile("travel")
+	var notFound *ProfileNotFoundError
+	if _, err := c.GetConfig(); !errors.As(err, &notFound) || notFound.Name != "travel" {
+		t.Errorf("unknown profile: error = %v, want a ProfileNotFoundError", err)
+	}
+}
+
+func TestSetConfigProfile(t *testing.T) {
+	c := newTestCredential(t, profilesConfig)
+	c.SelectProfile("travel")
+	if err := c.SetConfig("secret_travel", "db-travel"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := c.GetConfig(); err != nil || cfg.Token != "secret_travel" || cfg.DatabaseID != "db-travel" {
+		t.Fatalf("new profile = %+v, %v", cfg, err)
+	}
+
+	stored := storedConfig(t, c)
+	if stored.DatabaseID != "db-default" || stored.Token != "secret_default" {
+		t.Errorf("the default profile changed: %+v", stored.Profile)
+	}
+	if stored.Profiles["work"].DatabaseID != "db-work" {
+		t.Errorf("the work profile changed: %+v", stored.Profiles["work"])
+	}
+	if stored.ActiveProfile != "work" {
+		t.Errorf("active profile = %q, want work", stored.ActiveProfile)
+	}
+
+	c.SelectProfile("no spaces")
+	if err := c.SetConfig("secret", "db"); err == nil {
+		t.Error("SetConfig accepted an invalid profile name")
+	}
+}
+
+func TestManageProfiles(t *testing.T) {
+	c := newTestCredential(t, profilesConfig)
+
+	names, err := c.ListProfiles()
+	if want := []string{"default", "home", "work"}; err != nil || !reflect.DeepEqual(names, want) {
+		t.Errorf("ListProfiles() = %v, %v, want %v", names, err, want)
+	}
+
+	if err := c.UseProfile("home"); err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := c.Profile(); name != "home" {
+		t.Errorf("profile = %q after using home", name)
+	}
+	if err := c.UseProfile("default"); err != nil {
+		t.Fatal(err)
+	}
+	if stored := storedConfig(t, c); stored.ActiveProfile != "" {
+		t.Errorf("active profile = %q, want it cleared for the default profile", stored.ActiveProfile)
+	}
+	var notFound *ProfileNotFoundError
+	if err := c.UseProfile("travel"); !errors.As(err, &notFound) {
+		t.Errorf("UseProfile(travel) error = %v, want a ProfileNotFoundError", err)
+	}
+
+	if err := c.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RemoveProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	stored := storedConfig(t, c)
+	if _, ok := stored.Profiles["work"]; ok || stored.ActiveProfile != "" {
+		t.Errorf("after removing the active profile: %+v", stored)
+	}
+	if err := c.RemoveProfile("work"); !errors.As(err, &notFound) {
+		t.Errorf("removing a missing profile: error = %v", err)
+	}
+	if err := c.RemoveProfile("default"); err == nil {
+		t.Error("RemoveProfile removed the default profile")
+	}
+}
+
+func TestValidateProfileName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"work":      true,
+		"Work_2":    true,
+		"side-job":  true,
+		"":          false,
+		"two words": false,
+		"a/b":       false,
+		"über":      false,
+	} {
+		if err := ValidateProfileName(name); (err == nil) != valid {
+			t.Errorf("ValidateProfileName(%q) = %v, want valid %v", name, err, valid)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/secrets"
+)
+
+var (
+	storesMu sync.Mutex
+	// stores are opened once per run and shared, so the encrypted file is
+	// only decrypted once
+	stores = map[string]secrets.Store{}
+
+	// keyringAvailable and runKeyringTool find and drive the system keyring
+	keyringAvailable                = secrets.KeyringAvailable
+	runKeyringTool   secrets.Runner = secrets.RunTool
+
+	// warnings receives the warning about tokens left in the config file,
+	// printed once per run
+	warnings  io.Writer = os.Stderr
+	warnPlain sync.Once
+)
+
+// defaultBackend returns the preferred secret store: the keyring where one
+// is available, otherwise the encrypted file
+func defaultBackend() string {
+	if keyringAvailable() {
+		return secrets.BackendKeyring
+	}
+	return secrets.BackendFile
+}
+
+// openStore returns the secret store of a backend other than plain
+func openStore(backend string) (secrets.Store, error) {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	if store, ok := stores[backend]; ok {
+		return store, nil
+	}
+
+	var store secrets.Store
+	switch backend {
+	case secrets.BackendKeyring:
+		if !keyringAvailable() {
+			return nil, errors.New("no keyring is available, use the file secret store")
+		}
+		store = secrets.NewKeyringStore(runKeyringTool)
+	case secrets.BackendFile:
+		store = secrets.NewFileStore(files.NewFileService(files.DataDir, consts.SecretsFileName), secrets.PromptPassphrase)
+	default:
+		return nil, fmt.Errorf("unknown secret store %q, use %s", backend, strings.Join(secrets.Backends, ", "))
+	}
+	stores[backend] = store
+	return store, nil
+}
+
+func (c *credentialImpl) SetSecretStore(backend string) error {
+	if _, err := openStore(backend); err != nil && backend != secrets.BackendPlain {
+		return err
+	}
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	stored, err := c.readConfig()
+	if os.IsNotExist(err) {
+		stored, err = &models.Config{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	previous := stored.SecretStore
+	if previous == backend {
+		return nil
+	}
+
+	tokens := map[string]string{}
+	for _, name := range profileNames(stored) {
+		token, err := storedToken(stored, name)
+		if errors.Is(err, secrets.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		tokens[name] = token
+	}
+	for name, token := range tokens {
+		if err := saveToken(stored, backend, name, token); err != nil {
+			return err
+		}
+	}
+	stored.SecretStore = backend
+	if err := c.saveConfig(stored); err != nil {
+		return err
+	}
+
+	// Only forget the old copies once the config points to the new store
+	if previous != "" && previous != secrets.BackendPlain {
+		if store, err := openStore(previous); err == nil {
+			for name := range tokens {
+				_ = store.Delete(name)
+			}
+		}
+	}
+	return nil
+}
+
+// migrateTokens moves the plaintext tokens of a config that predates secret
+// stores into the preferred store. Without a keyring or TODO_PASSPHRASE that
+// would mean prompting for a new passphrase in any command, so the tokens
+// stay with a warning. It returns the updated config, or nil when nothing
+// was migrated.
+func (c *credentialImpl) migrateTokens() (*models.Config, error) {
+	backend := defaultBackend()
+	if backend == secrets.BackendFile && os.Getenv(consts.EnvPassphrase) == "" {
+		warnPlain.Do(func() {
+			path, _ := c.file.Path()
+			fmt.Fprintf(warnings, "Warning: your Notion token is stored in plain text in %s; set %s or run 'todo config --secret-store file' to encrypt it\n", path, consts.EnvPassphrase)
+		})
+		return nil, nil
+	}
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	stored, err := c.readConfig()
+	if err != nil {
+		return nil, err
+	}
+	if stored.SecretStore != "" {
+		// Migrated by another command meanwhile
+		return stored, nil
+	}
+	if err := moveTokens(stored, backend); err != nil {
+		return nil, fmt.Errorf("failed to move the token out of the config file into the %s secret store: %w (run 'todo config --secret-store plain' to keep it there)", backend, err)
+	}
+	if err := c.saveConfig(stored); err != nil {
+		return nil, fmt.Errorf("failed to save the config after moving the token into the %s secret store: %w", backend, err)
+	}
+	return stored, nil
+}
+
+// moveTokens moves the plaintext tokens of every profile of stored into
+// backend and records it as the secret store
+func moveTokens(stored *models.Config, backend string) error {
+	for _, name := range profileNames(stored) {
+		token, err := storedToken(stored, name)
+		if err != nil || token == "" {
+			continue
+		}
+		if err := saveToken(stored, backend, name, token); err != nil {
+			return err
+		}
+	}
+	stored.SecretStore = backend
+	return nil
+}
+
+// hasPlainTokens reports a config that predates secret stores and holds
+// tokens
+func hasPlainTokens(stored *models.Config) bool {
+	if stored.SecretStore != "" {
+		return false
+	}
+	for _, name := range profileNames(stored) {
+		if token, _ := storedToken(stored, name); token != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// storedToken returns the token of profile name from wherever stored
+// keeps it
+func storedToken(stored *models.Config, name string) (string, error) {
+	profile, err := profileConfig(stored, name)
+	if err != nil {
+		return "", err
+	}
+	if profile.Token != "" || stored.SecretStore == "" || stored.SecretStore == secrets.BackendPlain {
+		return profile.Token, nil
+	}
+	store, err := openStore(stored.SecretStore)
+	if err != nil {
+		return "", err
+	}
+	token, err := store.Get(name)
+	if err != nil {
+		return "", fmt.Errorf("token of profile %q in the %s secret store: %w", name, stored.SecretStore, err)
+	}
+	return token, nil
+}
+
+// storeToken saves the token of profile name, moving the tokens of a config
+// without a secret store to the preferred store first
+func storeToken(stored *models.Config, name, token string) error {
+	if stored.SecretStore == "" {
+		if err := moveTokens(stored, defaultBackend()); err != nil {
+			return err
+		}
+	}
+	return saveToken(stored, stored.SecretStore, name, token)
+}
+
+// saveToken stores the token of profile name in backend, keeping the
+// plaintext field of the profile only for the plain backend
+func saveToken(stored *models.Config, backend, name, token string) error {
+	plain := ""
+	if backend == secrets.BackendPlain {
+		plain = token
+	} else {
+		store, err := openStore(backend)
+		if err != nil {
+			return err
+		}
+		if err := store.Set(name, token); err != nil {
+			return err
+		}
+	}
+
+	profile, err := profileConfig(stored, name)
+	if err != nil {
+		return err
+	}
+	profile.Token = plain
+	storeProfile(stored, name, profile)
+	return nil
+}
+
+// profileNames returns the names of the profiles of stored, default first
+func profileNames(stored *models.Config) []string {
+	names := []string{consts.DefaultProfile}
+	for name := range stored.Profiles {
+		names = append(names, name)
+	}
+	return names
+}
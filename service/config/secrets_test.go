@@ -0,0 +1,190 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/secrets"
+)
+
+// plainConfig is a config written before secret stores, with the tokens of
+// two profiles in plain text
+const plainConfig = `{"databaseId":"db-default","token":"secret_default","profiles":{"work":{"databaseId":"db-work","token":"secret_work"}}}`
+
+// fakeKeyring is a keyring driven through the commands of security and
+// secret-tool, failing every command with err when set
+type fakeKeyring struct {
+	items map[string]string
+	err   error
+}
+
+func (k *fakeKeyring) run(input, name string, args ...string) (string, error) {
+	if k.err != nil {
+		return "", k.err
+	}
+	account := ""
+	for i, arg := range args {
+		if (arg == "-a" || arg == "account") && i+1 < len(args) {
+			account = args[i+1]
+		}
+	}
+	switch args[0] {
+	case "find-generic-password", "lookup":
+		value, ok := k.items[account]
+		if !ok {
+			if name == "security" {
+				return "", &secrets.ToolError{Tool: name, Code: 44}
+			}
+			return "", &secrets.ToolError{Tool: name, Code: 1}
+		}
+		return value + "\n", nil
+	case "-i":
+		// add-generic-password -U -s 'service' -a 'account' -w 'value'
+		fields := strings.Fields(input)
+		k.items[strings.Trim(fields[5], "'")] = strings.Trim(fields[7], "'")
+	case "store":
+		k.items[account] = input
+	case "delete-generic-password", "clear":
+		delete(k.items, account)
+	}
+	return "", nil
+}
+
+func TestMigrateTokens(t *testing.T) {
+	locked := &secrets.ToolError{Tool: "secret-tool", Code: 1, Stderr: "Cannot autolaunch D-Bus without X11 $DISPLAY"}
+
+	tests := []struct {
+		name       string
+		keyring    *fakeKeyring // nil when no keyring is available
+		passphrase string
+		wantStore  string // secret store recorded after migrating, empty when the tokens stay
+		wantErr    string
+		wantWarn   bool
+	}{
+		{name: "keyring", keyring: &fakeKeyring{items: map[string]string{}}, wantStore: secrets.BackendKeyring},
+		{name: "file with passphrase", passphrase: "correct horse", wantStore: secrets.BackendFile},
+		{name: "file without passphrase", wantWarn: true},
+		{name: "locked keyring", keyring: &fakeKeyring{items: map[string]string{}, err: locked}, wantErr: "Cannot autolaunch D-Bus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCredential(t, plainConfig)
+			if tt.keyring != nil {
+				keyringAvailable = func() bool { return true }
+				runKeyringTool = tt.keyring.run
+			}
+			t.Setenv(consts.EnvPassphrase, tt.passphrase)
+			var warned bytes.Buffer
+			warnings = &warned
+
+			// Settings alone do not need the token, so nothing is migrated
+			if _, err := c.GetSettings(); err != nil {
+				t.Fatal(err)
+			}
+			if stored := storedConfig(t, c); stored.SecretStore != "" || stored.Token != "secret_default" {
+				t.Fatalf("GetSettings migrated the tokens: %+v", stored)
+			}
+
+			for i := 0; i < 2; i++ {
+				cfg, err := c.GetConfig()
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "--secret-store plain") {
+						t.Fatalf("error = %v, want it to contain %q and how to keep the token", err, tt.wantErr)
+					}
+					if !errors.Is(err, locked) {
+						t.Errorf("error %v does not wrap the keyring failure", err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if cfg.Token != "secret_default" {
+					t.Errorf("token = %q, want secret_default", cfg.Token)
+				}
+			}
+
+			stored := storedConfig(t, c)
+			if stored.SecretStore != tt.wantStore {
+				t.Errorf("secret store = %q, want %q", stored.SecretStore, tt.wantStore)
+			}
+			if tt.wantStore == "" {
+				if stored.Token != "secret_default" || stored.Profiles["work"].Token != "secret_work" {
+					t.Errorf("tokens were removed without being migrated: %+v", stored)
+				}
+			} else if stored.Token != "" || stored.Profiles["work"].Token != "" {
+				t.Errorf("the config file still holds tokens: %+v", stored)
+			}
+
+			switch tt.wantStore {
+			case secrets.BackendKeyring:
+				if tt.keyring.items["default"] != "secret_default" || tt.keyring.items["work"] != "secret_work" {
+					t.Errorf("keyring holds %v", tt.keyring.items)
+				}
+			case secrets.BackendFile:
+				dir, _ := files.DirPath(files.DataDir)
+				if !fileExists(filepath.Join(dir, consts.SecretsFileName)) {
+					t.Error("no encrypted token file was written")
+				}
+				// A later run reads the tokens back with the passphrase
+				stores = map[string]secrets.Store{}
+				c.SelectProfile("work")
+				if cfg, err := c.GetConfig(); err != nil || cfg.Token != "secret_work" {
+					t.Errorf("work token = %v, %v after migrating", cfg, err)
+				}
+			}
+
+			warning := warned.String()
+			if !tt.wantWarn {
+				if warning != "" {
+					t.Errorf("unexpected warning %q", warning)
+				}
+				return
+			}
+			path, _ := c.file.Path()
+			if strings.Count(warning, "\n") != 1 || !strings.Contains(warning, "plain text") || !strings.Contains(warning, path) ||
+				!strings.Contains(warning, consts.EnvPassphrase) || !strings.Contains(warning, "--secret-store") {
+				t.Errorf("warning = %q, want one line naming %s and how to migrate", warning, path)
+			}
+		})
+	}
+}
+
+func TestSetSecretStore(t *testing.T) {
+	c := newTestCredential(t, plainConfig)
+	keyring := &fakeKeyring{items: map[string]string{}}
+	keyringAvailable = func() bool { return true }
+	runKeyringTool = keyring.run
+	t.Setenv(consts.EnvPassphrase, "correct horse")
+
+	for _, backend := range []string{secrets.BackendKeyring, secrets.BackendFile, secrets.BackendPlain} {
+		if err := c.SetSecretStore(backend); err != nil {
+			t.Fatalf("SetSecretStore(%s): %v", backend, err)
+		}
+		if stored := storedConfig(t, c); stored.SecretStore != backend {
+			t.Errorf("secret store = %q, want %q", stored.SecretStore, backend)
+		}
+		c.SelectProfile("work")
+		if cfg, err := c.GetConfig(); err != nil || cfg.Token != "secret_work" {
+			t.Errorf("%s: work token = %v, %v", backend, cfg, err)
+		}
+		c.SelectProfile("")
+	}
+	// Tokens are forgotten by the stores they leave
+	if len(keyring.items) != 0 {
+		t.Errorf("the keyring still holds %v", keyring.items)
+	}
+	if stored := storedConfig(t, c); stored.Token != "secret_default" {
+		t.Errorf("plain token = %q", stored.Token)
+	}
+
+	if err := c.SetSecretStore("vault"); err == nil {
+		t.Error("SetSecretStore accepted an unknown store")
+	}
+}
@@ -1,74 +1,129 @@
 package files
 
 import (
-	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
+
+	"github.com/caffeines/notion-todo/consts"
 )
 
 const AppName = "notion-todo"
 
 type fileImpl struct {
+	dir      Dir
 	fileName string
 }
 
-func NewFileService(fileName string) File {
+// NewFileService returns the file named fileName in the app directory of
+// kind dir
+func NewFileService(dir Dir, fileName string) File {
 	return &fileImpl{
+		dir:      dir,
 		fileName: fileName,
 	}
 }
 
-func (f *fileImpl) getBasePath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return homeDir, nil
-}
+func (f *fileImpl) Path() (string, error) {
+	legacyOnce.Do(migrateLegacy)
 
-func (f *fileImpl) createDirIfNotExists() error {
-	basePath, err := f.getBasePath()
-	if err != nil {
-		return err
+	if path := f.override(); path != "" {
+		return path, nil
 	}
-	dirPath := fmt.Sprintf("%s/.%s", basePath, AppName)
-	_, err = exec.Command("mkdir", "-p", dirPath).Output()
+	base, err := DirPath(f.dir)
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	return filepath.Join(base, f.fileName), nil
 }
 
-func (f *fileImpl) getPath() (string, error) {
-	basePath, err := f.getBasePath()
-	if err != nil {
-		return "", err
+// override returns the path given with SetConfigFile for the config file,
+// or an empty string
+func (f *fileImpl) override() string {
+	if f.dir != ConfigDir || f.fileName != consts.ConfigFileName {
+		return ""
 	}
-	path := fmt.Sprintf("%s/.%s/%s", basePath, AppName, f.fileName)
-	return path, nil
+	configFileMu.RLock()
+	defer configFileMu.RUnlock()
+	return configFile
 }
 
 func (f *fileImpl) SaveFile(data []byte) error {
-	err := f.createDirIfNotExists()
-	if err != nil {
-		return err
-	}
-	path, err := f.getPath()
+	path, err := f.Path()
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(path, data, 0644)
-	return err
+	return writeAtomic(path, data)
 }
 
 func (f *fileImpl) ReadFile() ([]byte, error) {
-	path, err := f.getPath()
+	path, err := f.Path()
 	if err != nil {
 		return nil, err
 	}
 	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && f.override() == "" {
+		// Fall back to a legacy file that could not be migrated
+		if legacy, legacyErr := legacyDir(); legacyErr == nil && filepath.Dir(path) != legacy {
+			if _, ok := legacyDirs[f.fileName]; ok {
+				legacyPath := filepath.Join(legacy, f.fileName)
+				if data, legacyErr := os.ReadFile(legacyPath); legacyErr == nil {
+					restrictMode(legacyPath, 0600)
+					restrictMode(legacy, 0700)
+					return data, nil
+				}
+			}
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
+	restrictMode(path, 0600)
+	if f.override() == "" {
+		restrictMode(filepath.Dir(path), 0700)
+	}
 	return data, nil
 }
+
+// restrictMode takes away the permissions of path beyond mode, for files
+// and directories created by earlier versions or by hand. Failures are
+// ignored, as the file was read all the same.
+func restrictMode(path string, mode os.FileMode) {
+	info, err := os.Stat(path)
+	if err == nil && info.Mode().Perm()&^mode != 0 {
+		os.Chmod(path, info.Mode().Perm()&mode)
+	}
+}
+
+// writeAtomic writes data to a temporary file next to path and renames it
+// into place, so readers never see a partial file. The config holds
+// credentials, so files and directories are private to the user.
+func writeAtomic(path string, data []byte) (err error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = tmp.Chmod(0600); err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,106 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+)
+
+func TestSaveFile(t *testing.T) {
+	home := newTestHome(t)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	file := NewFileService(ConfigDir, consts.ConfigFileName)
+
+	for _, data := range []string{"first", "second"} {
+		if err := file.SaveFile([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := file.ReadFile(); err != nil || string(got) != data {
+			t.Errorf("ReadFile() = %q, %v, want %q", got, err, data)
+		}
+	}
+
+	dir := filepath.Join(home, "xdg", AppName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != consts.ConfigFileName {
+		t.Errorf("the config directory holds %v, want only %s", entries, consts.ConfigFileName)
+	}
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if mode := perm(t, filepath.Join(dir, consts.ConfigFileName)); mode != 0600 {
+		t.Errorf("file mode %o, want 600", mode)
+	}
+	if mode := perm(t, dir); mode != 0700 {
+		t.Errorf("directory mode %o, want 700", mode)
+	}
+}
+
+func TestReadFileRestrictsMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not enforced on Windows")
+	}
+
+	tests := []struct {
+		name     string
+		fileMode os.FileMode
+		dirMode  os.FileMode
+		wantFile os.FileMode
+		wantDir  os.FileMode
+	}{
+		{name: "readable by others", fileMode: 0644, dirMode: 0755, wantFile: 0600, wantDir: 0700},
+		{name: "writable by the group", fileMode: 0660, dirMode: 0770, wantFile: 0600, wantDir: 0700},
+		{name: "private", fileMode: 0600, dirMode: 0700, wantFile: 0600, wantDir: 0700},
+		{name: "read-only", fileMode: 0444, dirMode: 0500, wantFile: 0400, wantDir: 0500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := newTestHome(t)
+			dir := filepath.Join(home, ".config", AppName)
+			path := filepath.Join(dir, consts.ConfigFileName)
+			writeFile(t, path, "{}", tt.fileMode)
+			if err := os.Chmod(dir, tt.dirMode); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { os.Chmod(dir, 0700) })
+
+			if _, err := NewFileService(ConfigDir, consts.ConfigFileName).ReadFile(); err != nil {
+				t.Fatal(err)
+			}
+			if mode := perm(t, path); mode != tt.wantFile {
+				t.Errorf("file mode %o, want %o", mode, tt.wantFile)
+			}
+			if mode := perm(t, dir); mode != tt.wantDir {
+				t.Errorf("directory mode %o, want %o", mode, tt.wantDir)
+			}
+		})
+	}
+
+	t.Run("config file given by path", func(t *testing.T) {
+		newTestHome(t)
+		dir := t.TempDir()
+		if err := os.Chmod(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "project.json")
+		writeFile(t, path, "{}", 0644)
+		SetConfigFile(path)
+
+		if _, err := NewFileService(ConfigDir, consts.ConfigFileName).ReadFile(); err != nil {
+			t.Fatal(err)
+		}
+		if mode := perm(t, path); mode != 0600 {
+			t.Errorf("file mode %o, want 600", mode)
+		}
+		// The directory is the user's own, not the app's
+		if mode := perm(t, dir); mode != 0755 {
+			t.Errorf("directory mode %o, want it left at 755", mode)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/caffeines/notion-todo/consts"
+)
+
+// Dir is the kind of directory a file is kept in
+type Dir int
+
+const (
+	// ConfigDir holds settings, under $XDG_CONFIG_HOME
+	ConfigDir Dir = iota
+	// CacheDir holds data that can be fetched again, under $XDG_CACHE_HOME
+	CacheDir
+	// DataDir holds state such as the encrypted tokens, under $XDG_DATA_HOME
+	DataDir
+)
+
+// legacyDirs maps the files of the directory used by earlier versions to
+// their directories
+var legacyDirs = map[string]Dir{
+	consts.ConfigFileName:      ConfigDir,
+	consts.StatusCacheFileName: CacheDir,
+	consts.SecretsFileName:     DataDir,
+}
+
+var (
+	configFileMu sync.RWMutex
+	// configFile replaces the path of the config file when set
+	configFile string
+
+	legacyOnce sync.Once
+)
+
+// SetConfigFile makes the config file live at path instead of the config
+// directory. An empty path restores the default.
+func SetConfigFile(path string) {
+	configFileMu.Lock()
+	defer configFileMu.Unlock()
+	configFile = path
+}
+
+// DirPath returns the directory of the app for dir, following the XDG base
+// directory specification. Relative XDG variables are ignored, as the
+// specification requires.
+func DirPath(dir Dir) (string, error) {
+	env, fallback := "XDG_CONFIG_HOME", ".config"
+	switch dir {
+	case CacheDir:
+		env, fallback = "XDG_CACHE_HOME", ".cache"
+	case DataDir:
+		env, fallback = "XDG_DATA_HOME", filepath.Join(".local", "share")
+	}
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, AppName), nil
+	}
+
+	if runtime.GOOS == "windows" {
+		base, err := os.UserConfigDir()
+		if dir == CacheDir {
+			base, err = os.UserCacheDir()
+		}
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(base, AppName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fallback, AppName), nil
+}
+
+// legacyDir returns the directory used by earlier versions, ~/.notion-todo
+func legacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "."+AppName), nil
+}
+
+// migrateLegacy moves the files of the legacy directory to their XDG
+// directories, keeping files that already exist there, and removes the
+// legacy directory once it is empty. Files that cannot be moved are still
+// read from the legacy directory.
+func migrateLegacy() {
+	legacy, err := legacyDir()
+	if err != nil {
+		return
+	}
+	entries, err := os.ReadDir(legacy)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		dir, ok := legacyDirs[entry.Name()]
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		base, err := DirPath(dir)
+		if err != nil {
+			continue
+		}
+		source := filepath.Join(legacy, entry.Name())
+		target := filepath.Join(base, entry.Name())
+		if source == target {
+			continue
+		}
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(base, 0700); err != nil {
+			continue
+		}
+		if err := os.Rename(source, target); err != nil {
+			// Renaming fails across file systems, so copy instead
+			data, err := os.ReadFile(source)
+			if err != nil || writeAtomic(target, data) != nil {
+				continue
+			}
+			os.Remove(source)
+		}
+		// Earlier versions wrote files readable by everyone
+		os.Chmod(target, 0600)
+	}
+	// Only succeeds when every file was moved
+	os.Remove(legacy)
+}
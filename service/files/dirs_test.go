@@ -0,0 +1,161 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+)
+
+// newTestHome points HOME at a temporary directory with the XDG variables
+// unset, and lets the legacy directory be migrated again
+func newTestHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME"} {
+		t.Setenv(env, "")
+	}
+	legacyOnce = sync.Once{}
+	t.Cleanup(func() {
+		legacyOnce = sync.Once{}
+		SetConfigFile("")
+	})
+	return home
+}
+
+// writeFile writes data to path with mode, creating its directories
+func writeFile(t *testing.T, path, data string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), mode); err != nil {
+		t.Fatal(err)
+	}
+	// WriteFile applies the umask
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// perm returns the permissions of path
+func perm(t *testing.T, path string) os.FileMode {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Mode().Perm()
+}
+
+func TestDirPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fallbacks are the user directories of Windows")
+	}
+	home := newTestHome(t)
+	xdg := t.TempDir()
+
+	tests := []struct {
+		dir  Dir
+		env  string
+		xdg  string
+		want string
+	}{
+		{dir: ConfigDir, env: "XDG_CONFIG_HOME", want: filepath.Join(home, ".config", AppName)},
+		{dir: ConfigDir, env: "XDG_CONFIG_HOME", xdg: xdg, want: filepath.Join(xdg, AppName)},
+		{dir: ConfigDir, env: "XDG_CONFIG_HOME", xdg: "relative/config", want: filepath.Join(home, ".config", AppName)},
+		{dir: CacheDir, env: "XDG_CACHE_HOME", want: filepath.Join(home, ".cache", AppName)},
+		{dir: CacheDir, env: "XDG_CACHE_HOME", xdg: xdg, want: filepath.Join(xdg, AppName)},
+		{dir: DataDir, env: "XDG_DATA_HOME", want: filepath.Join(home, ".local", "share", AppName)},
+		{dir: DataDir, env: "XDG_DATA_HOME", xdg: xdg, want: filepath.Join(xdg, AppName)},
+		{dir: DataDir, env: "XDG_DATA_HOME", xdg: "~/data", want: filepath.Join(home, ".local", "share", AppName)},
+	}
+	for _, tt := range tests {
+		t.Setenv(tt.env, tt.xdg)
+		got, err := DirPath(tt.dir)
+		if err != nil || got != tt.want {
+			t.Errorf("DirPath with %s=%q = %q, %v, want %q", tt.env, tt.xdg, got, err, tt.want)
+		}
+		t.Setenv(tt.env, "")
+	}
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not enforced on Windows")
+	}
+	legacyFiles := map[string]string{
+		consts.ConfigFileName:      "legacy config",
+		consts.StatusCacheFileName: "legacy statuses",
+		consts.SecretsFileName:     "legacy secrets",
+	}
+
+	t.Run("moves every file", func(t *testing.T) {
+		home := newTestHome(t)
+		legacy := filepath.Join(home, "."+AppName)
+		for name, data := range legacyFiles {
+			writeFile(t, filepath.Join(legacy, name), data, 0644)
+		}
+
+		for name, data := range legacyFiles {
+			file := NewFileService(legacyDirs[name], name)
+			got, err := file.ReadFile()
+			if err != nil || string(got) != data {
+				t.Errorf("%s = %q, %v, want %q", name, got, err, data)
+			}
+			path, _ := file.Path()
+			if dir, _ := DirPath(legacyDirs[name]); filepath.Dir(path) != dir {
+				t.Errorf("%s is at %s, want it in %s", name, path, dir)
+			}
+			if mode := perm(t, path); mode != 0600 {
+				t.Errorf("%s has mode %o, want 600", name, mode)
+			}
+			if mode := perm(t, filepath.Dir(path)); mode != 0700 {
+				t.Errorf("the directory of %s has mode %o, want 700", name, mode)
+			}
+		}
+		if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+			t.Errorf("the legacy directory was kept: %v", err)
+		}
+	})
+
+	t.Run("keeps existing files", func(t *testing.T) {
+		home := newTestHome(t)
+		legacy := filepath.Join(home, "."+AppName)
+		for name, data := range legacyFiles {
+			writeFile(t, filepath.Join(legacy, name), data, 0644)
+		}
+		xdgConfig := filepath.Join(home, ".config", AppName, consts.ConfigFileName)
+		writeFile(t, xdgConfig, "new config", 0600)
+
+		got, err := NewFileService(ConfigDir, consts.ConfigFileName).ReadFile()
+		if err != nil || string(got) != "new config" {
+			t.Errorf("config = %q, %v, want the XDG file", got, err)
+		}
+		// The legacy config stays, and with it the legacy directory
+		if data, err := os.ReadFile(filepath.Join(legacy, consts.ConfigFileName)); err != nil || string(data) != "legacy config" {
+			t.Errorf("legacy config = %q, %v", data, err)
+		}
+		if _, err := os.Stat(filepath.Join(legacy, consts.StatusCacheFileName)); !os.IsNotExist(err) {
+			t.Errorf("the legacy statuses were not moved: %v", err)
+		}
+	})
+
+	t.Run("ignores other files", func(t *testing.T) {
+		home := newTestHome(t)
+		legacy := filepath.Join(home, "."+AppName)
+		writeFile(t, filepath.Join(legacy, consts.ConfigFileName), "legacy config", 0644)
+		writeFile(t, filepath.Join(legacy, "notes.txt"), "mine", 0644)
+
+		if _, err := NewFileService(ConfigDir, consts.ConfigFileName).ReadFile(); err != nil {
+			t.Fatal(err)
+		}
+		if data, err := os.ReadFile(filepath.Join(legacy, "notes.txt")); err != nil || string(data) != "mine" {
+			t.Errorf("notes.txt = %q, %v, want it left in place", data, err)
+		}
+	})
+}
@@ -3,4 +3,6 @@ package files
 type File interface {
 	SaveFile(data []byte) error
 	ReadFile() ([]byte, error)
+	// Path returns where the file is stored
+	Path() (string, error)
 }
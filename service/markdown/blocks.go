@@ -0,0 +1,196 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+// maxNesting is how deep blocks can be nested in a single Notion request;
+// deeper list items are attached at the deepest allowed level
+const maxNesting = 2
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.*?)(\s+#+)?\s*$`)
+	dividerPattern  = regexp.MustCompile(`^(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	taskPattern     = regexp.MustCompile(`^[-*+]\s+\[([ xX])\]\s*(.*)$`)
+	bulletPattern   = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	numberedPattern = regexp.MustCompile(`^\d{1,9}[.)]\s+(.*)$`)
+	quotePattern    = regexp.MustCompile(`^>\s?(.*)$`)
+	fencePattern    = regexp.MustCompile("^(```+|~~~+)\\s*([^`\\s]*)")
+)
+
+// node is a block being built from Markdown
+type node struct {
+	kind     string
+	text     string
+	checked  bool
+	language string
+	indent   int
+	children []*node
+}
+
+// ToBlocks converts Markdown to Notion blocks: headings, paragraphs, quotes,
+// bulleted, numbered and checkbox lists, code fences and dividers. Inline
+// bold, italic, strikethrough, code and links become rich text.
+func ToBlocks(text string) []models.BlockData {
+	var roots []*node
+	var stack []*node // Open list items, outermost first
+	var last *node    // Block that following plain lines continue
+
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.ReplaceAll(lines[i], "\t", "    ")
+		content := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if content == "" {
+			last = nil
+			continue
+		}
+
+		// attach adds n under the list item it is indented beneath, if any
+		attach := func(n *node) {
+			n.indent = indent
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) > maxNesting {
+				stack = stack[:maxNesting]
+			}
+			if len(stack) == 0 {
+				roots = append(roots, n)
+				return
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, n)
+		}
+		push := func(n *node) {
+			attach(n)
+			stack = append(stack, n)
+			last = n
+		}
+
+		if match := fencePattern.FindStringSubmatch(content); match != nil {
+			fence := match[1]
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence[:3]) && strings.Trim(strings.TrimSpace(lines[i]), fence[:1]) == "" {
+					break
+				}
+				code = append(code, trimIndent(lines[i], indent))
+			}
+			attach(&node{kind: models.BlockTypeCode, text: strings.Join(code, "\n"), language: match[2]})
+			last = nil
+			continue
+		}
+
+		// Plain lines continue the paragraph, quote or list item above them
+		if last != nil && !isBlockStart(content) {
+			last.text += "\n" + content
+			continue
+		}
+		if last != nil && last.kind == models.BlockTypeQuote {
+			if match := quotePattern.FindStringSubmatch(content); match != nil {
+				last.text += "\n" + match[1]
+				continue
+			}
+		}
+
+		switch {
+		case headingPattern.MatchString(content):
+			match := headingPattern.FindStringSubmatch(content)
+			kind := models.BlockTypeHeading3
+			switch len(match[1]) {
+			case 1:
+				kind = models.BlockTypeHeading1
+			case 2:
+				kind = models.BlockTypeHeading2
+			}
+			attach(&node{kind: kind, text: match[2]})
+			last = nil
+		case dividerPattern.MatchString(content):
+			attach(&node{kind: models.BlockTypeDivider})
+			last = nil
+		case taskPattern.MatchString(content):
+			match := taskPattern.FindStringSubmatch(content)
+			push(&node{kind: models.BlockTypeToDo, text: match[2], checked: match[1] != " "})
+		case bulletPattern.MatchString(content):
+			push(&node{kind: models.BlockTypeBulletedListItem, text: bulletPattern.FindStringSubmatch(content)[1]})
+		case numberedPattern.MatchString(content):
+			push(&node{kind: models.BlockTypeNumberedListItem, text: numberedPattern.FindStringSubmatch(content)[1]})
+		case quotePattern.MatchString(content):
+			last = &node{kind: models.BlockTypeQuote, text: quotePattern.FindStringSubmatch(content)[1]}
+			attach(last)
+		default:
+			last = &node{kind: models.BlockTypeParagraph, text: content}
+			attach(last)
+		}
+	}
+
+	blocks := make([]models.BlockData, 0, len(roots))
+	for _, root := range roots {
+		blocks = append(blocks, root.block())
+	}
+	return blocks
+}
+
+// block converts n and its children to write-shaped blocks
+func (n *node) block() models.BlockData {
+	if n.kind == models.BlockTypeDivider {
+		return models.NewBlock(n.kind, struct{}{})
+	}
+
+	content := &models.BlockContent{}
+	switch n.kind {
+	case models.BlockTypeCode:
+		content.RichText = plainRichText(n.text)
+		content.Language = codeLanguage(n.language)
+	case models.BlockTypeToDo:
+		checked := n.checked
+		content.Checked = &checked
+		content.RichText = richText(n.text)
+	default:
+		content.RichText = richText(n.text)
+	}
+	for _, child := range n.children {
+		content.Children = append(content.Children, child.block())
+	}
+	return models.NewBlock(n.kind, content)
+}
+
+// isBlockStart reports whether a line starts a new block rather than
+// continuing a paragraph
+func isBlockStart(content string) bool {
+	return headingPattern.MatchString(content) || dividerPattern.MatchString(content) ||
+		bulletPattern.MatchString(content) || numberedPattern.MatchString(content) ||
+		quotePattern.MatchString(content) || fencePattern.MatchString(content)
+}
+
+// trimIndent removes up to indent columns of leading whitespace from a code
+// line, counting a tab as four columns. The rest of the line, tabs included,
+// is kept as typed.
+func trimIndent(line string, indent int) string {
+	column := 0
+	for i, r := range line {
+		if column >= indent {
+			return line[i:]
+		}
+		switch r {
+		case ' ':
+			column++
+		case '\t':
+			column += 4
+		default:
+			return line[i:]
+		}
+	}
+	return ""
+}
+
+// ToDo returns a to_do block for text, which may use inline Markdown
+func ToDo(text string, checked bool) models.BlockData {
+	return (&node{kind: models.BlockTypeToDo, text: text, checked: checked}).block()
+}
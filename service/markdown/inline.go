@@ -0,0 +1,368 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+)
+
+// maxRichTextItems is the most rich text items Notion accepts in one block
+const maxRichTextItems = 100
+
+// segment is a run of text with the same formatting
+type segment struct {
+	text        string
+	annotations models.Annotations
+	link        string
+}
+
+// richText converts inline Markdown (bold, italic, strikethrough, code and
+// links) to rich text items of at most consts.MAX_RICH_TEXT_LENGTH UTF-16
+// code units
+func richText(text string) []models.RichTextData {
+	items := toRichText(parseInline(text, models.Annotations{}, ""))
+	if len(items) > maxRichTextItems {
+		// Too many formatted runs for one block: keep the text, drop formatting
+		items = toRichText([]segment{{text: plainSegments(parseInline(text, models.Annotations{}, ""))}})
+	}
+	return items
+}
+
+// RichText converts a line of inline Markdown to the rich text of a text
+// property
+func RichText(text string) []models.RichTextData {
+	return richText(text)
+}
+
+// plainRichText returns text as unformatted rich text items
+func plainRichText(text string) []models.RichTextData {
+	return toRichText([]segment{{text: text}})
+}
+
+func toRichText(segments []segment) []models.RichTextData {
+	items := []models.RichTextData{}
+	for _, seg := range segments {
+		runes := []rune(seg.text)
+		for len(runes) > 0 {
+			n := richTextCut(runes)
+			item := models.RichTextData{Type: "text", Text: models.Text{Content: string(runes[:n])}}
+			if seg.link != "" {
+				item.Text.Link = &models.Link{URL: seg.link}
+			}
+			if seg.annotations != (models.Annotations{}) {
+				annotations := seg.annotations
+				item.Annotations = &annotations
+			}
+			items = append(items, item)
+			runes = runes[n:]
+		}
+	}
+	return items
+}
+
+// richTextCut returns how many of runes fit in one rich text item. Notion
+// counts the length in UTF-16 code units, so characters outside the Basic
+// Multilingual Plane, such as most emoji, count twice.
+func richTextCut(runes []rune) int {
+	units := 0
+	for i, r := range runes {
+		units += utf16.RuneLen(r)
+		if units > consts.MAX_RICH_TEXT_LENGTH {
+			return i
+		}
+	}
+	return len(runes)
+}
+
+func plainSegments(segments []segment) string {
+	var b strings.Builder
+	for _, seg := range segments {
+		b.WriteString(seg.text)
+	}
+	return b.String()
+}
+
+// parseInline splits text into formatted segments. Delimiters only take
+// effect when they are closed later in the text.
+func parseInline(text string, base models.Annotations, link string) []segment {
+	var segments []segment
+	var current strings.Builder
+	annotations := base
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		seg := segment{text: current.String(), annotations: annotations, link: link}
+		if n := len(segments); n > 0 && segments[n-1].annotations == seg.annotations && segments[n-1].link == seg.link {
+			segments[n-1].text += seg.text
+		} else {
+			segments = append(segments, seg)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(text)
+	find := newFinder(runes)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes) && isEscapable(runes[i+1]):
+			current.WriteRune(runes[i+1])
+			i++
+
+		case r == '`':
+			ticks := countRun(runes, i, '`')
+			fence := strings.Repeat("`", ticks)
+			end := find.next(fence, i+ticks)
+			if end < 0 {
+				current.WriteString(fence)
+				i += ticks - 1
+				continue
+			}
+			flush()
+			codeAnnotations := annotations
+			codeAnnotations.Code = true
+			content := string(runes[i+ticks : end])
+			if ticks > 1 {
+				content = strings.TrimSpace(content)
+			}
+			segments = append(segments, segment{text: content, annotations: codeAnnotations, link: link})
+			i = end + ticks - 1
+
+		case r == '[' && link == "":
+			label, url, width, ok := parseLink(runes, i, find)
+			if !ok {
+				current.WriteRune(r)
+				continue
+			}
+			flush()
+			segments = append(segments, parseInline(label, annotations, url)...)
+			i += width - 1
+
+		case hasPrefix(runes, i, "~~"):
+			if !annotations.Strikethrough && find.next("~~", i+2) < 0 {
+				current.WriteString("~~")
+			} else {
+				flush()
+				annotations.Strikethrough = !annotations.Strikethrough
+			}
+			i++
+
+		case hasPrefix(runes, i, "**") || hasPrefix(runes, i, "__"):
+			delim := string(runes[i : i+2])
+			if (delim == "__" && !atWordBoundary(runes, i, 2, annotations.Bold)) ||
+				!flanking(runes, i, 2, annotations.Bold) ||
+				(!annotations.Bold && find.next(delim, i+2) < 0) {
+				current.WriteString(delim)
+			} else {
+				flush()
+				annotations.Bold = !annotations.Bold
+			}
+			i++
+
+		case r == '*' || r == '_':
+			delim := string(r)
+			if (r == '_' && !atWordBoundary(runes, i, 1, annotations.Italic)) ||
+				!flanking(runes, i, 1, annotations.Italic) ||
+				(!annotations.Italic && find.next(delim, i+1) < 0) {
+				current.WriteRune(r)
+			} else {
+				flush()
+				annotations.Italic = !annotations.Italic
+			}
+
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return segments
+}
+
+// parseLink reads "[label](url)" at runes[i]
+func parseLink(runes []rune, i int, find *finder) (label, url string, width int, ok bool) {
+	closeLabel := find.next("](", i)
+	if closeLabel < 0 {
+		return "", "", 0, false
+	}
+	closeURL := find.next(")", closeLabel+2)
+	if closeURL < 0 {
+		return "", "", 0, false
+	}
+	label = string(runes[i+1 : closeLabel])
+	url = strings.TrimSpace(string(runes[closeLabel+2 : closeURL]))
+	if url == "" || strings.ContainsAny(url, " \n") {
+		return "", "", 0, false
+	}
+	return label, url, closeURL + 1 - i, true
+}
+
+// finder finds the next occurrence of delimiters in a text. The parser
+// mostly moves forward, so a match is reused until the parser has passed it,
+// which keeps parsing linear in the length of the text.
+type finder struct {
+	runes   []rune
+	matches map[string]match
+}
+
+// match is the result of the last search for a delimiter
+type match struct {
+	from, at int
+}
+
+func newFinder(runes []rune) *finder {
+	return &finder{runes: runes, matches: map[string]match{}}
+}
+
+// next returns the index of the first delim at or after from, or -1
+func (f *finder) next(delim string, from int) int {
+	if last, ok := f.matches[delim]; ok && from >= last.from && (last.at < 0 || from <= last.at) {
+		return last.at
+	}
+	at := indexRunes(f.runes, []rune(delim), from)
+	f.matches[delim] = match{from: from, at: at}
+	return at
+}
+
+// indexRunes returns the index of the first sub at or after from, or -1
+func indexRunes(runes, sub []rune, from int) int {
+	for i := from; i+len(sub) <= len(runes); i++ {
+		if hasRunes(runes, i, sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasRunes(runes []rune, i int, sub []rune) bool {
+	if i+len(sub) > len(runes) {
+		return false
+	}
+	for j, r := range sub {
+		if runes[i+j] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func hasPrefix(runes []rune, i int, prefix string) bool {
+	return hasRunes(runes, i, []rune(prefix))
+}
+
+// atWordBoundary reports whether an underscore delimiter of size n at i
+// opens or closes emphasis rather than sitting inside a word
+func atWordBoundary(runes []rune, i, n int, closing bool) bool {
+	if closing {
+		return i+n >= len(runes) || !isWordRune(runes[i+n])
+	}
+	return i == 0 || !isWordRune(runes[i-1])
+}
+
+// flanking reports whether an emphasis delimiter of size n at i touches the
+// text it opens or closes, so that "2 * 3" is not read as emphasis
+func flanking(runes []rune, i, n int, closing bool) bool {
+	if closing {
+		return i > 0 && !unicode.IsSpace(runes[i-1])
+	}
+	return i+n < len(runes) && !unicode.IsSpace(runes[i+n])
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isEscapable(r rune) bool {
+	return strings.ContainsRune("\\`*_[]()~#>-+.!|", r)
+}
+
+func countRun(runes []rune, i int, r rune) int {
+	n := 0
+	for i+n < len(runes) && runes[i+n] == r {
+		n++
+	}
+	return n
+}
+
+// renderRichText converts rich text to inline Markdown. Items split at the
+// length limit are joined first so their formatting is not repeated.
+func renderRichText(items []models.NotionTextContent) string {
+	var merged []models.NotionTextContent
+	for _, item := range items {
+		if n := len(merged); n > 0 && merged[n-1].Annotations == item.Annotations && sameHref(merged[n-1].Href, item.Href) {
+			merged[n-1].PlainText += item.PlainText
+			continue
+		}
+		merged = append(merged, item)
+	}
+
+	var b strings.Builder
+	for _, item := range merged {
+		text := item.PlainText
+		if text == "" {
+			continue
+		}
+		annotations := item.Annotations
+		if annotations.Code {
+			fence := "`"
+			if strings.Contains(text, "`") {
+				fence = "`` "
+			}
+			text = fence + text + reverse(fence)
+		} else {
+			text = escape(text)
+		}
+		text = wrap(text, "~~", annotations.Strikethrough)
+		text = wrap(text, "*", annotations.Italic)
+		text = wrap(text, "**", annotations.Bold)
+		if item.Href != nil && *item.Href != "" {
+			text = "[" + text + "](" + *item.Href + ")"
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+func sameHref(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// wrap surrounds text with delim, keeping surrounding spaces outside it
+func wrap(text, delim string, on bool) string {
+	trimmed := strings.TrimSpace(text)
+	if !on || trimmed == "" {
+		return text
+	}
+	start := strings.Index(text, trimmed)
+	return text[:start] + delim + trimmed + delim + text[start+len(trimmed):]
+}
+
+// escape protects characters that would otherwise read as formatting
+func escape(text string) string {
+	var b strings.Builder
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case strings.ContainsRune("\\`*[~", r):
+			b.WriteRune('\\')
+		case r == '_' && (atWordBoundary(runes, i, 1, false) || atWordBoundary(runes, i, 1, true)):
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
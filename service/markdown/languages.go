@@ -0,0 +1,46 @@
+package markdown
+
+import "strings"
+
+// plainTextLanguage is Notion's language for code without highlighting
+const plainTextLanguage = "plain text"
+
+// codeLanguages are the code block languages Notion accepts
+var codeLanguages = map[string]bool{
+	"abap": true, "arduino": true, "bash": true, "basic": true, "c": true, "clojure": true,
+	"coffeescript": true, "c++": true, "c#": true, "css": true, "dart": true, "diff": true,
+	"docker": true, "elixir": true, "elm": true, "erlang": true, "flow": true, "fortran": true,
+	"f#": true, "gherkin": true, "glsl": true, "go": true, "graphql": true, "groovy": true,
+	"haskell": true, "html": true, "java": true, "javascript": true, "json": true, "julia": true,
+	"kotlin": true, "latex": true, "less": true, "lisp": true, "livescript": true, "lua": true,
+	"makefile": true, "markdown": true, "markup": true, "matlab": true, "mermaid": true,
+	"nix": true, "objective-c": true, "ocaml": true, "pascal": true, "perl": true, "php": true,
+	"plain text": true, "powershell": true, "prolog": true, "protobuf": true, "python": true,
+	"r": true, "reason": true, "ruby": true, "rust": true, "sass": true, "scala": true,
+	"scheme": true, "scss": true, "shell": true, "sql": true, "swift": true, "typescript": true,
+	"vb.net": true, "verilog": true, "vhdl": true, "visual basic": true, "webassembly": true,
+	"xml": true, "yaml": true,
+}
+
+// languageAliases maps common fence names to Notion languages
+var languageAliases = map[string]string{
+	"sh": "shell", "zsh": "shell", "console": "shell", "js": "javascript", "jsx": "javascript",
+	"ts": "typescript", "tsx": "typescript", "py": "python", "rb": "ruby", "rs": "rust",
+	"golang": "go", "yml": "yaml", "cpp": "c++", "cs": "c#", "csharp": "c#", "fsharp": "f#",
+	"dockerfile": "docker", "md": "markdown", "kt": "kotlin", "ps1": "powershell",
+	"objc": "objective-c", "make": "makefile", "text": plainTextLanguage, "txt": plainTextLanguage,
+	"proto": "protobuf", "tex": "latex",
+}
+
+// codeLanguage returns the Notion language for a fence name, falling back
+// to plain text for unknown languages
+func codeLanguage(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := languageAliases[name]; ok {
+		return alias
+	}
+	if codeLanguages[name] {
+		return name
+	}
+	return plainTextLanguage
+}
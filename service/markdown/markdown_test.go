@@ -0,0 +1,263 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+)
+
+// readBlocks converts write-shaped blocks to blocks as Notion reads them back
+func readBlocks(t *testing.T, blocks []models.BlockData) []models.NotionBlock {
+	t.Helper()
+	var read []models.NotionBlock
+	for _, block := range blocks {
+		kind, _ := block["type"].(string)
+		nb := models.NotionBlock{Object: "block", Type: kind}
+		content := block.Content()
+		if content == nil {
+			read = append(read, nb)
+			continue
+		}
+		text := &models.BlockText{Language: content.Language}
+		if content.Checked != nil {
+			text.Checked = *content.Checked
+		}
+		for _, item := range content.RichText {
+			var rt models.NotionTextContent
+			rt.Type = item.Type
+			rt.Text.Content = item.Text.Content
+			rt.Text.Link = item.Text.Link
+			rt.PlainText = item.Text.Content
+			if item.Annotations != nil {
+				rt.Annotations = *item.Annotations
+			}
+			if item.Text.Link != nil {
+				url := item.Text.Link.URL
+				rt.Href = &url
+			}
+			text.RichText = append(text.RichText, rt)
+		}
+		switch kind {
+		case models.BlockTypeParagraph:
+			nb.Paragraph = text
+		case models.BlockTypeHeading1:
+			nb.Heading1 = text
+		case models.BlockTypeHeading2:
+			nb.Heading2 = text
+		case models.BlockTypeHeading3:
+			nb.Heading3 = text
+		case models.BlockTypeToDo:
+			nb.ToDo = text
+		case models.BlockTypeBulletedListItem:
+			nb.BulletedListItem = text
+		case models.BlockTypeNumberedListItem:
+			nb.NumberedListItem = text
+		case models.BlockTypeQuote:
+			nb.Quote = text
+		case models.BlockTypeCode:
+			nb.Code = text
+		default:
+			t.Fatalf("unexpected block type %q", kind)
+		}
+		nb.Children = readBlocks(t, content.Children)
+		nb.HasChildren = len(nb.Children) > 0
+		read = append(read, nb)
+	}
+	return read
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string // Markdown rendered back, when it differs from the input
+		// spaces is set when spaces next to emphasis lose their formatting,
+		// which does not show in Notion
+		spaces bool
+	}{
+		{name: "paragraph", markdown: "Plain text\n"},
+		{name: "paragraphs", markdown: "First paragraph\n\nSecond paragraph\n"},
+		{name: "line breaks", markdown: "First line\nsecond line\n"},
+		{name: "headings", markdown: "# Title\n\n## Section\n\n### Detail\n"},
+		{name: "inline", markdown: "Some **bold**, *italic*, ~~struck~~ and `code` text\n"},
+		{name: "nested formatting", markdown: "**bold and *italic* inside**\n", want: "**bold and** ***italic*** **inside**\n", spaces: true},
+		{name: "link", markdown: "See [the docs](https://developers.notion.com) first\n"},
+		{name: "code with backtick", markdown: "Run `` a`b `` now\n"},
+		{name: "escapes", markdown: "Literal \\*stars\\*, \\[brackets\\] and \\`ticks\\`\n", want: "Literal \\*stars\\*, \\[brackets] and \\`ticks\\`\n"},
+		{name: "spaced stars", markdown: "2 \\* 3 \\* 4\n"},
+		{name: "snake case", markdown: "Call snake_case_name here\n"},
+		{name: "bullets", markdown: "- one\n- two\n- three\n"},
+		{name: "numbers", markdown: "1. first\n2. second\n3. third\n"},
+		{name: "tasks", markdown: "- [ ] open\n- [x] done\n"},
+		{name: "nested list", markdown: "- parent\n  - child\n    - grandchild\n- sibling\n"},
+		{name: "list item lines", markdown: "- first line\n  second line\n"},
+		{name: "quote", markdown: "> quoted\n> lines\n"},
+		{name: "code block", markdown: "```go\nfunc main() {\n\tprintln(\"*\")\n}\n```\n"},
+		{name: "indented code block", markdown: "- item\n\n  ```\n  \tindented\n  ```\n", want: "- item\n  ```\n  \tindented\n  ```\n"},
+		{name: "plain code block", markdown: "```\nno language\n```\n"},
+		{name: "divider", markdown: "Above\n\n---\n\nBelow\n"},
+		{name: "escaped list start", markdown: "\\- not a list\n"},
+		{name: "escaped number", markdown: "2025\\. was a year\n"},
+		{name: "escaped heading", markdown: "\\# not a heading\n"},
+		{name: "unicode", markdown: "Grüße **ünïcødé** 日本語\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want == "" {
+				want = tt.markdown
+			}
+			blocks := ToBlocks(tt.markdown)
+			got := Render(readBlocks(t, blocks))
+			if got != want {
+				t.Errorf("round trip of %q = %q, want %q", tt.markdown, got, want)
+			}
+			// What is rendered must read back as the same blocks
+			if again := ToBlocks(got); !tt.spaces && !reflect.DeepEqual(again, blocks) {
+				t.Errorf("%q reads back as different blocks than %q", got, tt.markdown)
+			}
+		})
+	}
+}
+
+func TestToBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string // Markdown rendered back, when it differs from the input
+	}{
+		{name: "star bullets", markdown: "* one\n+ two\n", want: "- one\n- two\n"},
+		{name: "underscores", markdown: "__bold__ and _italic_\n", want: "**bold** and *italic*\n"},
+		{name: "unclosed delimiters", markdown: "2 * 3 and **open\n", want: "2 \\* 3 and \\*\\*open\n"},
+		{name: "spaced delimiters", markdown: "a * b * c and ** d **\n", want: "a \\* b \\* c and \\*\\* d \\*\\*\n"},
+		{name: "numbering restarts", markdown: "3. three\n7. seven\n", want: "1. three\n2. seven\n"},
+		{name: "blank lines", markdown: "\n\nText\n\n\n", want: "Text\n"},
+		{name: "crlf", markdown: "One\r\nTwo\r\n", want: "One\nTwo\n"},
+		{name: "language alias", markdown: "```js\nx\n```\n", want: "```javascript\nx\n```\n"},
+		{name: "unknown language", markdown: "```brainfuck\nx\n```\n", want: "```\nx\n```\n"},
+		{name: "unclosed fence", markdown: "```\ncode to the end\n", want: "```\ncode to the end\n```\n"},
+		{name: "closing heading hashes", markdown: "## Title ##\n", want: "## Title\n"},
+		{
+			name:     "deep list",
+			markdown: "- a\n  - b\n    - c\n      - d\n",
+			// Notion takes two levels of nesting in one request
+			want: "- a\n  - b\n    - c\n    - d\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(readBlocks(t, ToBlocks(tt.markdown)))
+			if got != tt.want {
+				t.Errorf("ToBlocks(%q) renders as %q, want %q", tt.markdown, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRichTextLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantItems []int // Length of each item in UTF-16 code units
+		plain     bool  // Formatting dropped
+	}{
+		{name: "short", text: "short", wantItems: []int{5}},
+		{name: "at limit", text: strings.Repeat("a", consts.MAX_RICH_TEXT_LENGTH), wantItems: []int{2000}},
+		{name: "over limit", text: strings.Repeat("a", 4500), wantItems: []int{2000, 2000, 500}},
+		// The limit counts UTF-16 code units, not bytes
+		{name: "multibyte", text: strings.Repeat("é", 2500), wantItems: []int{2000, 500}},
+		// Emoji take a surrogate pair each, and a pair is never split
+		{name: "surrogate pairs", text: strings.Repeat("🎉", 1500), wantItems: []int{2000, 1000}},
+		{name: "surrogate pair at limit", text: strings.Repeat("a", 1999) + "🎉b", wantItems: []int{1999, 3}},
+		{name: "formatted", text: "**" + strings.Repeat("b", 2500) + "** tail", wantItems: []int{2000, 500, 5}},
+		{name: "too many runs", text: strings.Repeat("**b** ", 150), wantItems: []int{300}, plain: true},
+		// Adjacent bold runs merge, leaving one bold and 50 plain and italic runs
+		{name: "many runs", text: strings.Repeat("**b**", 50) + strings.Repeat("c*i*", 25), wantItems: append([]int{50}, ones(50)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := RichText(tt.text)
+			if len(items) != len(tt.wantItems) {
+				t.Fatalf("got %d items, want %d", len(items), len(tt.wantItems))
+			}
+			for i, item := range items {
+				if n := utf16Len(item.Text.Content); n != tt.wantItems[i] {
+					t.Errorf("item %d has %d code units, want %d", i, n, tt.wantItems[i])
+				}
+				if !utf8.ValidString(item.Text.Content) {
+					t.Errorf("item %d splits a character", i)
+				}
+				if tt.plain && item.Annotations != nil {
+					t.Errorf("item %d keeps its formatting %+v", i, *item.Annotations)
+				}
+			}
+		})
+	}
+}
+
+// utf16Len returns the length of s in UTF-16 code units, as Notion counts it
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// ones returns n lengths of one character
+func ones(n int) []int {
+	lengths := make([]int, n)
+	for i := range lengths {
+		lengths[i] = 1
+	}
+	return lengths
+}
+
+func TestBlockLimits(t *testing.T) {
+	// Long paragraphs and code are split into items, never into blocks
+	code := strings.Repeat("x", 4100)
+	blocks := ToBlocks("```\n" + code + "\n```\n\n" + strings.Repeat("y", 2001))
+	if len(blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2", len(blocks))
+	}
+	for i, want := range []int{3, 2} {
+		items := blocks[i].Content().RichText
+		if len(items) != want {
+			t.Errorf("block %d has %d rich text items, want %d", i, len(items), want)
+		}
+		for _, item := range items {
+			if n := utf16Len(item.Text.Content); n > consts.MAX_RICH_TEXT_LENGTH {
+				t.Errorf("block %d has an item of %d code units", i, n)
+			}
+		}
+	}
+
+	// Every list item is kept, however many; requests are split when appending
+	var list strings.Builder
+	list.WriteString("- parent\n")
+	for i := 0; i < 3*consts.MAX_BLOCK_CHILDREN; i++ {
+		list.WriteString("  - child\n")
+	}
+	for i := 0; i < consts.MAX_BLOCK_CHILDREN+50; i++ {
+		list.WriteString("- item\n")
+	}
+	blocks = ToBlocks(list.String())
+	if len(blocks) != consts.MAX_BLOCK_CHILDREN+51 {
+		t.Fatalf("got %d blocks, want %d", len(blocks), consts.MAX_BLOCK_CHILDREN+51)
+	}
+	if n := len(blocks[0].Content().Children); n != 3*consts.MAX_BLOCK_CHILDREN {
+		t.Errorf("parent has %d children, want %d", n, 3*consts.MAX_BLOCK_CHILDREN)
+	}
+
+	stripped, children := blocks[0].WithoutChildren()
+	if len(children) != 3*consts.MAX_BLOCK_CHILDREN || len(stripped.Content().Children) != 0 {
+		t.Errorf("WithoutChildren returned %d children and kept %d", len(children), len(stripped.Content().Children))
+	}
+	if len(blocks[0].Content().Children) != 3*consts.MAX_BLOCK_CHILDREN {
+		t.Errorf("WithoutChildren changed the original block")
+	}
+}
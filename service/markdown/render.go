@@ -0,0 +1,120 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+// Render converts page content to Markdown, the reverse of ToBlocks.
+// Nested blocks are indented under their parent; unsupported block types
+// are shown by type name.
+func Render(blocks []models.NotionBlock) string {
+	var b strings.Builder
+	render(&b, blocks, "")
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func render(b *strings.Builder, blocks []models.NotionBlock, indent string) {
+	number := 0
+	for i, block := range blocks {
+		if block.Type == models.BlockTypeNumberedListItem {
+			number++
+		} else {
+			number = 0
+		}
+		// Blank lines separate blocks, except between items of one list
+		if i > 0 && !(isListItem(block) && isListItem(blocks[i-1])) {
+			b.WriteString("\n")
+		}
+
+		text := ""
+		if content := block.Text(); content != nil {
+			text = renderRichText(content.RichText)
+		}
+		switch block.Type {
+		case models.BlockTypeParagraph:
+			writeLines(b, indent, "", escapeLineStart(text))
+		case models.BlockTypeHeading1:
+			writeLines(b, indent, "# ", text)
+		case models.BlockTypeHeading2:
+			writeLines(b, indent, "## ", text)
+		case models.BlockTypeHeading3:
+			writeLines(b, indent, "### ", text)
+		case models.BlockTypeToDo:
+			mark := "[ ]"
+			if block.ToDo != nil && block.ToDo.Checked {
+				mark = "[x]"
+			}
+			writeLines(b, indent, "- "+mark+" ", text)
+		case models.BlockTypeBulletedListItem:
+			writeLines(b, indent, "- ", text)
+		case models.BlockTypeNumberedListItem:
+			writeLines(b, indent, fmt.Sprintf("%d. ", number), text)
+		case models.BlockTypeQuote:
+			writeLines(b, indent, "> ", text)
+		case models.BlockTypeCode:
+			language, code := "", ""
+			if block.Code != nil {
+				language, code = block.Code.Language, block.Code.PlainText()
+			}
+			if language == plainTextLanguage {
+				language = ""
+			}
+			b.WriteString(indent + "```" + strings.ReplaceAll(language, " ", "") + "\n")
+			for _, line := range strings.Split(code, "\n") {
+				b.WriteString(indent + line + "\n")
+			}
+			b.WriteString(indent + "```\n")
+		case models.BlockTypeDivider:
+			b.WriteString(indent + "---\n")
+		default:
+			b.WriteString(indent + "[" + block.Type + "]\n")
+		}
+
+		if len(block.Children) > 0 {
+			if !isListItem(block) {
+				b.WriteString("\n")
+			}
+			render(b, block.Children, indent+"  ")
+		}
+	}
+}
+
+// writeLines writes text after prefix, aligning continuation lines with it
+// and escaping those that would start a new block
+func writeLines(b *strings.Builder, indent, prefix, text string) {
+	continuation := strings.Repeat(" ", len(prefix))
+	if strings.HasPrefix(prefix, ">") {
+		continuation = prefix
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if i == 0 {
+			b.WriteString(indent + prefix + line + "\n")
+		} else {
+			b.WriteString(indent + continuation + escapeLineStart(line) + "\n")
+		}
+	}
+}
+
+// escapeLineStart escapes a paragraph line that would otherwise read as the
+// start of a heading, list, quote, divider or code fence
+func escapeLineStart(line string) string {
+	if !isBlockStart(line) && !strings.HasPrefix(line, "#") {
+		return line
+	}
+	if match := numberedPattern.FindStringIndex(line); match != nil {
+		end := strings.IndexAny(line, ".)")
+		return line[:end] + "\\" + line[end:]
+	}
+	return "\\" + line
+}
+
+func isListItem(block models.NotionBlock) bool {
+	switch block.Type {
+	case models.BlockTypeToDo, models.BlockTypeBulletedListItem, models.BlockTypeNumberedListItem:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+)
+
+// maxBlockDepth limits how deep nested blocks are fetched
+const maxBlockDepth = 5
+
+// GetBlocks fetches the children of a page or block, following pagination
+// cursors and descending into nested blocks
+func (n *notionImpl) GetBlocks(ctx context.Context, blockID string) ([]models.NotionBlock, error) {
+	return n.getBlocks(ctx, blockID, 1, maxBlockDepth)
+}
+
+// ListSubTasks returns the top-level to_do blocks of a page, fetching only
+// the first level of content
+func (n *notionImpl) ListSubTasks(ctx context.Context, pageID string) ([]models.SubTask, error) {
+	blocks, err := n.getBlocks(ctx, pageID, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	return models.SubTasksOf(blocks), nil
+}
+
+// SetSubTaskChecked checks or unchecks a to_do block
+func (n *notionImpl) SetSubTaskChecked(ctx context.Context, blockID string, checked bool) error {
+	path := fmt.Sprintf("/blocks/%s", blockID)
+	payload := map[string]interface{}{
+		models.BlockTypeToDo: map[string]interface{}{"checked": checked},
+	}
+	// Setting a fixed value is idempotent, so it is safe to retry
+	_, err := n.doRequest(ctx, http.MethodPatch, path, payload, true)
+	return err
+}
+
+func (n *notionImpl) getBlocks(ctx context.Context, blockID string, depth, maxDepth int) ([]models.NotionBlock, error) {
+	var blocks []models.NotionBlock
+	cursor := ""
+	for {
+		query := url.Values{"page_size": {fmt.Sprint(consts.PAGE_SIZE)}}
+		if cursor != "" {
+			query.Set("start_cursor", cursor)
+		}
+		path := fmt.Sprintf("/blocks/%s/children?%s", blockID, query.Encode())
+		body, err := n.doRequest(ctx, http.MethodGet, path, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		var list models.NotionBlockList
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("failed to parse blocks: %v", err)
+		}
+		blocks = append(blocks, list.Results...)
+		if !list.HasMore || list.NextCursor == nil {
+			break
+		}
+		cursor = *list.NextCursor
+	}
+
+	if depth < maxDepth {
+		for i := range blocks {
+			if !blocks[i].HasChildren {
+				continue
+			}
+			children, err := n.getBlocks(ctx, blocks[i].ID, depth+1, maxDepth)
+			if err != nil {
+				return nil, err
+			}
+			blocks[i].Children = children
+		}
+	}
+	return blocks, nil
+}
+
+// AppendBlocks appends blocks to a page or block, at most
+// consts.MAX_BLOCK_CHILDREN per request. Blocks with more nested children
+// than a request takes are created empty and filled in afterwards.
+func (n *notionImpl) AppendBlocks(ctx context.Context, blockID string, blocks []models.BlockData) error {
+	path := fmt.Sprintf("/blocks/%s/children", blockID)
+	for len(blocks) > 0 {
+		chunk := blocks
+		if len(chunk) > consts.MAX_BLOCK_CHILDREN {
+			chunk = chunk[:consts.MAX_BLOCK_CHILDREN]
+		}
+		blocks = blocks[len(chunk):]
+
+		chunk = slices.Clone(chunk)
+		deferred := make([][]models.BlockData, len(chunk))
+		split := false
+		for i, block := range chunk {
+			if !fitsRequest(block) {
+				chunk[i], deferred[i] = block.WithoutChildren()
+				split = true
+			}
+		}
+
+		// Appending is not idempotent, so only rate limited attempts are retried
+		payload := map[string]interface{}{"children": chunk}
+		body, err := n.doRequest(ctx, http.MethodPatch, path, payload, false)
+		if err != nil {
+			return err
+		}
+		if !split {
+			continue
+		}
+
+		var created models.NotionBlockList
+		if err := json.Unmarshal(body, &created); err != nil {
+			return fmt.Errorf("failed to parse appended blocks: %v", err)
+		}
+		if len(created.Results) != len(chunk) {
+			return fmt.Errorf("expected %d appended blocks, got %d", len(chunk), len(created.Results))
+		}
+		for i, children := range deferred {
+			if len(children) == 0 {
+				continue
+			}
+			if err := n.AppendBlocks(ctx, created.Results[i].ID, children); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fitsRequest reports whether every list of nested children in block is
+// within consts.MAX_BLOCK_CHILDREN, so it can be sent in one request
+func fitsRequest(block models.BlockData) bool {
+	content := block.Content()
+	if content == nil {
+		return true
+	}
+	if len(content.Children) > consts.MAX_BLOCK_CHILDREN {
+		return false
+	}
+	for _, child := range content.Children {
+		if !fitsRequest(child) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+package notion
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/markdown"
+	"github.com/caffeines/notion-todo/service/notion/fakenotion"
+)
+
+// listMarkdown returns a bulleted list of items, the first of which has
+// children nested items
+func listMarkdown(items, children int) string {
+	var b strings.Builder
+	for i := 0; i < items; i++ {
+		b.WriteString("- item\n")
+		if i == 0 {
+			for j := 0; j < children; j++ {
+				b.WriteString("  - child\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// checkChildrenLimit fails when a request sent more blocks in one children
+// list than Notion accepts
+func checkChildrenLimit(t *testing.T, server *fakenotion.Server) {
+	t.Helper()
+	var check func(children []interface{})
+	check = func(children []interface{}) {
+		if len(children) > consts.MAX_BLOCK_CHILDREN {
+			t.Errorf("a request sent %d children", len(children))
+		}
+		for _, child := range children {
+			block, _ := child.(map[string]interface{})
+			kind, _ := block["type"].(string)
+			content, _ := block[kind].(map[string]interface{})
+			nested, _ := content["children"].([]interface{})
+			check(nested)
+		}
+	}
+	for _, request := range server.Requests() {
+		children, _ := request.Body["children"].([]interface{})
+		check(children)
+	}
+}
+
+func TestAppendBlocks(t *testing.T) {
+	tests := []struct {
+		name            string
+		items, children int
+	}{
+		{name: "few", items: 3, children: 2},
+		{name: "at limit", items: consts.MAX_BLOCK_CHILDREN, children: consts.MAX_BLOCK_CHILDREN},
+		{name: "many items", items: 2*consts.MAX_BLOCK_CHILDREN + 50, children: 0},
+		{name: "many nested", items: 1, children: consts.MAX_BLOCK_CHILDREN + 20},
+		{name: "both", items: consts.MAX_BLOCK_CHILDREN + 1, children: 3*consts.MAX_BLOCK_CHILDREN + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newTestClient(t, fakenotion.TodoSchema())
+			ctx := context.Background()
+			pageID := addPage(t, server, "Notes", nil)
+
+			if err := client.AppendBlocks(ctx, pageID, markdown.ToBlocks(listMarkdown(tt.items, tt.children))); err != nil {
+				t.Fatalf("AppendBlocks: %v", err)
+			}
+			checkChildrenLimit(t, server)
+
+			blocks, err := client.GetBlocks(ctx, pageID)
+			if err != nil {
+				t.Fatalf("GetBlocks: %v", err)
+			}
+			if len(blocks) != tt.items {
+				t.Fatalf("page has %d blocks, want %d", len(blocks), tt.items)
+			}
+			if n := len(blocks[0].Children); n != tt.children {
+				t.Errorf("first item has %d children, want %d", n, tt.children)
+			}
+			for _, block := range blocks {
+				if block.Type != models.BlockTypeBulletedListItem {
+					t.Fatalf("block type %q, want %q", block.Type, models.BlockTypeBulletedListItem)
+				}
+			}
+		})
+	}
+}
+
+func TestAddPageContent(t *testing.T) {
+	server, client := newTestClient(t, fakenotion.TodoSchema())
+	ctx := context.Background()
+
+	body := markdown.ToBlocks(listMarkdown(consts.MAX_BLOCK_CHILDREN+30, consts.MAX_BLOCK_CHILDREN+5) +
+		"\n" + strings.Repeat("x", 2*consts.MAX_RICH_TEXT_LENGTH+1))
+	if err := client.AddPage(ctx, models.NewTodo{Title: "Long note"}, body); err != nil {
+		t.Fatalf("AddPage: %v", err)
+	}
+	checkChildrenLimit(t, server)
+
+	todos, err := client.QueryPages(ctx, models.TodoFilter{})
+	if err != nil || len(todos) != 1 {
+		t.Fatalf("QueryPages = %d todos, %v", len(todos), err)
+	}
+	blocks, err := client.GetBlocks(ctx, todos[0].ID)
+	if err != nil {
+		t.Fatalf("GetBlocks: %v", err)
+	}
+	if len(blocks) != consts.MAX_BLOCK_CHILDREN+31 {
+		t.Fatalf("page has %d blocks, want %d", len(blocks), consts.MAX_BLOCK_CHILDREN+31)
+	}
+	if n := len(blocks[0].Children); n != consts.MAX_BLOCK_CHILDREN+5 {
+		t.Errorf("first item has %d children, want %d", n, consts.MAX_BLOCK_CHILDREN+5)
+	}
+	last := blocks[len(blocks)-1]
+	if last.Paragraph == nil || len(last.Paragraph.RichText) != 3 || len(last.Paragraph.PlainText()) != 2*consts.MAX_RICH_TEXT_LENGTH+1 {
+		t.Errorf("long paragraph was not kept whole: %+v", last.Paragraph)
+	}
+}
+
+func TestSubTasks(t *testing.T) {
+	server, client := newTestClient(t, fakenotion.TodoSchema())
+	ctx := context.Background()
+
+	body := markdown.ToBlocks("Before the trip\n\n- [ ] Book flights\n- [x] Renew passport\n  - [ ] Take photos\n- Pack light\n")
+	if err := client.AddPage(ctx, models.NewTodo{Title: "Trip"}, body); err != nil {
+		t.Fatalf("AddPage: %v", err)
+	}
+	todos, err := client.QueryPages(ctx, models.TodoFilter{})
+	if err != nil || len(todos) != 1 {
+		t.Fatalf("QueryPages = %d todos, %v", len(todos), err)
+	}
+	pageID := todos[0].ID
+
+	// Only top-level to_do blocks are sub-tasks
+	subTasks, err := client.ListSubTasks(ctx, pageID)
+	if err != nil {
+		t.Fatalf("ListSubTasks: %v", err)
+	}
+	if len(subTasks) != 2 || subTasks[0].Text != "Book flights" || subTasks[0].Checked || subTasks[1].Text != "Renew passport" || !subTasks[1].Checked {
+		t.Fatalf("ListSubTasks() = %+v", subTasks)
+	}
+	if done, total := models.SubTaskProgress(subTasks); done != 1 || total != 2 {
+		t.Errorf("SubTaskProgress() = %d/%d, want 1/2", done, total)
+	}
+
+	if err := client.SetSubTaskChecked(ctx, subTasks[0].ID, true); err != nil {
+		t.Fatalf("SetSubTaskChecked: %v", err)
+	}
+	updates := requestsTo(server, http.MethodPatch, "/blocks/"+subTasks[0].ID)
+	if len(updates) != 1 {
+		t.Fatalf("sent %d block updates, want 1", len(updates))
+	}
+	assertJSON(t, updates[0].Body, rawJSON(t, `{"to_do":{"checked":true}}`))
+
+	subTasks, err = client.ListSubTasks(ctx, pageID)
+	if err != nil {
+		t.Fatalf("ListSubTasks: %v", err)
+	}
+	if done, total := models.SubTaskProgress(subTasks); done != 2 || total != 2 {
+		t.Errorf("SubTaskProgress() after checking = %d/%d, want 2/2", done, total)
+	}
+}
@@ -0,0 +1,542 @@
+package notion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/notion/fakenotion"
+)
+
+func TestPagination(t *testing.T) {
+	server, client := newTestClient(t, fakenotion.TodoSchema())
+	ctx := context.Background()
+	const total = 2*consts.PAGE_SIZE + 50
+	for i := 0; i < total; i++ {
+		addPage(t, server, fmt.Sprintf("Todo %03d", i), nil)
+	}
+	queryPath := "/databases/" + testDatabaseID + "/query"
+
+	t.Run("QueryPages follows cursors", func(t *testing.T) {
+		before := len(requestsTo(server, http.MethodPost, queryPath))
+		todos, err := client.QueryPages(ctx, models.TodoFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(todos) != total {
+			t.Fatalf("got %d todos, want %d", len(todos), total)
+		}
+		seen := map[string]bool{}
+		for _, todo := range todos {
+			if seen[todo.ID] {
+				t.Fatalf("todo %s returned twice", todo.ID)
+			}
+			seen[todo.ID] = true
+		}
+
+		requests := requestsTo(server, http.MethodPost, queryPath)[before:]
+		if len(requests) != 3 {
+			t.Fatalf("sent %d queries, want 3", len(requests))
+		}
+		for i, request := range requests {
+			cursor, _ := request.Body["start_cursor"].(string)
+			if (i == 0) != (cursor == "") {
+				t.Errorf("query %d has start_cursor %q", i, cursor)
+			}
+			if size, _ := request.Body["page_size"].(float64); int(size) != consts.PAGE_SIZE {
+				t.Errorf("query %d has page_size %v, want %d", i, request.Body["page_size"], consts.PAGE_SIZE)
+			}
+		}
+	})
+
+	t.Run("QueryPagesCursor returns one page", func(t *testing.T) {
+		cursor := ""
+		var sizes []int
+		for {
+			page, err := client.QueryPagesCursor(ctx, models.TodoFilter{}, cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sizes = append(sizes, len(page.Todos))
+			if page.HasMore != (page.NextCursor != "") {
+				t.Fatalf("HasMore is %v with next cursor %q", page.HasMore, page.NextCursor)
+			}
+			if !page.HasMore {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if fmt.Sprint(sizes) != "[100 100 50]" {
+			t.Errorf("page sizes %v, want [100 100 50]", sizes)
+		}
+	})
+
+	t.Run("IteratePages stops early", func(t *testing.T) {
+		before := len(requestsTo(server, http.MethodPost, queryPath))
+		seen := 0
+		err := client.IteratePages(ctx, models.TodoFilter{}, func(todo models.TodoItem) bool {
+			seen++
+			return seen < consts.PAGE_SIZE+20
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen != consts.PAGE_SIZE+20 {
+			t.Errorf("saw %d todos, want %d", seen, consts.PAGE_SIZE+20)
+		}
+		if n := len(requestsTo(server, http.MethodPost, queryPath)) - before; n != 2 {
+			t.Errorf("sent %d queries, want 2", n)
+		}
+	})
+}
+
+func TestRetries(t *testing.T) {
+	query := func(ctx context.Context, client *notionImpl) error {
+		_, err := client.QueryPages(ctx, models.TodoFilter{})
+		return err
+	}
+	create := func(ctx context.Context, client *notionImpl) error {
+		return client.AddPage(ctx, models.NewTodo{Title: "New"}, nil)
+	}
+	queryPath := "/databases/" + testDatabaseID + "/query"
+
+	tests := []struct {
+		name       string
+		maxRetries string
+		fail       func(server *fakenotion.Server)
+		call       func(ctx context.Context, client *notionImpl) error
+		method     string
+		path       string
+		// wantRequests counts the requests sent to path
+		wantRequests int
+		// wantWaits are the waits between attempts, nil when they are random
+		wantWaits []time.Duration
+		wantErr   func(error) bool
+	}{
+		{
+			name:         "rate limited query waits for Retry-After",
+			fail:         func(s *fakenotion.Server) { s.RateLimit(2, 2) },
+			call:         query,
+			method:       http.MethodPost,
+			path:         queryPath,
+			wantRequests: 3,
+			wantWaits:    []time.Duration{2 * time.Second, 2 * time.Second},
+		},
+		{
+			name:         "Retry-After is capped",
+			fail:         func(s *fakenotion.Server) { s.RateLimit(3600, 1) },
+			call:         query,
+			method:       http.MethodPost,
+			path:         queryPath,
+			wantRequests: 2,
+			wantWaits:    []time.Duration{consts.RETRY_MAX_DELAY},
+		},
+		{
+			name:         "rate limited create is retried",
+			fail:         func(s *fakenotion.Server) { s.RateLimit(0, 1) },
+			call:         create,
+			method:       http.MethodPost,
+			path:         "/pages",
+			wantRequests: 2,
+			wantWaits:    []time.Duration{0},
+		},
+		{
+			name: "unavailable query is retried",
+			fail: func(s *fakenotion.Server) {
+				s.Fail(http.StatusServiceUnavailable, CodeServiceUnavailable, "Notion is unavailable.", 2)
+			},
+			call:         query,
+			method:       http.MethodPost,
+			path:         queryPath,
+			wantRequests: 3,
+		},
+		{
+			name: "failed create is not retried",
+			fail: func(s *fakenotion.Server) {
+				s.Fail(http.StatusInternalServerError, CodeInternalServer, "Unexpected error.", 1)
+			},
+			call:         create,
+			method:       http.MethodPost,
+			path:         "/pages",
+			wantRequests: 1,
+			wantWaits:    []time.Duration{},
+			wantErr:      IsUnavailable,
+		},
+		{
+			name: "validation error is not retried",
+			fail: func(s *fakenotion.Server) {
+				s.Fail(http.StatusBadRequest, CodeValidation, "body failed validation.", 1)
+			},
+			call:         query,
+			method:       http.MethodPost,
+			path:         queryPath,
+			wantRequests: 1,
+			wantWaits:    []time.Duration{},
+			wantErr:      IsValidation,
+		},
+		{
+			name:         "retries run out",
+			maxRetries:   "1",
+			fail:         func(s *fakenotion.Server) { s.RateLimit(1, 3) },
+			call:         query,
+			method:       http.MethodPost,
+			path:         queryPath,
+			wantRequests: 2,
+			wantWaits:    []time.Duration{time.Second},
+			wantErr:      IsRateLimited,
+		},
+		{
+			name:         "retries disabled",
+			maxRetries:   "-1",
+			fail:         func(s *fakenotion.Server) { s.RateLimit(0, 1) },
+			call:         query,
+			method:       http.MethodPost,
+			path:         queryPath,
+			wantRequests: 1,
+			wantWaits:    []time.Duration{},
+			wantErr:      IsRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newTestClient(t, fakenotion.TodoSchema())
+			ctx := context.Background()
+			if tt.maxRetries != "" {
+				t.Setenv(consts.EnvMaxRetries, tt.maxRetries)
+			}
+			waits := []time.Duration{}
+			client.transport.wait = func(ctx context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+			before := len(requestsTo(server, tt.method, tt.path))
+			tt.fail(server)
+
+			err := tt.call(ctx, client)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !tt.wantErr(err) {
+				t.Fatalf("error = %v", err)
+			}
+			if n := len(requestsTo(server, tt.method, tt.path)) - before; n != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", n, tt.wantRequests)
+			}
+			if tt.wantWaits != nil && fmt.Sprint(waits) != fmt.Sprint(tt.wantWaits) {
+				t.Errorf("waited %v, want %v", waits, tt.wantWaits)
+			}
+			if tt.wantWaits == nil && len(waits) != tt.wantRequests-1 {
+				t.Errorf("waited %d times, want %d", len(waits), tt.wantRequests-1)
+			}
+		})
+	}
+
+	t.Run("created once after rate limiting", func(t *testing.T) {
+		server, client := newTestClient(t, fakenotion.TodoSchema())
+		ctx := context.Background()
+		server.RateLimit(0, 2)
+		if err := create(ctx, client); err != nil {
+			t.Fatal(err)
+		}
+		todos, err := client.QueryPages(ctx, models.TodoFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(todos) != 1 {
+			t.Errorf("created %d todos, want 1", len(todos))
+		}
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		server, client := newTestClient(t, fakenotion.TodoSchema())
+		ctx, cancel := context.WithCancel(context.Background())
+		client.transport.wait = func(ctx context.Context, d time.Duration) error {
+			cancel()
+			return wait(ctx, d)
+		}
+		server.RateLimit(30, 1)
+		if err := query(ctx, client); err != context.Canceled {
+			t.Errorf("error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+func TestStatusPayloads(t *testing.T) {
+	statusSchema := map[string]map[string]interface{}{
+		"Name": {"type": "title"},
+		"Stage": {"type": "status", "status": map[string]interface{}{
+			"options": []interface{}{
+				map[string]interface{}{"name": "Not started", "group": "To-do"},
+				map[string]interface{}{"name": "Doing", "group": "In progress"},
+				map[string]interface{}{"name": "Shipped", "group": "Complete"},
+			},
+		}},
+	}
+	checkboxSchema := map[string]map[string]interface{}{
+		"Title":     {"type": "title"},
+		"Completed": {"type": "checkbox"},
+	}
+
+	tests := []struct {
+		name   string
+		schema map[string]map[string]interface{}
+		// property is the status property and its initial value as sent
+		// when creating a todo
+		property string
+		created  interface{}
+		// done is the status set, sent as doneValue
+		done      string
+		doneValue interface{}
+		// doneFilter is the query condition for the done status
+		doneFilter interface{}
+		wantStatus string
+	}{
+		{
+			name:       "select",
+			schema:     fakenotion.TodoSchema(),
+			property:   "Status",
+			created:    map[string]interface{}{"select": map[string]interface{}{"name": "Todo"}},
+			done:       "Done",
+			doneValue:  map[string]interface{}{"select": map[string]interface{}{"name": "Done"}},
+			doneFilter: map[string]interface{}{"property": "Status", "select": map[string]interface{}{"equals": "Done"}},
+			wantStatus: "Done",
+		},
+		{
+			name:       "status",
+			schema:     statusSchema,
+			property:   "Stage",
+			created:    map[string]interface{}{"status": map[string]interface{}{"name": "Not started"}},
+			done:       "Shipped",
+			doneValue:  map[string]interface{}{"status": map[string]interface{}{"name": "Shipped"}},
+			doneFilter: map[string]interface{}{"property": "Stage", "status": map[string]interface{}{"equals": "Shipped"}},
+			wantStatus: "Shipped",
+		},
+		{
+			name:       "checkbox",
+			schema:     checkboxSchema,
+			property:   "Completed",
+			created:    map[string]interface{}{"checkbox": false},
+			done:       models.CheckboxStatusDone,
+			doneValue:  map[string]interface{}{"checkbox": true},
+			doneFilter: map[string]interface{}{"property": "Completed", "checkbox": map[string]interface{}{"equals": true}},
+			wantStatus: models.CheckboxStatusDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newTestClient(t, tt.schema)
+			ctx := context.Background()
+
+			if err := client.AddPage(ctx, models.NewTodo{Title: "Ship it"}, nil); err != nil {
+				t.Fatalf("AddPage: %v", err)
+			}
+			creates := requestsTo(server, http.MethodPost, "/pages")
+			properties, _ := creates[len(creates)-1].Body["properties"].(map[string]interface{})
+			assertJSON(t, properties[tt.property], tt.created)
+
+			other := models.NewTodo{Title: "Still open"}
+			if err := client.AddPage(ctx, other, nil); err != nil {
+				t.Fatalf("AddPage: %v", err)
+			}
+			todos, err := client.QueryPages(ctx, models.TodoFilter{TitleContains: "Ship"})
+			if err != nil || len(todos) != 1 {
+				t.Fatalf("QueryPages = %d todos, %v", len(todos), err)
+			}
+			id := todos[0].ID
+
+			if err := client.UpdatePageStatus(ctx, id, tt.done); err != nil {
+				t.Fatalf("UpdatePageStatus: %v", err)
+			}
+			updates := requestsTo(server, http.MethodPatch, "/pages/"+id)
+			properties, _ = updates[len(updates)-1].Body["properties"].(map[string]interface{})
+			assertJSON(t, properties, map[string]interface{}{tt.property: tt.doneValue})
+
+			todo, err := client.GetPage(ctx, id)
+			if err != nil {
+				t.Fatalf("GetPage: %v", err)
+			}
+			if todo.Status != tt.wantStatus || todo.StatusGroup != models.StatusGroupComplete {
+				t.Errorf("status read back as %q in group %q, want %q in %q", todo.Status, todo.StatusGroup, tt.wantStatus, models.StatusGroupComplete)
+			}
+
+			done, err := client.QueryPages(ctx, models.TodoFilter{Statuses: []string{tt.done}})
+			if err != nil {
+				t.Fatalf("QueryPages: %v", err)
+			}
+			if len(done) != 1 || done[0].ID != id {
+				t.Errorf("status filter matched %d todos, want the updated one", len(done))
+			}
+			queries := requestsTo(server, http.MethodPost, "/query")
+			filter, _ := queries[len(queries)-1].Body["filter"].(map[string]interface{})
+			assertJSON(t, filter, map[string]interface{}{"and": []interface{}{tt.doneFilter}})
+		})
+	}
+}
+
+func TestUpdatePage(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name   string
+		update models.PageUpdate
+		// want is the JSON of the properties sent, or wantErr part of the
+		// error when no request is made
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "title and status",
+			update: models.PageUpdate{Title: str("Buy oat milk"), Status: str("In Progress")},
+			want:   `{"Title":{"title":[{"text":{"content":"Buy oat milk"}}]},"Status":{"select":{"name":"In Progress"}}}`,
+		},
+		{
+			name: "date range in a zone",
+			update: models.PageUpdate{DueDate: &models.DateValue{
+				Start:    str("2025-06-10T09:00:00"),
+				End:      str("2025-06-10T17:00:00"),
+				TimeZone: str("Europe/Berlin"),
+			}},
+			want: `{"Due Date":{"date":{"start":"2025-06-10T09:00:00","end":"2025-06-10T17:00:00","time_zone":"Europe/Berlin"}}}`,
+		},
+		{
+			name:   "clear date",
+			update: models.PageUpdate{ClearDueDate: true},
+			want:   `{"Due Date":{"date":null}}`,
+		},
+		{name: "nothing", update: models.PageUpdate{}, wantErr: "nothing to update"},
+		{name: "empty title", update: models.PageUpdate{Title: str("  ")}, wantErr: "title cannot be empty"},
+		{
+			name:    "set and clear date",
+			update:  models.PageUpdate{DueDate: &models.DateValue{Start: str("2025-06-10")}, ClearDueDate: true},
+			wantErr: "cannot both set and clear",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newTestClient(t, fakenotion.TodoSchema())
+			id := addPage(t, server, "Buy milk", map[string]interface{}{
+				"Due Date": map[string]interface{}{"date": map[string]interface{}{"start": "2025-06-01"}},
+			})
+
+			err := client.UpdatePage(context.Background(), id, tt.update)
+			updates := requestsTo(server, http.MethodPatch, "/pages/"+id)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("UpdatePage() error = %v, want %q", err, tt.wantErr)
+				}
+				if len(updates) != 0 {
+					t.Errorf("sent %d updates, want none", len(updates))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdatePage() error = %v", err)
+			}
+			if len(updates) != 1 {
+				t.Fatalf("sent %d updates, want 1", len(updates))
+			}
+			assertJSON(t, updates[0].Body["properties"], rawJSON(t, tt.want))
+		})
+	}
+
+	// The page reads back with the changes
+	server, client := newTestClient(t, fakenotion.TodoSchema())
+	id := addPage(t, server, "Buy milk", nil)
+	update := models.PageUpdate{Title: str("Buy oat milk"), DueDate: &models.DateValue{Start: str("2025-06-10"), End: str("2025-06-12")}}
+	if err := client.UpdatePage(context.Background(), id, update); err != nil {
+		t.Fatal(err)
+	}
+	todo, err := client.GetPage(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo.Title != "Buy oat milk" || todo.DueDate == nil || *todo.DueDate != "2025-06-10" || todo.DueDateEnd == nil || *todo.DueDateEnd != "2025-06-12" {
+		t.Errorf("read back %q due %v to %v", todo.Title, todo.DueDate, todo.DueDateEnd)
+	}
+}
+
+func TestOptionalProperties(t *testing.T) {
+	schema := fakenotion.TodoSchema()
+	schema["Priority"] = map[string]interface{}{"type": "select", "select": map[string]interface{}{
+		"options": []interface{}{
+			map[string]interface{}{"name": "High", "color": "red"},
+			map[string]interface{}{"name": "Low", "color": "gray"},
+		},
+	}}
+	schema["Labels"] = map[string]interface{}{"type": "multi_select"}
+	schema["Owner"] = map[string]interface{}{"type": "people"}
+	server, client := newTestClient(t, schema)
+	server.AddUser("6a1f0c2e-0000-4000-8000-000000000001", "Ada Lovelace", "ada@example.com")
+	server.AddUser("6a1f0c2e-0000-4000-8000-000000000002", "Grace Hopper", "grace@example.com")
+	ctx := context.Background()
+
+	config, err := client.credentialService.GetSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapping := config.Properties
+	if mapping.Priority != "Priority" || mapping.Tags != "Labels" || mapping.Assignee != "Owner" || len(mapping.PriorityOptions) != 2 {
+		t.Fatalf("discovered %+v", mapping)
+	}
+
+	todo := models.NewTodo{Title: "Ship it", Priority: "high", Tags: []string{"release"}, Assignees: []string{"ada"}}
+	if err := client.AddPage(ctx, todo, nil); err != nil {
+		t.Fatalf("AddPage: %v", err)
+	}
+	creates := requestsTo(server, http.MethodPost, "/pages")
+	properties, _ := creates[len(creates)-1].Body["properties"].(map[string]interface{})
+	assertJSON(t, map[string]interface{}{
+		"Priority": properties["Priority"],
+		"Labels":   properties["Labels"],
+		"Owner":    properties["Owner"],
+	}, rawJSON(t, `{
+		"Priority": {"select": {"name": "High"}},
+		"Labels": {"multi_select": [{"name": "release"}]},
+		"Owner": {"people": [{"object": "user", "id": "6a1f0c2e-0000-4000-8000-000000000001"}]}
+	}`))
+
+	todos, err := client.QueryPages(ctx, models.TodoFilter{})
+	if err != nil || len(todos) != 1 {
+		t.Fatalf("QueryPages = %d todos, %v", len(todos), err)
+	}
+	got := todos[0]
+	if got.Priority == nil || *got.Priority != "High" || got.PriorityColor != "red" {
+		t.Errorf("priority read back as %v", got.Priority)
+	}
+	if strings.Join(got.Tags, ",") != "release" || strings.Join(got.Assignees, ",") != "Ada Lovelace" {
+		t.Errorf("read back tags %v and assignees %v", got.Tags, got.Assignees)
+	}
+
+	none := ""
+	update := models.PageUpdate{Priority: &none, Tags: &[]string{}, Assignees: &[]string{"grace@example.com"}}
+	if err := client.UpdatePage(ctx, got.ID, update); err != nil {
+		t.Fatalf("UpdatePage: %v", err)
+	}
+	updates := requestsTo(server, http.MethodPatch, "/pages/"+got.ID)
+	assertJSON(t, updates[len(updates)-1].Body["properties"], rawJSON(t, `{
+		"Priority": {"select": null},
+		"Labels": {"multi_select": []},
+		"Owner": {"people": [{"object": "user", "id": "6a1f0c2e-0000-4000-8000-000000000002"}]}
+	}`))
+
+	// Unknown options and users fail before anything is sent
+	before := len(server.Requests())
+	if err := client.AddPage(ctx, models.NewTodo{Title: "Later", Priority: "urgent"}, nil); err == nil || !strings.Contains(err.Error(), `unknown priority "urgent"`) {
+		t.Errorf("AddPage() with an unknown priority error = %v", err)
+	}
+	if err := client.UpdatePage(ctx, got.ID, models.PageUpdate{Assignees: &[]string{"linus"}}); err == nil || !strings.Contains(err.Error(), `no user matches "linus"`) {
+		t.Errorf("UpdatePage() with an unknown user error = %v", err)
+	}
+	for _, request := range server.Requests()[before:] {
+		if request.Method != http.MethodGet {
+			t.Errorf("sent %s %s", request.Method, request.Path)
+		}
+	}
+}
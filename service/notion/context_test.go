@@ -0,0 +1,93 @@
+package notion
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+)
+
+// newHangingClient returns a client for a server that answers no request,
+// and a channel receiving a value as each request arrives
+func newHangingClient(t *testing.T) (Notion, <-chan struct{}) {
+	t.Helper()
+	arrived := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server notices the client going away once the body is read
+		io.Copy(io.Discard, r.Body)
+		arrived <- struct{}{}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv(consts.EnvToken, "secret_test")
+	t.Setenv(consts.EnvDatabaseID, testDatabaseID)
+	t.Setenv(consts.EnvAPIURL, server.URL)
+	return NewNotionSvc(config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))), arrived
+}
+
+func TestCancellation(t *testing.T) {
+	calls := map[string]func(ctx context.Context, client Notion) error{
+		"QueryPages": func(ctx context.Context, client Notion) error {
+			_, err := client.QueryPages(ctx, models.TodoFilter{})
+			return err
+		},
+		"AddPage": func(ctx context.Context, client Notion) error {
+			return client.AddPage(ctx, models.NewTodo{Title: "New"}, nil)
+		},
+		"GetPage": func(ctx context.Context, client Notion) error {
+			_, err := client.GetPage(ctx, "5e6f708192a3b4c5d6e7f8091a2b3c4d")
+			return err
+		},
+		"UpdatePageStatus": func(ctx context.Context, client Notion) error {
+			return client.UpdatePageStatus(ctx, "5e6f708192a3b4c5d6e7f8091a2b3c4d", "Done")
+		},
+		"GetBlocks": func(ctx context.Context, client Notion) error {
+			_, err := client.GetBlocks(ctx, "5e6f708192a3b4c5d6e7f8091a2b3c4d")
+			return err
+		},
+		"ListUsers": func(ctx context.Context, client Notion) error {
+			_, err := client.ListUsers(ctx)
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name+" cancelled", func(t *testing.T) {
+			client, arrived := newHangingClient(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				<-arrived
+				cancel()
+			}()
+			if err := call(ctx, client); !errors.Is(err, context.Canceled) {
+				t.Errorf("error = %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+
+	t.Run("deadline", func(t *testing.T) {
+		client, _ := newHangingClient(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if err := calls["QueryPages"](ctx, client); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("returned after %v", elapsed)
+		}
+	})
+}
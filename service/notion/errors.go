@@ -0,0 +1,142 @@
+package notion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Error codes returned in Notion's error object
+const (
+	CodeInvalidJSON        = "invalid_json"
+	CodeInvalidRequestURL  = "invalid_request_url"
+	CodeInvalidRequest     = "invalid_request"
+	CodeValidation         = "validation_error"
+	CodeMissingVersion     = "missing_version"
+	CodeUnauthorized       = "unauthorized"
+	CodeRestrictedResource = "restricted_resource"
+	CodeObjectNotFound     = "object_not_found"
+	CodeConflict           = "conflict_error"
+	CodeRateLimited        = "rate_limited"
+	CodeInternalServer     = "internal_server_error"
+	CodeServiceUnavailable = "service_unavailable"
+)
+
+// APIError is the structured error object returned by the Notion API
+type APIError struct {
+	Status    int    `json:"status"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("notion API error (status %d)", e.Status)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// PartialPageError reports a page that was created, but whose content
+// beyond the first request could not be appended
+type PartialPageError struct {
+	PageID string
+	URL    string
+	Err    error
+}
+
+func (e *PartialPageError) Error() string {
+	return fmt.Sprintf("page %s was created, but adding the rest of its content failed: %v", e.PageID, e.Err)
+}
+
+func (e *PartialPageError) Unwrap() error {
+	return e.Err
+}
+
+// newAPIError decodes Notion's error object, keeping the raw body as the
+// message when the response is not a Notion error
+func newAPIError(status int, body []byte) *APIError {
+	apiErr := &APIError{}
+	if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Code == "" {
+		apiErr = &APIError{Message: strings.TrimSpace(string(body))}
+	}
+	apiErr.Status = status
+	return apiErr
+}
+
+// AsAPIError returns the APIError wrapped by err, if any
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsUnauthorized reports whether the token was rejected or lacks access
+func IsUnauthorized(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && (apiErr.Code == CodeUnauthorized ||
+		apiErr.Code == CodeRestrictedResource ||
+		apiErr.Status == http.StatusUnauthorized ||
+		apiErr.Status == http.StatusForbidden)
+}
+
+// IsNotFound reports whether the object does not exist or is not shared
+// with the integration
+func IsNotFound(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && (apiErr.Code == CodeObjectNotFound || apiErr.Status == http.StatusNotFound)
+}
+
+// NotFoundObject returns the kind of object a not found error is about:
+// database, page or block, or an empty string when it is not known
+func NotFoundObject(err error) string {
+	apiErr, ok := AsAPIError(err)
+	if !ok || !IsNotFound(err) {
+		return ""
+	}
+	// Notion words these errors as "Could not find page with ID: ..."
+	message := strings.ToLower(apiErr.Message)
+	for _, object := range []string{"database", "page", "block"} {
+		if strings.Contains(message, "could not find "+object) {
+			return object
+		}
+	}
+	return ""
+}
+
+// IsRateLimited reports whether Notion rate limited the request
+func IsRateLimited(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && (apiErr.Code == CodeRateLimited || apiErr.Status == http.StatusTooManyRequests)
+}
+
+// IsValidation reports whether Notion rejected the request payload
+func IsValidation(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		return false
+	}
+	switch apiErr.Code {
+	case CodeValidation, CodeInvalidJSON, CodeInvalidRequest, CodeInvalidRequestURL, CodeMissingVersion:
+		return true
+	}
+	return apiErr.Status == http.StatusBadRequest
+}
+
+// IsUnavailable reports whether Notion failed on its side or could not be reached
+func IsUnavailable(err error) bool {
+	if apiErr, ok := AsAPIError(err); ok {
+		return apiErr.Status >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
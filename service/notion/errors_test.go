@@ -0,0 +1,127 @@
+package notion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/caffeines/notion-todo/service/notion/fakenotion"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   APIError
+		text   string
+	}{
+		{
+			name:   "notion error",
+			status: http.StatusNotFound,
+			body:   `{"object":"error","status":404,"code":"object_not_found","message":"Could not find database with ID: 0f1e.","request_id":"req-1"}`,
+			want:   APIError{Status: 404, Code: CodeObjectNotFound, Message: "Could not find database with ID: 0f1e.", RequestID: "req-1"},
+			text:   "notion API error (status 404) object_not_found: Could not find database with ID: 0f1e.",
+		},
+		{
+			// The status of the response wins over the one in the body
+			name:   "status from response",
+			status: http.StatusBadGateway,
+			body:   `{"status":200,"code":"internal_server_error","message":"Unexpected error."}`,
+			want:   APIError{Status: 502, Code: CodeInternalServer, Message: "Unexpected error."},
+		},
+		{
+			name:   "not JSON",
+			status: http.StatusBadGateway,
+			body:   "<html>Bad gateway</html>\n",
+			want:   APIError{Status: 502, Message: "<html>Bad gateway</html>"},
+			text:   "notion API error (status 502): <html>Bad gateway</html>",
+		},
+		{
+			name:   "JSON without code",
+			status: http.StatusServiceUnavailable,
+			body:   `{"error":"busy"}`,
+			want:   APIError{Status: 503, Message: `{"error":"busy"}`},
+		},
+		{name: "empty", status: http.StatusTooManyRequests, want: APIError{Status: 429}, text: "notion API error (status 429)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newAPIError(tt.status, []byte(tt.body))
+			if *got != tt.want {
+				t.Errorf("newAPIError() = %+v, want %+v", *got, tt.want)
+			}
+			if tt.text != "" && got.Error() != tt.text {
+				t.Errorf("Error() = %q, want %q", got.Error(), tt.text)
+			}
+		})
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	apiErr := func(status int, code, message string) error {
+		return fmt.Errorf("query: %w", &APIError{Status: status, Code: code, Message: message})
+	}
+	tests := []struct {
+		name     string
+		err      error
+		kind     string
+		notFound string
+	}{
+		{name: "unauthorized", err: apiErr(401, CodeUnauthorized, "API token is invalid."), kind: "IsUnauthorized"},
+		{name: "restricted", err: apiErr(403, CodeRestrictedResource, ""), kind: "IsUnauthorized"},
+		{name: "forbidden", err: apiErr(403, "", ""), kind: "IsUnauthorized"},
+		{name: "database not found", err: apiErr(404, CodeObjectNotFound, "Could not find database with ID: x."), kind: "IsNotFound", notFound: "database"},
+		{name: "page not found", err: apiErr(404, CodeObjectNotFound, "Could not find page with ID: x."), kind: "IsNotFound", notFound: "page"},
+		{name: "block not found", err: apiErr(404, CodeObjectNotFound, "Could not find block with ID: x."), kind: "IsNotFound", notFound: "block"},
+		{name: "not found", err: apiErr(404, "", ""), kind: "IsNotFound"},
+		{name: "rate limited", err: apiErr(429, CodeRateLimited, ""), kind: "IsRateLimited"},
+		{name: "validation", err: apiErr(400, CodeValidation, "body failed validation"), kind: "IsValidation"},
+		{name: "missing version", err: apiErr(400, CodeMissingVersion, ""), kind: "IsValidation"},
+		{name: "server error", err: apiErr(500, CodeInternalServer, ""), kind: "IsUnavailable"},
+		{name: "network", err: &url.Error{Op: "Post", URL: "https://api.notion.com", Err: context.DeadlineExceeded}, kind: "IsUnavailable"},
+	}
+	kinds := map[string]func(error) bool{
+		"IsUnauthorized": IsUnauthorized,
+		"IsNotFound":     IsNotFound,
+		"IsRateLimited":  IsRateLimited,
+		"IsValidation":   IsValidation,
+		"IsUnavailable":  IsUnavailable,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Each error is of exactly one kind
+			for name, is := range kinds {
+				want := name == tt.kind
+				if got := is(tt.err); got != want {
+					t.Errorf("%s(%v) = %v, want %v", name, tt.err, got, want)
+				}
+			}
+			if got := NotFoundObject(tt.err); got != tt.notFound {
+				t.Errorf("NotFoundObject() = %q, want %q", got, tt.notFound)
+			}
+		})
+	}
+	if IsNotFound(fmt.Errorf("plain")) || IsUnavailable(fmt.Errorf("plain")) {
+		t.Error("a plain error was classified")
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	server, client := newTestClient(t, fakenotion.TodoSchema())
+	_, err := client.GetPage(context.Background(), "5e6f708192a3b4c5d6e7f8091a2b3c4d")
+	apiErr, ok := AsAPIError(err)
+	if !ok || apiErr.Status != http.StatusNotFound || apiErr.Code != CodeObjectNotFound {
+		t.Fatalf("GetPage of a missing page: error = %v", err)
+	}
+	if NotFoundObject(err) != "page" {
+		t.Errorf("NotFoundObject() = %q, want page", NotFoundObject(err))
+	}
+
+	server.Fail(http.StatusUnauthorized, CodeUnauthorized, "API token is invalid.", 1)
+	if _, err := client.ListUsers(context.Background()); !IsUnauthorized(err) {
+		t.Errorf("ListUsers with a rejected token: error = %v", err)
+	}
+}
@@ -0,0 +1,340 @@
+package fakenotion
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// matchFilter evaluates a database query filter against a page object
+func (s *Server) matchFilter(db *database, obj, filter map[string]interface{}) (bool, *apiError) {
+	if and, ok := filter["and"].([]interface{}); ok {
+		for _, sub := range and {
+			match, apiErr := s.matchFilter(db, obj, sub.(map[string]interface{}))
+			if apiErr != nil || !match {
+				return false, apiErr
+			}
+		}
+		return true, nil
+	}
+	if or, ok := filter["or"].([]interface{}); ok {
+		for _, sub := range or {
+			match, apiErr := s.matchFilter(db, obj, sub.(map[string]interface{}))
+			if apiErr != nil {
+				return false, apiErr
+			}
+			if match {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if timestamp, ok := filter["timestamp"].(string); ok {
+		condition, _ := filter[timestamp].(map[string]interface{})
+		value, _ := obj[timestamp].(string)
+		return matchDate(value, condition), nil
+	}
+
+	name, _ := filter["property"].(string)
+	schema, ok := db.properties[name]
+	if !ok {
+		schema = propertyByID(db, name)
+	}
+	if schema == nil {
+		return false, validationError("Could not find property with name or id: %s", name)
+	}
+	kind := schema["type"].(string)
+
+	filterKind := ""
+	for key := range filter {
+		if key != "property" {
+			filterKind = key
+		}
+	}
+	if filterKind != kind && !(kind == "title" && filterKind == "rich_text") {
+		return false, validationError("body failed validation. Fix one: body.filter.%s should be not present. database property %s does not match filter %s.", filterKind, kind, filterKind)
+	}
+	condition, _ := filter[filterKind].(map[string]interface{})
+	value := obj["properties"].(map[string]interface{})[schema["name"].(string)].(map[string]interface{})[kind]
+
+	switch kind {
+	case "title", "rich_text", "url", "email", "phone_number":
+		text, _ := value.(string)
+		if kind == "title" || kind == "rich_text" {
+			text = plainText(value)
+		}
+		return matchText(text, condition), nil
+	case "select", "status":
+		option, _ := value.(map[string]interface{})
+		optionName, _ := option["name"].(string)
+		return matchText(optionName, condition), nil
+	case "multi_select", "people", "relation":
+		return matchList(value, condition), nil
+	case "date":
+		date, _ := value.(map[string]interface{})
+		start, _ := date["start"].(string)
+		return matchDate(start, condition), nil
+	case "checkbox":
+		checked, _ := value.(bool)
+		want, _ := condition["equals"].(bool)
+		if other, ok := condition["does_not_equal"].(bool); ok {
+			return checked != other, nil
+		}
+		return checked == want, nil
+	case "number":
+		number, isSet := value.(float64)
+		return matchNumber(number, isSet, condition), nil
+	}
+	return false, validationError("Filtering on %s properties is not supported by the fake server.", kind)
+}
+
+func matchText(value string, condition map[string]interface{}) bool {
+	for op, raw := range condition {
+		want, _ := raw.(string)
+		lower, lowerWant := strings.ToLower(value), strings.ToLower(want)
+		switch op {
+		case "equals":
+			if value != want {
+				return false
+			}
+		case "does_not_equal":
+			if value == want {
+				return false
+			}
+		case "contains":
+			if !strings.Contains(lower, lowerWant) {
+				return false
+			}
+		case "does_not_contain":
+			if strings.Contains(lower, lowerWant) {
+				return false
+			}
+		case "starts_with":
+			if !strings.HasPrefix(lower, lowerWant) {
+				return false
+			}
+		case "ends_with":
+			if !strings.HasSuffix(lower, lowerWant) {
+				return false
+			}
+		case "is_empty":
+			if value != "" {
+				return false
+			}
+		case "is_not_empty":
+			if value == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchList(value interface{}, condition map[string]interface{}) bool {
+	items, _ := value.([]interface{})
+	has := func(want string) bool {
+		for _, item := range items {
+			m, _ := item.(map[string]interface{})
+			if m["name"] == want || m["id"] == want {
+				return true
+			}
+		}
+		return false
+	}
+	for op, raw := range condition {
+		want, _ := raw.(string)
+		switch op {
+		case "contains":
+			if !has(want) {
+				return false
+			}
+		case "does_not_contain":
+			if has(want) {
+				return false
+			}
+		case "is_empty":
+			if len(items) != 0 {
+				return false
+			}
+		case "is_not_empty":
+			if len(items) == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchNumber(value float64, isSet bool, condition map[string]interface{}) bool {
+	for op, raw := range condition {
+		want, _ := raw.(float64)
+		switch op {
+		case "equals":
+			if !isSet || value != want {
+				return false
+			}
+		case "does_not_equal":
+			if isSet && value == want {
+				return false
+			}
+		case "greater_than":
+			if !isSet || value <= want {
+				return false
+			}
+		case "less_than":
+			if !isSet || value >= want {
+				return false
+			}
+		case "greater_than_or_equal_to":
+			if !isSet || value < want {
+				return false
+			}
+		case "less_than_or_equal_to":
+			if !isSet || value > want {
+				return false
+			}
+		case "is_empty":
+			if isSet {
+				return false
+			}
+		case "is_not_empty":
+			if !isSet {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchDate compares ISO dates or date-times. When either side has no time
+// component the comparison is by calendar day, as in Notion.
+func matchDate(value string, condition map[string]interface{}) bool {
+	for op, raw := range condition {
+		switch op {
+		case "is_empty":
+			if value != "" {
+				return false
+			}
+			continue
+		case "is_not_empty":
+			if value == "" {
+				return false
+			}
+			continue
+		}
+		want, _ := raw.(string)
+		if value == "" {
+			return false
+		}
+		cmp := compareDates(value, want)
+		switch op {
+		case "equals":
+			if cmp != 0 {
+				return false
+			}
+		case "before":
+			if cmp >= 0 {
+				return false
+			}
+		case "after":
+			if cmp <= 0 {
+				return false
+			}
+		case "on_or_before":
+			if cmp > 0 {
+				return false
+			}
+		case "on_or_after":
+			if cmp < 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func compareDates(a, b string) int {
+	ta, aTime := parseDate(a)
+	tb, bTime := parseDate(b)
+	if !aTime || !bTime {
+		return strings.Compare(ta.Format("2006-01-02"), tb.Format("2006-01-02"))
+	}
+	return ta.Compare(tb)
+}
+
+// parseDate parses an ISO date or date-time, reporting whether it had a time
+func parseDate(value string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	t, _ := time.Parse("2006-01-02", value)
+	return t, false
+}
+
+// sortPages orders results by the query sorts, falling back to creation order
+func sortPages(results []map[string]interface{}, sorts []interface{}) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i]["id"].(string) < results[j]["id"].(string)
+	})
+	for k := len(sorts) - 1; k >= 0; k-- {
+		sortSpec, _ := sorts[k].(map[string]interface{})
+		descending := sortSpec["direction"] == "descending"
+		sort.SliceStable(results, func(i, j int) bool {
+			a, b := sortValue(results[i], sortSpec), sortValue(results[j], sortSpec)
+			// Empty values always sort last
+			if a.empty || b.empty {
+				return !a.empty && b.empty
+			}
+			if descending {
+				return b.less(a)
+			}
+			return a.less(b)
+		})
+	}
+}
+
+// sortKey is a comparable property value
+type sortKey struct {
+	text   string
+	number float64
+	empty  bool
+}
+
+func (k sortKey) less(other sortKey) bool {
+	if k.text == "" && other.text == "" {
+		return k.number < other.number
+	}
+	return k.text < other.text
+}
+
+func sortValue(obj map[string]interface{}, sortSpec map[string]interface{}) sortKey {
+	if timestamp, ok := sortSpec["timestamp"].(string); ok {
+		value, _ := obj[timestamp].(string)
+		return sortKey{text: value, empty: value == ""}
+	}
+	name, _ := sortSpec["property"].(string)
+	prop, _ := obj["properties"].(map[string]interface{})[name].(map[string]interface{})
+	kind, _ := prop["type"].(string)
+	switch value := prop[kind].(type) {
+	case []interface{}:
+		text := strings.ToLower(plainText(value))
+		return sortKey{text: text, empty: text == ""}
+	case map[string]interface{}:
+		text, ok := value["start"].(string)
+		if !ok {
+			text, _ = value["name"].(string)
+		}
+		return sortKey{text: text, empty: text == ""}
+	case string:
+		return sortKey{text: strings.ToLower(value), empty: value == ""}
+	case bool:
+		if value {
+			return sortKey{number: 1}
+		}
+		return sortKey{}
+	case float64:
+		return sortKey{number: value}
+	}
+	return sortKey{empty: true}
+}
@@ -0,0 +1,276 @@
+package fakenotion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// richTextLimit is the maximum length of a single rich text content string
+const richTextLimit = 2000
+
+// apiError is written in the shape of Notion's error object
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func writeError(w http.ResponseWriter, e *apiError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"object":     "error",
+		"status":     e.status,
+		"code":       e.code,
+		"message":    e.message,
+		"request_id": "00000000-fake-4000-8000-000000000000",
+	})
+}
+
+func notFound(id, object string) *apiError {
+	return &apiError{http.StatusNotFound, "object_not_found",
+		fmt.Sprintf("Could not find %s with ID: %s. Make sure the relevant pages and databases are shared with your integration.", object, id)}
+}
+
+func validationError(format string, args ...interface{}) *apiError {
+	return &apiError{http.StatusBadRequest, "validation_error", fmt.Sprintf(format, args...)}
+}
+
+type bodyKey struct{}
+
+// withBody stores the decoded request body, since the router consumes it
+func withBody(ctx context.Context, body map[string]interface{}) context.Context {
+	return context.WithValue(ctx, bodyKey{}, body)
+}
+
+func requestBody(r *http.Request) map[string]interface{} {
+	body, _ := r.Context().Value(bodyKey{}).(map[string]interface{})
+	if body == nil {
+		body = map[string]interface{}{}
+	}
+	return body
+}
+
+// convertProperties validates write-shaped property values against the
+// database schema and returns them in the shape Notion reads them back
+func (s *Server) convertProperties(db *database, properties map[string]interface{}) (map[string]map[string]interface{}, *apiError) {
+	converted := map[string]map[string]interface{}{}
+	for name, raw := range properties {
+		schema, ok := db.properties[name]
+		if !ok {
+			schema = propertyByID(db, name)
+		}
+		if schema == nil {
+			return nil, validationError("%s is not a property that exists.", name)
+		}
+		name = schema["name"].(string)
+		kind := schema["type"].(string)
+
+		value, _ := raw.(map[string]interface{})
+		content, ok := value[kind]
+		if !ok {
+			return nil, validationError("%s is expected to be %s.", name, kind)
+		}
+
+		read, apiErr := s.convertValue(schema, kind, name, content)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		converted[name] = map[string]interface{}{kind: read}
+	}
+	return converted, nil
+}
+
+func (s *Server) convertValue(schema map[string]interface{}, kind, name string, content interface{}) (interface{}, *apiError) {
+	switch kind {
+	case "title", "rich_text":
+		if apiErr := validateRichText(content, "body.properties."+name+"."+kind); apiErr != nil {
+			return nil, apiErr
+		}
+		return readRichText(content), nil
+	case "select", "status":
+		if content == nil {
+			return nil, nil
+		}
+		option, apiErr := s.option(schema, kind, name, content)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		return option, nil
+	case "multi_select":
+		items, _ := content.([]interface{})
+		options := []interface{}{}
+		for _, item := range items {
+			option, apiErr := s.option(schema, kind, name, item)
+			if apiErr != nil {
+				return nil, apiErr
+			}
+			options = append(options, option)
+		}
+		return options, nil
+	case "date":
+		if content == nil {
+			return nil, nil
+		}
+		date, ok := content.(map[string]interface{})
+		if !ok || date["start"] == nil {
+			return nil, validationError("body.properties.%s.date.start should be defined.", name)
+		}
+		read := map[string]interface{}{"start": date["start"], "end": nil, "time_zone": nil}
+		if end, ok := date["end"]; ok {
+			read["end"] = end
+		}
+		if zone, ok := date["time_zone"]; ok {
+			read["time_zone"] = zone
+		}
+		return read, nil
+	case "people":
+		items, _ := content.([]interface{})
+		people := []interface{}{}
+		for _, item := range items {
+			person, _ := item.(map[string]interface{})
+			id, _ := person["id"].(string)
+			if user := s.user(id); user != nil {
+				person = user
+			} else if len(s.users) > 0 {
+				return nil, validationError("Could not find user with ID: %s.", id)
+			} else {
+				person = cloneMap(person)
+				person["object"] = "user"
+			}
+			people = append(people, person)
+		}
+		return people, nil
+	case "relation":
+		items, _ := content.([]interface{})
+		relations := []interface{}{}
+		for _, item := range items {
+			relation, _ := item.(map[string]interface{})
+			relations = append(relations, map[string]interface{}{"id": relation["id"]})
+		}
+		return relations, nil
+	case "checkbox", "number", "url", "email", "phone_number":
+		return content, nil
+	}
+	return nil, validationError("%s is a read-only %s property.", name, kind)
+}
+
+// option resolves a select-like value by name or id. Unknown select and
+// multi_select names create a new option, as Notion does; status does not.
+func (s *Server) option(schema map[string]interface{}, kind, name string, content interface{}) (map[string]interface{}, *apiError) {
+	value, _ := content.(map[string]interface{})
+	wantName, _ := value["name"].(string)
+	wantID, _ := value["id"].(string)
+
+	config := schema[kind].(map[string]interface{})
+	options, _ := config["options"].([]interface{})
+	for _, option := range options {
+		o := option.(map[string]interface{})
+		if (wantName != "" && o["name"] == wantName) || (wantID != "" && o["id"] == wantID) {
+			return map[string]interface{}{"id": o["id"], "name": o["name"], "color": o["color"]}, nil
+		}
+	}
+	if kind == "status" || wantName == "" {
+		return nil, validationError("Invalid %s option \"%s\" for property %s.", kind, wantName+wantID, name)
+	}
+
+	o := map[string]interface{}{"id": s.newID()[:8], "name": wantName, "color": "default"}
+	config["options"] = append(options, o)
+	return cloneMap(o), nil
+}
+
+func propertyByID(db *database, id string) map[string]interface{} {
+	for _, prop := range db.properties {
+		if prop["id"] == id {
+			return prop
+		}
+	}
+	return nil
+}
+
+// emptyValue returns the value Notion reports for an unset property
+func emptyValue(schema map[string]interface{}) map[string]interface{} {
+	kind := schema["type"].(string)
+	switch kind {
+	case "title", "rich_text", "multi_select", "people", "relation":
+		return map[string]interface{}{kind: []interface{}{}}
+	case "checkbox":
+		return map[string]interface{}{kind: false}
+	case "status":
+		// Status properties always have a value, defaulting to the first option
+		config, _ := schema[kind].(map[string]interface{})
+		if options, _ := config["options"].([]interface{}); len(options) > 0 {
+			o := options[0].(map[string]interface{})
+			return map[string]interface{}{kind: map[string]interface{}{"id": o["id"], "name": o["name"], "color": o["color"]}}
+		}
+	}
+	return map[string]interface{}{kind: nil}
+}
+
+// validateRichText enforces Notion's per-item content length limit
+func validateRichText(content interface{}, path string) *apiError {
+	items, _ := content.([]interface{})
+	if len(items) > 100 {
+		return validationError("body failed validation: %s.length should be ≤ `100`, instead was `%d`.", path, len(items))
+	}
+	for i, item := range items {
+		text, _ := item.(map[string]interface{})["text"].(map[string]interface{})
+		body, _ := text["content"].(string)
+		if n := utf8.RuneCountInString(body); n > richTextLimit {
+			return validationError("body failed validation: %s[%d].text.content.length should be ≤ `%d`, instead was `%d`.", path, i, richTextLimit, n)
+		}
+	}
+	return nil
+}
+
+// readRichText converts write-shaped rich text into the read shape
+func readRichText(content interface{}) []interface{} {
+	items, _ := content.([]interface{})
+	read := []interface{}{}
+	for _, item := range items {
+		raw, _ := item.(map[string]interface{})
+		text, _ := raw["text"].(map[string]interface{})
+		body, _ := text["content"].(string)
+
+		var href interface{}
+		if link, ok := text["link"].(map[string]interface{}); ok {
+			href = link["url"]
+		}
+		annotations := map[string]interface{}{
+			"bold": false, "italic": false, "strikethrough": false,
+			"underline": false, "code": false, "color": "default",
+		}
+		if given, ok := raw["annotations"].(map[string]interface{}); ok {
+			for key, value := range given {
+				annotations[key] = value
+			}
+		}
+		read = append(read, map[string]interface{}{
+			"type":        "text",
+			"text":        map[string]interface{}{"content": body, "link": text["link"]},
+			"annotations": annotations,
+			"plain_text":  body,
+			"href":        href,
+		})
+	}
+	return read
+}
+
+func textItem(content string) map[string]interface{} {
+	return map[string]interface{}{"text": map[string]interface{}{"content": content}}
+}
+
+// plainText concatenates the plain text of read-shaped rich text
+func plainText(content interface{}) string {
+	items, _ := content.([]interface{})
+	var b strings.Builder
+	for _, item := range items {
+		text, _ := item.(map[string]interface{})["plain_text"].(string)
+		b.WriteString(text)
+	}
+	return b.String()
+}
@@ -0,0 +1,784 @@
+// Package fakenotion provides an in-memory fake of the Notion API so the
+// client, processors and list view can be exercised offline in go test.
+//
+// Point the CLI at it by setting NOTION_API_URL (or the apiUrl config key)
+// to Server.APIURL().
+package fakenotion
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is an in-memory Notion API served over HTTP
+type Server struct {
+	*httptest.Server
+
+	// Token, when set, is the only integration token the server accepts
+	Token string
+	// Now returns the timestamp used for created and edited times
+	Now func() time.Time
+
+	mu        sync.Mutex
+	nextID    int
+	databases map[string]*database
+	pages     map[string]*page
+	blocks    map[string]*block
+	children  map[string][]string
+	users     []map[string]interface{}
+	failures  []failure
+	requests  []Request
+}
+
+// Request records a call received by the server
+type Request struct {
+	Method string
+	Path   string
+	Body   map[string]interface{}
+}
+
+type database struct {
+	id         string
+	title      string
+	properties map[string]map[string]interface{}
+	created    time.Time
+}
+
+type page struct {
+	id         string
+	databaseID string
+	properties map[string]map[string]interface{}
+	archived   bool
+	created    time.Time
+	edited     time.Time
+}
+
+type block struct {
+	id       string
+	parentID string
+	kind     string
+	content  map[string]interface{}
+	archived bool
+	created  time.Time
+}
+
+type failure struct {
+	status     int
+	code       string
+	message    string
+	retryAfter int
+}
+
+// NewServer starts an empty fake Notion server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		Now:       time.Now,
+		databases: map[string]*database{},
+		pages:     map[string]*page{},
+		blocks:    map[string]*block{},
+		children:  map[string][]string{},
+	}
+	s.Server = httptest.NewServer(s.routes())
+	return s
+}
+
+// APIURL returns the base URL to use in place of https://api.notion.com/v1
+func (s *Server) APIURL() string {
+	return s.URL + "/v1"
+}
+
+// AddDatabase registers a database with the given property schema, keyed by
+// property name, e.g. {"Title": {"type": "title"}}. Select and status
+// options are given as {"select": {"options": [{"name": "Todo"}]}}.
+func (s *Server) AddDatabase(id, title string, properties map[string]map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db := &database{
+		id:         id,
+		title:      title,
+		properties: map[string]map[string]interface{}{},
+		created:    s.Now(),
+	}
+	for name, prop := range properties {
+		prop = cloneMap(prop)
+		prop["name"] = name
+		if _, ok := prop["id"]; !ok {
+			prop["id"] = s.newID()[:8]
+		}
+		s.fillOptions(prop)
+		db.properties[name] = prop
+	}
+	s.databases[id] = db
+}
+
+// AddUser registers a workspace member, listed by GET /users and used to
+// fill in people property values
+func (s *Server) AddUser(id, name, email string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = append(s.users, map[string]interface{}{
+		"object":     "user",
+		"id":         id,
+		"type":       "person",
+		"name":       name,
+		"avatar_url": nil,
+		"person":     map[string]interface{}{"email": email},
+	})
+}
+
+// AddTodoDatabase registers a database matching the Notion Todo template:
+// Title, a Status select with Todo, In Progress and Done, and Due Date
+func (s *Server) AddTodoDatabase(id string) {
+	s.AddDatabase(id, "Todo", TodoSchema())
+}
+
+// TodoSchema returns the property schema of the Notion Todo template
+func TodoSchema() map[string]map[string]interface{} {
+	return map[string]map[string]interface{}{
+		"Title": {"type": "title"},
+		"Status": {"type": "select", "select": map[string]interface{}{
+			"options": []interface{}{
+				map[string]interface{}{"name": "Todo", "color": "yellow"},
+				map[string]interface{}{"name": "In Progress", "color": "blue"},
+				map[string]interface{}{"name": "Done", "color": "green"},
+			},
+		}},
+		"Due Date": {"type": "date"},
+	}
+}
+
+// AddPage creates a page in a database from write-shaped properties, as
+// accepted by POST /pages, and returns its id
+func (s *Server) AddPage(databaseID string, properties map[string]interface{}) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, apiErr := s.createPage(databaseID, properties)
+	if apiErr != nil {
+		return "", fmt.Errorf("%s: %s", apiErr.code, apiErr.message)
+	}
+	return p.id, nil
+}
+
+// Page returns the read-shaped properties of a page, whether it is archived
+// and whether it exists
+func (s *Server) Page(id string) (properties map[string]interface{}, archived bool, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.pages[id]
+	if !ok {
+		return nil, false, false
+	}
+	properties = s.pageObject(p)["properties"].(map[string]interface{})
+	return properties, p.archived, true
+}
+
+// Fail makes the next times requests fail with the given status and
+// Notion error code. A 429 status is sent with a Retry-After of 0.
+func (s *Server) Fail(status int, code, message string, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < times; i++ {
+		s.failures = append(s.failures, failure{status: status, code: code, message: message})
+	}
+}
+
+// RateLimit makes the next times requests fail with a 429 status and a
+// Retry-After of retryAfter seconds
+func (s *Server) RateLimit(retryAfter, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < times; i++ {
+		s.failures = append(s.failures, failure{
+			status:     http.StatusTooManyRequests,
+			code:       "rate_limited",
+			message:    "You have been rate limited. Please try again in a few minutes.",
+			retryAfter: retryAfter,
+		})
+	}
+}
+
+// Requests returns every request received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/databases/{id}", s.handleGetDatabase)
+	mux.HandleFunc("POST /v1/databases/{id}/query", s.handleQueryDatabase)
+	mux.HandleFunc("POST /v1/pages", s.handleCreatePage)
+	mux.HandleFunc("GET /v1/pages/{id}", s.handleGetPage)
+	mux.HandleFunc("PATCH /v1/pages/{id}", s.handleUpdatePage)
+	mux.HandleFunc("GET /v1/blocks/{id}", s.handleGetBlock)
+	mux.HandleFunc("PATCH /v1/blocks/{id}", s.handleUpdateBlock)
+	mux.HandleFunc("DELETE /v1/blocks/{id}", s.handleDeleteBlock)
+	mux.HandleFunc("GET /v1/blocks/{id}/children", s.handleListChildren)
+	mux.HandleFunc("PATCH /v1/blocks/{id}/children", s.handleAppendChildren)
+	mux.HandleFunc("POST /v1/search", s.handleSearch)
+	mux.HandleFunc("GET /v1/users", s.handleListUsers)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		body := map[string]interface{}{}
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Body: body})
+
+		if len(s.failures) > 0 {
+			f := s.failures[0]
+			s.failures = s.failures[1:]
+			if f.status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", strconv.Itoa(f.retryAfter))
+			}
+			writeError(w, &apiError{f.status, f.code, f.message})
+			return
+		}
+		if r.Header.Get("Notion-Version") == "" {
+			writeError(w, &apiError{http.StatusBadRequest, "missing_version", "Notion-Version header failed validation."})
+			return
+		}
+		if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+			writeError(w, &apiError{http.StatusUnauthorized, "unauthorized", "API token is invalid."})
+			return
+		}
+
+		r = r.WithContext(withBody(r.Context(), body))
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleGetDatabase(w http.ResponseWriter, r *http.Request) {
+	db, apiErr := s.database(r.PathValue("id"))
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	writeJSON(w, s.databaseObject(db))
+}
+
+func (s *Server) handleQueryDatabase(w http.ResponseWriter, r *http.Request) {
+	db, apiErr := s.database(r.PathValue("id"))
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	body := requestBody(r)
+
+	var results []map[string]interface{}
+	for _, p := range s.pages {
+		if p.databaseID != db.id || p.archived {
+			continue
+		}
+		obj := s.pageObject(p)
+		if filter, ok := body["filter"].(map[string]interface{}); ok {
+			match, apiErr := s.matchFilter(db, obj, filter)
+			if apiErr != nil {
+				writeError(w, apiErr)
+				return
+			}
+			if !match {
+				continue
+			}
+		}
+		results = append(results, obj)
+	}
+
+	sorts, _ := body["sorts"].([]interface{})
+	sortPages(results, sorts)
+
+	writeJSON(w, paginate(results, body))
+}
+
+func (s *Server) handleCreatePage(w http.ResponseWriter, r *http.Request) {
+	body := requestBody(r)
+	parent, _ := body["parent"].(map[string]interface{})
+	databaseID, _ := parent["database_id"].(string)
+	properties, _ := body["properties"].(map[string]interface{})
+
+	p, apiErr := s.createPage(databaseID, properties)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	if children, ok := body["children"].([]interface{}); ok {
+		if apiErr := s.appendChildren(p.id, children); apiErr != nil {
+			writeError(w, apiErr)
+			return
+		}
+	}
+	writeJSON(w, s.pageObject(p))
+}
+
+func (s *Server) handleGetPage(w http.ResponseWriter, r *http.Request) {
+	p, apiErr := s.page(r.PathValue("id"))
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	writeJSON(w, s.pageObject(p))
+}
+
+func (s *Server) handleUpdatePage(w http.ResponseWriter, r *http.Request) {
+	p, apiErr := s.page(r.PathValue("id"))
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	body := requestBody(r)
+
+	if properties, ok := body["properties"].(map[string]interface{}); ok {
+		converted, apiErr := s.convertProperties(s.databases[p.databaseID], properties)
+		if apiErr != nil {
+			writeError(w, apiErr)
+			return
+		}
+		for name, value := range converted {
+			p.properties[name] = value
+		}
+	}
+	if archived, ok := body["archived"].(bool); ok {
+		p.archived = archived
+	}
+	p.edited = s.Now()
+	writeJSON(w, s.pageObject(p))
+}
+
+func (s *Server) handleGetBlock(w http.ResponseWriter, r *http.Request) {
+	b, ok := s.blocks[r.PathValue("id")]
+	if !ok || b.archived {
+		writeError(w, notFound(r.PathValue("id"), "block"))
+		return
+	}
+	writeJSON(w, s.blockObject(b))
+}
+
+func (s *Server) handleUpdateBlock(w http.ResponseWriter, r *http.Request) {
+	b, ok := s.blocks[r.PathValue("id")]
+	if !ok || b.archived {
+		writeError(w, notFound(r.PathValue("id"), "block"))
+		return
+	}
+	body := requestBody(r)
+	if content, ok := body[b.kind].(map[string]interface{}); ok {
+		for key, value := range content {
+			if key == "rich_text" {
+				value = readRichText(value)
+			}
+			b.content[key] = value
+		}
+	}
+	if archived, ok := body["archived"].(bool); ok {
+		b.archived = archived
+	}
+	writeJSON(w, s.blockObject(b))
+}
+
+func (s *Server) handleDeleteBlock(w http.ResponseWriter, r *http.Request) {
+	b, ok := s.blocks[r.PathValue("id")]
+	if !ok || b.archived {
+		writeError(w, notFound(r.PathValue("id"), "block"))
+		return
+	}
+	b.archived = true
+	writeJSON(w, s.blockObject(b))
+}
+
+func (s *Server) handleListChildren(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if apiErr := s.blockParent(id); apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	var results []map[string]interface{}
+	for _, childID := range s.children[id] {
+		if b := s.blocks[childID]; !b.archived {
+			results = append(results, s.blockObject(b))
+		}
+	}
+
+	query := map[string]interface{}{
+		"start_cursor": r.URL.Query().Get("start_cursor"),
+	}
+	if size, err := strconv.Atoi(r.URL.Query().Get("page_size")); err == nil {
+		query["page_size"] = float64(size)
+	}
+	writeJSON(w, paginate(results, query))
+}
+
+func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	var results []map[string]interface{}
+	for _, user := range s.users {
+		results = append(results, cloneMap(user))
+	}
+
+	query := map[string]interface{}{
+		"start_cursor": r.URL.Query().Get("start_cursor"),
+	}
+	if size, err := strconv.Atoi(r.URL.Query().Get("page_size")); err == nil {
+		query["page_size"] = float64(size)
+	}
+	writeJSON(w, paginate(results, query))
+}
+
+func (s *Server) handleAppendChildren(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if apiErr := s.blockParent(id); apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	children, _ := requestBody(r)["children"].([]interface{})
+
+	before := len(s.children[id])
+	if apiErr := s.appendChildren(id, children); apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	var results []map[string]interface{}
+	for _, childID := range s.children[id][before:] {
+		results = append(results, s.blockObject(s.blocks[childID]))
+	}
+	writeJSON(w, paginate(results, map[string]interface{}{"page_size": float64(100)}))
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	body := requestBody(r)
+	query, _ := body["query"].(string)
+	query = strings.ToLower(query)
+	objectFilter := ""
+	if filter, ok := body["filter"].(map[string]interface{}); ok {
+		objectFilter, _ = filter["value"].(string)
+	}
+
+	var results []map[string]interface{}
+	if objectFilter == "" || objectFilter == "database" {
+		for _, db := range s.databases {
+			if strings.Contains(strings.ToLower(db.title), query) {
+				results = append(results, s.databaseObject(db))
+			}
+		}
+	}
+	if objectFilter == "" || objectFilter == "page" {
+		for _, p := range s.pages {
+			if !p.archived && strings.Contains(strings.ToLower(s.pageTitle(p)), query) {
+				results = append(results, s.pageObject(p))
+			}
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i]["last_edited_time"].(string) > results[j]["last_edited_time"].(string)
+	})
+	writeJSON(w, paginate(results, body))
+}
+
+// database looks up a database by id, accepting ids with or without dashes
+func (s *Server) database(id string) (*database, *apiError) {
+	for _, db := range s.databases {
+		if sameID(db.id, id) {
+			return db, nil
+		}
+	}
+	return nil, notFound(id, "database")
+}
+
+func (s *Server) page(id string) (*page, *apiError) {
+	for _, p := range s.pages {
+		if sameID(p.id, id) {
+			return p, nil
+		}
+	}
+	return nil, notFound(id, "page")
+}
+
+// blockParent checks that id refers to a page or a live block
+func (s *Server) blockParent(id string) *apiError {
+	if _, apiErr := s.page(id); apiErr == nil {
+		return nil
+	}
+	if b, ok := s.blocks[id]; ok && !b.archived {
+		return nil
+	}
+	return notFound(id, "block")
+}
+
+func (s *Server) createPage(databaseID string, properties map[string]interface{}) (*page, *apiError) {
+	db, apiErr := s.database(databaseID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	converted, apiErr := s.convertProperties(db, properties)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	now := s.Now()
+	p := &page{
+		id:         s.newID(),
+		databaseID: db.id,
+		properties: converted,
+		created:    now,
+		edited:     now,
+	}
+	s.pages[p.id] = p
+	return p, nil
+}
+
+func (s *Server) appendChildren(parentID string, children []interface{}) *apiError {
+	if len(children) > 100 {
+		return &apiError{http.StatusBadRequest, "validation_error",
+			fmt.Sprintf("body failed validation: body.children.length should be ≤ `100`, instead was `%d`.", len(children))}
+	}
+	var created []*block
+	for i, child := range children {
+		raw, _ := child.(map[string]interface{})
+		kind, _ := raw["type"].(string)
+		if kind == "" {
+			// The type may be implied by the single content key
+			for key := range raw {
+				if key != "object" {
+					kind = key
+				}
+			}
+		}
+		content, ok := raw[kind].(map[string]interface{})
+		if !ok {
+			return &apiError{http.StatusBadRequest, "validation_error",
+				fmt.Sprintf("body failed validation: body.children[%d].%s should be defined.", i, kind)}
+		}
+		content = cloneMap(content)
+		if richText, ok := content["rich_text"]; ok {
+			if apiErr := validateRichText(richText, fmt.Sprintf("body.children[%d].%s.rich_text", i, kind)); apiErr != nil {
+				return apiErr
+			}
+			content["rich_text"] = readRichText(richText)
+		}
+		nested, _ := content["children"].([]interface{})
+		delete(content, "children")
+
+		b := &block{
+			id:       s.newID(),
+			parentID: parentID,
+			kind:     kind,
+			content:  content,
+			created:  s.Now(),
+		}
+		s.blocks[b.id] = b
+		created = append(created, b)
+		if len(nested) > 0 {
+			if apiErr := s.appendChildren(b.id, nested); apiErr != nil {
+				return apiErr
+			}
+		}
+	}
+	for _, b := range created {
+		s.children[parentID] = append(s.children[parentID], b.id)
+	}
+	return nil
+}
+
+func (s *Server) databaseObject(db *database) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for name, prop := range db.properties {
+		properties[name] = cloneMap(prop)
+	}
+	return map[string]interface{}{
+		"object":           "database",
+		"id":               db.id,
+		"created_time":     formatTime(db.created),
+		"last_edited_time": formatTime(db.created),
+		"title":            readRichText([]interface{}{textItem(db.title)}),
+		"properties":       properties,
+		"url":              "https://www.notion.so/" + strings.ReplaceAll(db.id, "-", ""),
+		"archived":         false,
+	}
+}
+
+func (s *Server) pageObject(p *page) map[string]interface{} {
+	properties := map[string]interface{}{}
+	db := s.databases[p.databaseID]
+	for name, schema := range db.properties {
+		prop, ok := p.properties[name]
+		if !ok {
+			prop = emptyValue(schema)
+		}
+		prop = cloneMap(prop)
+		prop["id"] = schema["id"]
+		prop["type"] = schema["type"]
+		properties[name] = prop
+	}
+	return map[string]interface{}{
+		"object":           "page",
+		"id":               p.id,
+		"created_time":     formatTime(p.created),
+		"last_edited_time": formatTime(p.edited),
+		"archived":         p.archived,
+		"parent":           map[string]interface{}{"type": "database_id", "database_id": p.databaseID},
+		"properties":       properties,
+		"url":              "https://www.notion.so/" + strings.ReplaceAll(p.id, "-", ""),
+	}
+}
+
+func (s *Server) blockObject(b *block) map[string]interface{} {
+	return map[string]interface{}{
+		"object":           "block",
+		"id":               b.id,
+		"type":             b.kind,
+		"created_time":     formatTime(b.created),
+		"last_edited_time": formatTime(b.created),
+		"has_children":     len(s.children[b.id]) > 0,
+		"archived":         b.archived,
+		"parent":           map[string]interface{}{"type": "block_id", "block_id": b.parentID},
+		b.kind:             cloneMap(b.content),
+	}
+}
+
+func (s *Server) pageTitle(p *page) string {
+	for name, schema := range s.databases[p.databaseID].properties {
+		if schema["type"] == "title" {
+			return plainText(p.properties[name]["title"])
+		}
+	}
+	return ""
+}
+
+// fillOptions assigns ids and default colors to select, status and
+// multi_select options
+func (s *Server) fillOptions(prop map[string]interface{}) {
+	kind, _ := prop["type"].(string)
+	config, ok := prop[kind].(map[string]interface{})
+	if !ok {
+		config = map[string]interface{}{}
+		prop[kind] = config
+	}
+	options, _ := config["options"].([]interface{})
+	var groupNames []string
+	groupIDs := map[string][]interface{}{}
+	for i, option := range options {
+		o := cloneMap(option.(map[string]interface{}))
+		if _, ok := o["id"]; !ok {
+			o["id"] = s.newID()[:8]
+		}
+		if _, ok := o["color"]; !ok {
+			o["color"] = "default"
+		}
+		// Status options may name their group, e.g. {"group": "Complete"}
+		group, _ := o["group"].(string)
+		delete(o, "group")
+		if group == "" {
+			group = "To-do"
+		}
+		if _, ok := groupIDs[group]; !ok {
+			groupNames = append(groupNames, group)
+		}
+		groupIDs[group] = append(groupIDs[group], o["id"])
+		options[i] = o
+	}
+	if options != nil {
+		config["options"] = options
+	}
+	if _, ok := config["groups"]; kind == "status" && !ok {
+		groups := []interface{}{}
+		for _, name := range groupNames {
+			groups = append(groups, map[string]interface{}{
+				"id":         s.newID()[:8],
+				"name":       name,
+				"color":      "default",
+				"option_ids": groupIDs[name],
+			})
+		}
+		config["groups"] = groups
+	}
+}
+
+// newID returns a unique UUID-shaped id
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("%08x-0000-4000-8000-%012x", s.nextID, s.Now().UnixNano()&0xffffffffffff)
+}
+
+func sameID(a, b string) bool {
+	return strings.ReplaceAll(a, "-", "") == strings.ReplaceAll(b, "-", "")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Truncate(time.Minute).Format("2006-01-02T15:04:05.000Z")
+}
+
+// user returns a copy of the registered user with the given id
+func (s *Server) user(id string) map[string]interface{} {
+	for _, user := range s.users {
+		if sameID(user["id"].(string), id) {
+			return cloneMap(user)
+		}
+	}
+	return nil
+}
+
+// paginate applies start_cursor and page_size from body to results
+func paginate(results []map[string]interface{}, body map[string]interface{}) map[string]interface{} {
+	start := 0
+	if cursor, _ := body["start_cursor"].(string); cursor != "" {
+		for i, result := range results {
+			if result["id"] == cursor {
+				start = i
+				break
+			}
+		}
+	}
+	size := 100
+	if pageSize, ok := body["page_size"].(float64); ok && pageSize > 0 && pageSize < 100 {
+		size = int(pageSize)
+	}
+
+	end := start + size
+	var nextCursor interface{}
+	if end < len(results) {
+		nextCursor = results[end]["id"]
+	} else {
+		end = len(results)
+	}
+
+	page := results[start:end]
+	if page == nil {
+		page = []map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"object":      "list",
+		"results":     page,
+		"next_cursor": nextCursor,
+		"has_more":    nextCursor != nil,
+	}
+}
+
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		clone[key] = value
+	}
+	return clone
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
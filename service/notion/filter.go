@@ -0,0 +1,203 @@
+package notion
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+// queryFilter translates filter into a Notion compound filter and sorts for
+// the database mapping. today is the local date used for overdue todos.
+func queryFilter(mapping models.PropertyMapping, filter models.TodoFilter, today string) (*models.QueryFilter, []map[string]interface{}, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if filter.HasDueFilter() && mapping.DueDate == "" {
+		return nil, nil, errors.New("the database has no date property for due dates")
+	}
+
+	var conditions []map[string]interface{}
+
+	// Several statuses match any of them
+	if len(filter.Statuses) > 0 {
+		var statuses []interface{}
+		seen := map[string]bool{}
+		for _, status := range filter.Statuses {
+			condition := statusFilter(mapping, status)
+			key := fmt.Sprint(condition)
+			if !seen[key] {
+				seen[key] = true
+				statuses = append(statuses, condition)
+			}
+		}
+		if len(statuses) == 1 {
+			conditions = append(conditions, statuses[0].(map[string]interface{}))
+		} else {
+			conditions = append(conditions, map[string]interface{}{"or": statuses})
+		}
+	}
+
+	if filter.TitleContains != "" {
+		conditions = append(conditions, map[string]interface{}{
+			"property": mapping.Title,
+			"title": map[string]interface{}{
+				"contains": filter.TitleContains,
+			},
+		})
+	}
+
+	if len(filter.Priorities) > 0 {
+		var priorities []interface{}
+		for _, name := range filter.Priorities {
+			priority, err := mapping.PriorityOption(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			priorities = append(priorities, map[string]interface{}{
+				"property":                mapping.Priority,
+				models.PropertyTypeSelect: map[string]interface{}{"equals": priority},
+			})
+		}
+		if len(priorities) == 1 {
+			conditions = append(conditions, priorities[0].(map[string]interface{}))
+		} else {
+			conditions = append(conditions, map[string]interface{}{"or": priorities})
+		}
+	}
+
+	// Several tags must all be present
+	if len(filter.Tags) > 0 && mapping.Tags == "" {
+		return nil, nil, errors.New("the database has no tags property")
+	}
+	for _, tag := range filter.Tags {
+		conditions = append(conditions, map[string]interface{}{
+			"property":                     mapping.Tags,
+			models.PropertyTypeMultiSelect: map[string]interface{}{"contains": tag},
+		})
+	}
+
+	// Assignees are user IDs by now, and match any of them
+	if len(filter.Assignees) > 0 {
+		if mapping.Assignee == "" {
+			return nil, nil, errors.New("the database has no assignee property")
+		}
+		var assignees []interface{}
+		for _, id := range filter.Assignees {
+			assignees = append(assignees, map[string]interface{}{
+				"property":                mapping.Assignee,
+				models.PropertyTypePeople: map[string]interface{}{"contains": id},
+			})
+		}
+		if len(assignees) == 1 {
+			conditions = append(conditions, assignees[0].(map[string]interface{}))
+		} else {
+			conditions = append(conditions, map[string]interface{}{"or": assignees})
+		}
+	}
+
+	dateCondition := func(operator string, value interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"property": mapping.DueDate,
+			"date": map[string]interface{}{
+				operator: value,
+			},
+		}
+	}
+	if filter.DueBefore != "" {
+		conditions = append(conditions, dateCondition("before", filter.DueBefore))
+	}
+	if filter.DueAfter != "" {
+		conditions = append(conditions, dateCondition("after", filter.DueAfter))
+	}
+	if filter.DueOn != "" {
+		conditions = append(conditions, dateCondition("equals", filter.DueOn))
+	}
+	if filter.NoDueDate {
+		conditions = append(conditions, dateCondition("is_empty", true))
+	}
+	if filter.Overdue {
+		conditions = append(conditions, dateCondition("before", today))
+		conditions = append(conditions, notCompleteFilter(mapping)...)
+	}
+
+	timestampCondition := func(timestamp, since string) map[string]interface{} {
+		return map[string]interface{}{
+			"timestamp": timestamp,
+			timestamp: map[string]interface{}{
+				"on_or_after": since,
+			},
+		}
+	}
+	if filter.CreatedSince != "" {
+		conditions = append(conditions, timestampCondition("created_time", filter.CreatedSince))
+	}
+	if filter.EditedSince != "" {
+		conditions = append(conditions, timestampCondition("last_edited_time", filter.EditedSince))
+	}
+
+	var sorts []map[string]interface{}
+	for _, sort := range filter.Sorts {
+		direction := "ascending"
+		if sort.Descending {
+			direction = "descending"
+		}
+		switch sort.Key {
+		case models.SortKeyDue:
+			if mapping.DueDate == "" {
+				return nil, nil, errors.New("the database has no date property to sort by due date")
+			}
+			sorts = append(sorts, map[string]interface{}{"property": mapping.DueDate, "direction": direction})
+		case models.SortKeyTitle:
+			sorts = append(sorts, map[string]interface{}{"property": mapping.Title, "direction": direction})
+		case models.SortKeyCreated:
+			sorts = append(sorts, map[string]interface{}{"timestamp": "created_time", "direction": direction})
+		case models.SortKeyEdited:
+			sorts = append(sorts, map[string]interface{}{"timestamp": "last_edited_time", "direction": direction})
+		}
+	}
+
+	if len(conditions) == 0 {
+		return nil, sorts, nil
+	}
+	return &models.QueryFilter{And: conditions}, sorts, nil
+}
+
+// notCompleteFilter returns conditions excluding todos in the Complete group
+// where the property type allows it. Select statuses are checked by
+// matchesFilter once the results are read.
+func notCompleteFilter(mapping models.PropertyMapping) []map[string]interface{} {
+	switch mapping.StatusType {
+	case models.PropertyTypeCheckbox:
+		return []map[string]interface{}{{
+			"property":                  mapping.Status,
+			models.PropertyTypeCheckbox: map[string]interface{}{"equals": false},
+		}}
+	case models.PropertyTypeStatus:
+		var conditions []map[string]interface{}
+		for status, group := range mapping.StatusGroups {
+			if group == models.StatusGroupComplete {
+				conditions = append(conditions, map[string]interface{}{
+					"property":                mapping.Status,
+					models.PropertyTypeStatus: map[string]interface{}{"does_not_equal": status},
+				})
+			}
+		}
+		return conditions
+	}
+	return nil
+}
+
+// matchesFilter applies the conditions Notion cannot evaluate to a todo
+func matchesFilter(todo models.TodoItem, filter models.TodoFilter) bool {
+	if filter.Overdue && todo.StatusGroup == models.StatusGroupComplete {
+		return false
+	}
+	return true
+}
+
+// localToday returns the current local date as YYYY-MM-DD
+func localToday() string {
+	return time.Now().Format("2006-01-02")
+}
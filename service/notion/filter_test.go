@@ -0,0 +1,269 @@
+package notion
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/notion/fakenotion"
+)
+
+func TestQueryFilter(t *testing.T) {
+	selectMapping := models.PropertyMapping{
+		Title:           "Name",
+		Status:          "Status",
+		StatusType:      models.PropertyTypeSelect,
+		DueDate:         "Due",
+		Priority:        "Priority",
+		PriorityOptions: []string{"High", "Low"},
+		Tags:            "Tags",
+		Assignee:        "Owner",
+	}
+	statusMapping := selectMapping
+	statusMapping.StatusType = models.PropertyTypeStatus
+	statusMapping.StatusGroups = map[string]string{"Not started": models.StatusGroupTodo, "Shipped": models.StatusGroupComplete}
+	checkboxMapping := selectMapping
+	checkboxMapping.Status = "Done"
+	checkboxMapping.StatusType = models.PropertyTypeCheckbox
+	bareMapping := models.PropertyMapping{Title: "Name", Status: "Status", StatusType: models.PropertyTypeSelect}
+
+	const today = "2025-03-12"
+	tests := []struct {
+		name    string
+		mapping models.PropertyMapping
+		filter  models.TodoFilter
+		// want is the JSON of the filter conditions, sorts the JSON of the
+		// sorts; both are empty when not sent
+		want    string
+		sorts   string
+		wantErr string
+	}{
+		{name: "empty", mapping: selectMapping},
+		{
+			name:    "select status",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{Statuses: []string{"Done"}},
+			want:    `[{"property":"Status","select":{"equals":"Done"}}]`,
+		},
+		{
+			name:    "any of several statuses",
+			mapping: statusMapping,
+			filter:  models.TodoFilter{Statuses: []string{"Not started", "Shipped"}},
+			want:    `[{"or":[{"property":"Status","status":{"equals":"Not started"}},{"property":"Status","status":{"equals":"Shipped"}}]}]`,
+		},
+		{
+			name:    "checkbox statuses",
+			mapping: checkboxMapping,
+			filter:  models.TodoFilter{Statuses: []string{"Todo", "In Progress", "done"}},
+			// Todo and In Progress both mean unchecked, so one condition is sent
+			want: `[{"or":[{"checkbox":{"equals":false},"property":"Done"},{"checkbox":{"equals":true},"property":"Done"}]}]`,
+		},
+		{
+			name:    "title",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{TitleContains: "report"},
+			want:    `[{"property":"Name","title":{"contains":"report"}}]`,
+		},
+		{
+			name:    "priority by exact name",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{Priorities: []string{"high"}},
+			want:    `[{"property":"Priority","select":{"equals":"High"}}]`,
+		},
+		{
+			name:    "unknown priority",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{Priorities: []string{"Urgent"}},
+			wantErr: `unknown priority "Urgent"`,
+		},
+		{
+			name:    "every tag",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{Tags: []string{"home", "work"}},
+			want:    `[{"multi_select":{"contains":"home"},"property":"Tags"},{"multi_select":{"contains":"work"},"property":"Tags"}]`,
+		},
+		{
+			name:    "assignees",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{Assignees: []string{"u1", "u2"}},
+			want:    `[{"or":[{"people":{"contains":"u1"},"property":"Owner"},{"people":{"contains":"u2"},"property":"Owner"}]}]`,
+		},
+		{
+			name:    "due range",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{DueAfter: "2025-03-01", DueBefore: "2025-03-31"},
+			want:    `[{"date":{"before":"2025-03-31"},"property":"Due"},{"date":{"after":"2025-03-01"},"property":"Due"}]`,
+		},
+		{
+			name:    "due on",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{DueOn: "2025-03-14"},
+			want:    `[{"date":{"equals":"2025-03-14"},"property":"Due"}]`,
+		},
+		{
+			name:    "no due date",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{NoDueDate: true},
+			want:    `[{"date":{"is_empty":true},"property":"Due"}]`,
+		},
+		{
+			// Select statuses are checked once the results are read
+			name:    "overdue select",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{Overdue: true},
+			want:    `[{"date":{"before":"2025-03-12"},"property":"Due"}]`,
+		},
+		{
+			name:    "overdue status",
+			mapping: statusMapping,
+			filter:  models.TodoFilter{Overdue: true},
+			want:    `[{"date":{"before":"2025-03-12"},"property":"Due"},{"property":"Status","status":{"does_not_equal":"Shipped"}}]`,
+		},
+		{
+			name:    "overdue checkbox",
+			mapping: checkboxMapping,
+			filter:  models.TodoFilter{Overdue: true},
+			want:    `[{"date":{"before":"2025-03-12"},"property":"Due"},{"checkbox":{"equals":false},"property":"Done"}]`,
+		},
+		{
+			name:    "created and edited since",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{CreatedSince: "2025-03-01", EditedSince: "2025-03-10"},
+			want:    `[{"created_time":{"on_or_after":"2025-03-01"},"timestamp":"created_time"},{"last_edited_time":{"on_or_after":"2025-03-10"},"timestamp":"last_edited_time"}]`,
+		},
+		{
+			name:    "sorts",
+			mapping: selectMapping,
+			filter: models.TodoFilter{Sorts: []models.TodoSort{
+				{Key: models.SortKeyDue, Descending: true},
+				{Key: models.SortKeyTitle},
+				{Key: models.SortKeyEdited},
+			}},
+			sorts: `[{"direction":"descending","property":"Due"},{"direction":"ascending","property":"Name"},{"direction":"ascending","timestamp":"last_edited_time"}]`,
+		},
+		{
+			name:    "due filter without date property",
+			mapping: bareMapping,
+			filter:  models.TodoFilter{DueOn: "2025-03-14"},
+			wantErr: "no date property",
+		},
+		{
+			name:    "tags without tags property",
+			mapping: bareMapping,
+			filter:  models.TodoFilter{Tags: []string{"home"}},
+			wantErr: "no tags property",
+		},
+		{
+			name:    "due sort without date property",
+			mapping: bareMapping,
+			filter:  models.TodoFilter{Sorts: []models.TodoSort{{Key: models.SortKeyDue}}},
+			wantErr: "no date property",
+		},
+		{
+			name:    "contradictory dates",
+			mapping: selectMapping,
+			filter:  models.TodoFilter{NoDueDate: true, DueOn: "2025-03-14"},
+			wantErr: "cannot be combined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, sorts, err := queryFilter(tt.mapping, tt.filter, today)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" {
+				if filter != nil {
+					t.Errorf("sent filter %+v, want none", filter.And)
+				}
+			} else if filter == nil {
+				t.Errorf("sent no filter, want %s", tt.want)
+			} else {
+				assertJSON(t, filter.And, rawJSON(t, tt.want))
+			}
+			if tt.sorts == "" {
+				if len(sorts) > 0 {
+					t.Errorf("sent sorts %v, want none", sorts)
+				}
+			} else {
+				assertJSON(t, sorts, rawJSON(t, tt.sorts))
+			}
+		})
+	}
+}
+
+// TestQueryPagesFilter checks that translated filters select the expected
+// todos of the fake server
+func TestQueryPagesFilter(t *testing.T) {
+	server, client := newTestClient(t, fakenotion.TodoSchema())
+	ctx := context.Background()
+	date := func(start string) map[string]interface{} {
+		return map[string]interface{}{"date": map[string]interface{}{"start": start}}
+	}
+	status := func(name string) map[string]interface{} {
+		return map[string]interface{}{"select": map[string]interface{}{"name": name}}
+	}
+	addPage(t, server, "Write report", map[string]interface{}{"Status": status("Todo"), "Due Date": date("2020-01-10")})
+	addPage(t, server, "Review report", map[string]interface{}{"Status": status("In Progress"), "Due Date": date("2999-06-01")})
+	addPage(t, server, "Send invoice", map[string]interface{}{"Status": status("Done"), "Due Date": date("2020-01-05")})
+	addPage(t, server, "Call plumber", map[string]interface{}{"Status": status("Todo")})
+
+	tests := []struct {
+		name   string
+		filter models.TodoFilter
+		want   []string
+	}{
+		{name: "all", want: []string{"Call plumber", "Review report", "Send invoice", "Write report"}},
+		{name: "status", filter: models.TodoFilter{Statuses: []string{"Todo"}}, want: []string{"Call plumber", "Write report"}},
+		{name: "statuses", filter: models.TodoFilter{Statuses: []string{"In Progress", "Done"}}, want: []string{"Review report", "Send invoice"}},
+		{name: "title", filter: models.TodoFilter{TitleContains: "report"}, want: []string{"Review report", "Write report"}},
+		{name: "due before", filter: models.TodoFilter{DueBefore: "2020-01-07"}, want: []string{"Send invoice"}},
+		{name: "due on", filter: models.TodoFilter{DueOn: "2999-06-01"}, want: []string{"Review report"}},
+		{name: "no due date", filter: models.TodoFilter{NoDueDate: true}, want: []string{"Call plumber"}},
+		// Done todos are not overdue, although their date has passed
+		{name: "overdue", filter: models.TodoFilter{Overdue: true}, want: []string{"Write report"}},
+		{name: "combined", filter: models.TodoFilter{TitleContains: "report", Statuses: []string{"Done"}}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos, err := client.QueryPages(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, todo := range todos {
+				titles = append(titles, todo.Title)
+			}
+			sort.Strings(titles)
+			if strings.Join(titles, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("got %v, want %v", titles, tt.want)
+			}
+		})
+	}
+
+	t.Run("sorted", func(t *testing.T) {
+		todos, err := client.QueryPages(ctx, models.TodoFilter{Sorts: []models.TodoSort{{Key: models.SortKeyDue, Descending: true}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var titles []string
+		for _, todo := range todos {
+			titles = append(titles, todo.Title)
+		}
+		// Todos without a date sort last
+		want := "Review report, Write report, Send invoice, Call plumber"
+		if got := strings.Join(titles, ", "); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+}
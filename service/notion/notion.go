@@ -1,10 +1,43 @@
 package notion
 
-import "github.com/caffeines/notion-todo/models"
+import (
+	"context"
 
+	"github.com/caffeines/notion-todo/models"
+)
+
+// Notion is the Notion API client. Every call honours ctx cancellation.
 type Notion interface {
-	AddPage(title, date string) error
-	QueryPages(status, title string) ([]models.TodoItem, error)
-	UpdatePageStatus(pageID, status string) error
-	DeletePage(pageID string) error
+	// AddPage creates a todo with optional properties and page content.
+	// Assignees may be given by name, email or ID. A *PartialPageError
+	// reports a todo created without all of its content.
+	AddPage(ctx context.Context, todo models.NewTodo, body []models.BlockData) error
+	// QueryPages returns every todo matching filter, following pagination cursors
+	QueryPages(ctx context.Context, filter models.TodoFilter) ([]models.TodoItem, error)
+	// QueryPagesCursor returns a single page of results starting at cursor
+	QueryPagesCursor(ctx context.Context, filter models.TodoFilter, cursor string) (*models.TodoPage, error)
+	// IteratePages streams todos matching filter to fn until fn returns false
+	IteratePages(ctx context.Context, filter models.TodoFilter, fn func(todo models.TodoItem) bool) error
+	// GetPage returns a todo of the database by its page ID
+	GetPage(ctx context.Context, pageID string) (*models.TodoItem, error)
+	UpdatePageStatus(ctx context.Context, pageID, status string) error
+	// UpdatePage changes the properties of a page
+	UpdatePage(ctx context.Context, pageID string, update models.PageUpdate) error
+	DeletePage(ctx context.Context, pageID string) error
+	// GetDatabase returns the configured database and its property schema
+	GetDatabase(ctx context.Context) (*models.NotionDatabase, error)
+	// DiscoverProperties detects and saves the property mapping of the database
+	DiscoverProperties(ctx context.Context) (*models.PropertyMapping, error)
+	// GetBlocks returns the content of a page or block, including nested blocks
+	GetBlocks(ctx context.Context, blockID string) ([]models.NotionBlock, error)
+	// AppendBlocks adds blocks to the end of a page or block
+	AppendBlocks(ctx context.Context, blockID string, blocks []models.BlockData) error
+	// ListSubTasks returns the top-level to_do blocks of a page
+	ListSubTasks(ctx context.Context, pageID string) ([]models.SubTask, error)
+	// SetSubTaskChecked checks or unchecks a to_do block
+	SetSubTaskChecked(ctx context.Context, blockID string, checked bool) error
+	// ListUsers returns the people and bots of the workspace
+	ListUsers(ctx context.Context) ([]models.NotionUser, error)
+	// ListStatuses returns the status options defined by the database schema
+	ListStatuses(ctx context.Context) ([]models.StatusOption, error)
 }
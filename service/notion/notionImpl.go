@@ -1,12 +1,14 @@
 package notion
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/caffeines/notion-todo/service/config"
 	"github.com/caffeines/notion-todo/service/utility"
@@ -18,6 +20,11 @@ import (
 // notionImpl is the implementation of Notion interface
 type notionImpl struct {
 	credentialService config.Credential
+	transport         *transport
+
+	// users caches the workspace users for resolving assignees
+	usersMu sync.Mutex
+	users   []models.NotionUser
 }
 
 var notion Notion
@@ -27,157 +34,171 @@ func NewNotionImpl(credService config.Credential) Notion {
 	if notion == nil {
 		notion = &notionImpl{
 			credentialService: credService,
+			transport:         newTransport(),
 		}
 	}
 	return notion
 }
 
-func (n *notionImpl) doRequest(req *http.Request) error {
-	config, err := n.credentialService.GetConfig()
-	if err != nil {
-		fmt.Println("Error getting config:", err)
-		return err
+// NewNotionSvc returns a service of its own for credService, to work with
+// several databases at once
+func NewNotionSvc(credService config.Credential) Notion {
+	return &notionImpl{
+		credentialService: credService,
+		transport:         newTransport(),
 	}
-	// Set the necessary headers
-	req.Header.Set("Content-Type", consts.CONTENT_TYPE)
-	req.Header.Set("Authorization", "Bearer "+config.Token)
-	req.Header.Set("Notion-Version", consts.NOTION_VERSION)
+}
 
-	// Send the HTTP request
-	client := http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Println("Error sending request:", err)
-		return err
+// apiURL returns the base API URL, honouring a configured override
+func apiURL(cfg *models.Config) string {
+	if cfg.APIURL != "" {
+		return strings.TrimRight(cfg.APIURL, "/")
 	}
-	defer resp.Body.Close()
+	return consts.API_URL
+}
 
-	// Read response body for better error reporting
-	body, readErr := io.ReadAll(resp.Body)
-	if readErr != nil {
-		return fmt.Errorf("failed to read response body: %v", readErr)
+// doRequest sends a request for path, relative to the API base URL, through
+// the shared transport and returns the response body. A nil payload sends an
+// empty body.
+func (n *notionImpl) doRequest(ctx context.Context, method, path string, payload interface{}, idempotent bool) ([]byte, error) {
+	if n.credentialService == nil {
+		return nil, errors.New("credential service is not initialized")
+	}
+	config, err := n.credentialService.GetConfig()
+	if err != nil {
+		return nil, err
 	}
 
-	// Check the response status code
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("notion API error (status %d): %s", resp.StatusCode, string(body))
+	var body []byte
+	if payload != nil {
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %v", err)
+		}
 	}
 
-	return nil
+	return n.transport.do(ctx, config, apiRequest{
+		method:     method,
+		url:        apiURL(config) + path,
+		body:       body,
+		idempotent: idempotent,
+	})
 }
 
-// AddPage adds a new page to the database
-func (n *notionImpl) AddPage(title, date string) error {
-	config, err := n.credentialService.GetConfig()
+// AddPage adds a new page to the database. Content beyond the children
+// limit of a single request is appended to the new page; when that fails
+// the page is kept and a *PartialPageError is returned.
+func (n *notionImpl) AddPage(ctx context.Context, todo models.NewTodo, body []models.BlockData) error {
+	config, err := n.credentialService.GetSettings()
 	if err != nil {
 		return err
 	}
-	url := fmt.Sprintf("%s/pages", consts.API_URL)
-	body, err := utility.GetCreateTodoBody(title, date, config.DatabaseID)
+	mapping, err := n.propertyMapping(ctx, config)
 	if err != nil {
 		return err
 	}
-
-	// Create a new HTTP request
-	req, err := http.NewRequest("POST", url, body)
+	if todo.Due != nil && mapping.DueDate == "" {
+		return errors.New("the database has no date property for due dates")
+	}
+	// Blocks past the limit, or from the first one with too many nested
+	// children, are appended once the page exists
+	split := min(len(body), consts.MAX_BLOCK_CHILDREN)
+	if i := slices.IndexFunc(body[:split], func(block models.BlockData) bool { return !fitsRequest(block) }); i >= 0 {
+		split = i
+	}
+	children, rest := body[:split], body[split:]
+	if todo.Assignees, err = n.resolveUsers(ctx, todo.Assignees); err != nil {
+		return err
+	}
+	payload, err := utility.GetCreateTodoPayload(mapping, todo, children, config.DatabaseID)
 	if err != nil {
 		return err
 	}
-	err = n.doRequest(req)
-	return err
-}
 
-// GetPages returns all the pages from the database
-func (n *notionImpl) GetPages() error {
-	url := fmt.Sprintf("%s/databases/%s/query", consts.API_URL, "databaseID")
-	req, err := http.NewRequest("POST", url, nil)
-	if err != nil {
+	// Creating a page is not idempotent, so only rate limited attempts are retried
+	respBody, err := n.doRequest(ctx, http.MethodPost, "/pages", payload, false)
+	if err != nil || len(rest) == 0 {
 		return err
 	}
-	err = n.doRequest(req)
-	return err
+
+	var page models.NotionPage
+	if err := json.Unmarshal(respBody, &page); err != nil {
+		return fmt.Errorf("failed to parse created page: %v", err)
+	}
+	if err := n.AppendBlocks(ctx, page.ID, rest); err != nil {
+		return &PartialPageError{PageID: page.ID, URL: page.URL, Err: err}
+	}
+	return nil
 }
 
-// QueryPages queries pages from the Notion database with optional filters
-func (n *notionImpl) QueryPages(status, title string) ([]models.TodoItem, error) {
-	config, err := n.credentialService.GetConfig()
+// QueryPages queries pages from the Notion database with optional filters,
+// walking every result page via start_cursor
+func (n *notionImpl) QueryPages(ctx context.Context, filter models.TodoFilter) ([]models.TodoItem, error) {
+	var todos []models.TodoItem
+	err := n.IteratePages(ctx, filter, func(todo models.TodoItem) bool {
+		todos = append(todos, todo)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
+	return todos, nil
+}
 
-	url := fmt.Sprintf("%s/databases/%s/query", consts.API_URL, config.DatabaseID)
-
-	// Build query filter
-	var queryReq models.QueryRequest
-	var filters []map[string]interface{}
-
-	// Add status filter if provided
-	if status != "" {
-		statusFilter := map[string]interface{}{
-			"property": "Status",
-			"select": map[string]interface{}{
-				"equals": status,
-			},
+// IteratePages streams todos page by page, stopping early when fn returns false
+func (n *notionImpl) IteratePages(ctx context.Context, filter models.TodoFilter, fn func(todo models.TodoItem) bool) error {
+	cursor := ""
+	for {
+		page, err := n.QueryPagesCursor(ctx, filter, cursor)
+		if err != nil {
+			return err
 		}
-		filters = append(filters, statusFilter)
-	}
-
-	// Add title filter if provided
-	if title != "" {
-		titleFilter := map[string]interface{}{
-			"property": "Title",
-			"title": map[string]interface{}{
-				"contains": title,
-			},
+		for _, todo := range page.Todos {
+			if !fn(todo) {
+				return nil
+			}
 		}
-		filters = append(filters, titleFilter)
-	}
-
-	// Only add filter if we have conditions
-	if len(filters) > 0 {
-		queryReq.Filter = &models.QueryFilter{
-			And: filters,
+		if !page.HasMore || page.NextCursor == "" {
+			return nil
 		}
+		cursor = page.NextCursor
 	}
+}
 
-	// Convert to JSON
-	jsonData, err := json.Marshal(queryReq)
+// QueryPagesCursor queries a single page of results starting at cursor.
+// An empty cursor starts from the beginning of the database.
+func (n *notionImpl) QueryPagesCursor(ctx context.Context, filter models.TodoFilter, cursor string) (*models.TodoPage, error) {
+	config, err := n.credentialService.GetSettings()
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal query request: %v", err)
+		return nil, err
 	}
 
-	// Create HTTP request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	mapping, err := n.propertyMapping(ctx, config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, err
 	}
 
-	// Set headers
-	config, err = n.credentialService.GetConfig()
-	if err != nil {
+	path := fmt.Sprintf("/databases/%s/query", config.DatabaseID)
+
+	// Build query filter and sorts, matching assignees by ID
+	if filter.Assignees, err = n.resolveUsers(ctx, filter.Assignees); err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", consts.CONTENT_TYPE)
-	req.Header.Set("Authorization", "Bearer "+config.Token)
-	req.Header.Set("Notion-Version", consts.NOTION_VERSION)
-
-	// Send request
-	client := http.Client{}
-	resp, err := client.Do(req)
+	filterReq, sorts, err := queryFilter(mapping, filter, localToday())
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
+	queryReq := models.QueryRequest{
+		Filter:      filterReq,
+		Sorts:       sorts,
+		StartCursor: cursor,
+		PageSize:    consts.PAGE_SIZE,
 	}
 
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("notion API error (status %d): %s", resp.StatusCode, string(body))
+	// Querying is read-only, so it is safe to retry
+	body, err := n.doRequest(ctx, http.MethodPost, path, queryReq, true)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse response
@@ -187,127 +208,125 @@ func (n *notionImpl) QueryPages(status, title string) ([]models.TodoItem, error)
 	}
 
 	// Convert to TodoItems
-	var todos []models.TodoItem
-	for _, page := range queryResp.Results {
-		todos = append(todos, page.ToTodoItem())
+	page := &models.TodoPage{
+		HasMore: queryResp.HasMore,
+	}
+	if queryResp.NextCursor != nil {
+		page.NextCursor = *queryResp.NextCursor
+	}
+	for _, result := range queryResp.Results {
+		if todo := result.ToTodoItem(mapping); matchesFilter(todo, filter) {
+			page.Todos = append(page.Todos, todo)
+		}
 	}
 
-	return todos, nil
+	return page, nil
 }
 
-// UpdatePageStatus updates the status of a specific page in Notion
-func (n *notionImpl) UpdatePageStatus(pageID, status string) error {
-	if n.credentialService == nil {
-		return errors.New("credential service is not initialized")
+// GetPage fetches a single page. A page of another database, or one in the
+// trash, is reported as not found, as Notion does for pages that are not
+// shared with the integration.
+func (n *notionImpl) GetPage(ctx context.Context, pageID string) (*models.TodoItem, error) {
+	config, err := n.credentialService.GetSettings()
+	if err != nil {
+		return nil, err
 	}
-
-	config, err := n.credentialService.GetConfig()
+	mapping, err := n.propertyMapping(ctx, config)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	url := fmt.Sprintf("%s/pages/%s", consts.API_URL, pageID)
+	// Reading is safe to retry
+	body, err := n.doRequest(ctx, http.MethodGet, fmt.Sprintf("/pages/%s", pageID), nil, true)
+	if err != nil {
+		return nil, err
+	}
+	var page models.NotionPage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse page: %v", err)
+	}
+	if page.Archived || !utility.SameID(page.Parent.DatabaseID, config.DatabaseID) {
+		return nil, &APIError{
+			Status:  http.StatusNotFound,
+			Code:    CodeObjectNotFound,
+			Message: fmt.Sprintf("Could not find page with ID: %s. It is not a todo of the database.", pageID),
+		}
+	}
+	todo := page.ToTodoItem(mapping)
+	return &todo, nil
+}
 
-	// Create request body for status update
-	updateReq := map[string]interface{}{
-		"properties": map[string]interface{}{
-			"Status": map[string]interface{}{
-				"select": map[string]interface{}{
-					"name": status,
-				},
+// statusFilter returns the filter matching status in the shape required by
+// the status property type
+func statusFilter(mapping models.PropertyMapping, status string) map[string]interface{} {
+	if mapping.StatusType == models.PropertyTypeCheckbox {
+		return map[string]interface{}{
+			"property": mapping.Status,
+			models.PropertyTypeCheckbox: map[string]interface{}{
+				"equals": mapping.StatusGroup(status) == models.StatusGroupComplete,
 			},
+		}
+	}
+	return map[string]interface{}{
+		"property": mapping.Status,
+		mapping.StatusType: map[string]interface{}{
+			"equals": status,
 		},
 	}
+}
 
-	// Convert to JSON
-	jsonData, err := json.Marshal(updateReq)
-	if err != nil {
-		return fmt.Errorf("failed to marshal update request: %v", err)
-	}
+// UpdatePageStatus updates the status of a specific page in Notion
+func (n *notionImpl) UpdatePageStatus(ctx context.Context, pageID, status string) error {
+	return n.UpdatePage(ctx, pageID, models.PageUpdate{Status: &status})
+}
 
-	// Create HTTP request
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(jsonData))
+// UpdatePage updates the title, due date, status, priority, tags and
+// assignees of a page in Notion
+func (n *notionImpl) UpdatePage(ctx context.Context, pageID string, update models.PageUpdate) error {
+	if update.IsEmpty() {
+		return errors.New("nothing to update")
+	}
+	config, err := n.credentialService.GetSettings()
 	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
+		return err
 	}
-
-	// Set headers
-	req.Header.Set("Content-Type", consts.CONTENT_TYPE)
-	req.Header.Set("Authorization", "Bearer "+config.Token)
-	req.Header.Set("Notion-Version", consts.NOTION_VERSION)
-
-	// Send request
-	client := http.Client{}
-	resp, err := client.Do(req)
+	mapping, err := n.propertyMapping(ctx, config)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
+		return err
 	}
-	defer resp.Body.Close()
-
-	// Read response body for error reporting
-	body, err := io.ReadAll(resp.Body)
+	if update.Assignees != nil {
+		assignees, err := n.resolveUsers(ctx, *update.Assignees)
+		if err != nil {
+			return err
+		}
+		update.Assignees = &assignees
+	}
+	properties, err := update.Properties(mapping)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %v", err)
+		return err
 	}
 
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("notion API error (status %d): %s", resp.StatusCode, string(body))
+	path := fmt.Sprintf("/pages/%s", pageID)
+
+	// Setting properties to fixed values is idempotent, so it is safe to retry
+	updateReq := map[string]interface{}{
+		"properties": properties,
 	}
 
-	return nil
+	_, err = n.doRequest(ctx, http.MethodPatch, path, updateReq, true)
+	return err
 }
 
 // DeletePage deletes a page from Notion (archives it)
-func (n *notionImpl) DeletePage(pageID string) error {
-	config, err := n.credentialService.GetConfig()
-	if err != nil {
-		return fmt.Errorf("failed to get config: %v", err)
-	}
-
-	// Notion API URL for updating a page (we archive it by setting archived: true)
-	url := fmt.Sprintf("https://api.notion.com/v1/pages/%s", pageID)
+func (n *notionImpl) DeletePage(ctx context.Context, pageID string) error {
+	// Notion API path for updating a page (we archive it by setting archived: true)
+	path := fmt.Sprintf("/pages/%s", pageID)
 
 	// Create the request payload to archive the page
 	updateReq := map[string]interface{}{
 		"archived": true,
 	}
 
-	// Convert to JSON
-	jsonData, err := json.Marshal(updateReq)
-	if err != nil {
-		return fmt.Errorf("failed to marshal delete request: %v", err)
-	}
-
-	// Create HTTP request
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", consts.CONTENT_TYPE)
-	req.Header.Set("Authorization", "Bearer "+config.Token)
-	req.Header.Set("Notion-Version", consts.NOTION_VERSION)
-
-	// Send request
-	client := http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body for error reporting
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %v", err)
-	}
-
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("notion API error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	return nil
+	_, err := n.doRequest(ctx, http.MethodPatch, path, updateReq, true)
+	return err
 }
@@ -0,0 +1,106 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/notion/fakenotion"
+)
+
+const testDatabaseID = "0f1e2d3c4b5a69788796a5b4c3d2e1f0"
+
+// newTestClient starts a fake Notion server with a database of schema and
+// returns a client configured for it through the environment. The property
+// mapping is discovered up front, and retries do not wait.
+func newTestClient(t *testing.T, schema map[string]map[string]interface{}) (*fakenotion.Server, *notionImpl) {
+	t.Helper()
+	server := fakenotion.NewServer()
+	t.Cleanup(server.Close)
+	server.AddDatabase(testDatabaseID, "Todo", schema)
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv(consts.EnvToken, "secret_test")
+	t.Setenv(consts.EnvDatabaseID, testDatabaseID)
+	t.Setenv(consts.EnvAPIURL, server.APIURL())
+
+	credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+	client := NewNotionSvc(credService).(*notionImpl)
+	client.transport.wait = func(ctx context.Context, d time.Duration) error {
+		return ctx.Err()
+	}
+	if _, err := client.DiscoverProperties(context.Background()); err != nil {
+		t.Fatalf("DiscoverProperties: %v", err)
+	}
+	return server, client
+}
+
+// addPage creates a page titled title in the test database, with further
+// write-shaped properties
+func addPage(t *testing.T, server *fakenotion.Server, title string, properties map[string]interface{}) string {
+	t.Helper()
+	props := map[string]interface{}{
+		"Title": map[string]interface{}{
+			"title": []interface{}{map[string]interface{}{"text": map[string]interface{}{"content": title}}},
+		},
+	}
+	for name, value := range properties {
+		props[name] = value
+	}
+	id, err := server.AddPage(testDatabaseID, props)
+	if err != nil {
+		t.Fatalf("AddPage(%q): %v", title, err)
+	}
+	return id
+}
+
+// requestsTo returns the requests sent with method to a path ending in suffix
+func requestsTo(server *fakenotion.Server, method, suffix string) []fakenotion.Request {
+	var requests []fakenotion.Request
+	for _, request := range server.Requests() {
+		if request.Method == method && strings.HasSuffix(request.Path, suffix) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// assertJSON fails when got and want do not encode to the same JSON
+func assertJSON(t *testing.T, got, want interface{}) {
+	t.Helper()
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Decode again so map keys and number types compare alike
+	var gotValue, wantValue interface{}
+	_ = json.Unmarshal(gotJSON, &gotValue)
+	_ = json.Unmarshal(wantJSON, &wantValue)
+	gotJSON, _ = json.Marshal(gotValue)
+	wantJSON, _ = json.Marshal(wantValue)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("got  %s\nwant %s", gotJSON, wantJSON)
+	}
+}
+
+// rawJSON decodes a JSON literal for comparison with assertJSON
+func rawJSON(t *testing.T, text string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		t.Fatalf("bad JSON %s: %v", text, err)
+	}
+	return value
+}
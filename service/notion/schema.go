@@ -0,0 +1,277 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+// Property names preferred when a database has several candidates, in order
+var (
+	statusNames   = []string{"status", "state", "stage", "progress"}
+	checkboxNames = []string{"done", "completed", "complete", "finished"}
+	dueDateNames  = []string{"due date", "due", "deadline", "date", "due on"}
+	priorityNames = []string{"priority", "prio", "importance", "urgency"}
+	tagNames      = []string{"tags", "tag", "labels", "label", "categories", "category"}
+	assigneeNames = []string{"assignee", "assignees", "assigned to", "owner", "owners"}
+	// defaultStatusNames are preferred initial statuses for select properties
+	defaultStatusNames = []string{"todo", "to do", "to-do", "not started", "pending", "backlog"}
+)
+
+// GetDatabase fetches the configured database including its property schema
+func (n *notionImpl) GetDatabase(ctx context.Context) (*models.NotionDatabase, error) {
+	config, err := n.credentialService.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := n.doRequest(ctx, http.MethodGet, fmt.Sprintf("/databases/%s", config.DatabaseID), nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var database models.NotionDatabase
+	if err := json.Unmarshal(body, &database); err != nil {
+		return nil, fmt.Errorf("failed to parse database: %v", err)
+	}
+	return &database, nil
+}
+
+// DiscoverProperties detects the title, status and due date properties of
+// the database by type and saves the mapping to the config
+func (n *notionImpl) DiscoverProperties(ctx context.Context) (*models.PropertyMapping, error) {
+	config, err := n.credentialService.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	database, err := n.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := discoverMapping(database)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := n.credentialService.SaveProperties(config.DatabaseID, mapping); err != nil {
+		return nil, fmt.Errorf("failed to save property mapping: %v", err)
+	}
+	return mapping, nil
+}
+
+// ListStatuses returns the options of the status property in schema order
+func (n *notionImpl) ListStatuses(ctx context.Context) ([]models.StatusOption, error) {
+	config, err := n.credentialService.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := n.propertyMapping(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	database, err := n.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	property, ok := database.Properties[mapping.Status]
+	if !ok {
+		return nil, fmt.Errorf("status property %q not found in the database, run 'todo config --refresh'", mapping.Status)
+	}
+
+	var statuses []models.StatusOption
+	switch {
+	case property.Type == models.PropertyTypeStatus && property.Status != nil:
+		groups := statusGroups(property)
+		for _, option := range property.Status.Options {
+			statuses = append(statuses, models.StatusOption{
+				Name:  option.Name,
+				Color: option.Color,
+				Group: groups[option.Name],
+			})
+		}
+	case property.Type == models.PropertyTypeSelect && property.Select != nil:
+		for _, option := range property.Select.Options {
+			statuses = append(statuses, models.StatusOption{
+				Name:  option.Name,
+				Color: option.Color,
+				Group: mapping.StatusGroup(option.Name),
+			})
+		}
+	case property.Type == models.PropertyTypeCheckbox:
+		statuses = []models.StatusOption{
+			{Name: models.CheckboxStatusTodo, Color: "default", Group: models.StatusGroupTodo},
+			{Name: models.CheckboxStatusDone, Color: "green", Group: models.StatusGroupComplete},
+		}
+	default:
+		return nil, fmt.Errorf("status property %q has unsupported type %s", mapping.Status, property.Type)
+	}
+	return statuses, nil
+}
+
+// propertyMapping returns the saved mapping, discovering it on first use
+func (n *notionImpl) propertyMapping(ctx context.Context, config *models.Config) (models.PropertyMapping, error) {
+	if config.Properties != nil {
+		return *config.Properties, nil
+	}
+	mapping, err := n.DiscoverProperties(ctx)
+	if err != nil {
+		return models.PropertyMapping{}, fmt.Errorf("failed to discover database properties: %w", err)
+	}
+	return *mapping, nil
+}
+
+// discoverMapping picks the properties used for todos from the schema
+func discoverMapping(database *models.NotionDatabase) (*models.PropertyMapping, error) {
+	mapping := &models.PropertyMapping{}
+
+	titles := propertiesOfType(database, models.PropertyTypeTitle)
+	if len(titles) == 0 {
+		return nil, fmt.Errorf("database has no title property")
+	}
+	mapping.Title = titles[0].Name
+
+	// Prefer Notion's native status type over select properties
+	if statuses := propertiesOfType(database, models.PropertyTypeStatus); len(statuses) > 0 {
+		status := preferByName(statuses, statusNames)
+		mapping.Status = status.Name
+		mapping.StatusType = models.PropertyTypeStatus
+		mapping.DefaultStatus = defaultStatusOption(status)
+		mapping.StatusGroups = statusGroups(status)
+	} else if selects := propertiesOfType(database, models.PropertyTypeSelect); len(selects) > 0 {
+		status := preferByName(selects, statusNames)
+		mapping.Status = status.Name
+		mapping.StatusType = models.PropertyTypeSelect
+		mapping.DefaultStatus = defaultSelectOption(status)
+	} else if checkboxes := propertiesOfType(database, models.PropertyTypeCheckbox); len(checkboxes) > 0 {
+		mapping.Status = preferByName(checkboxes, checkboxNames).Name
+		mapping.StatusType = models.PropertyTypeCheckbox
+		mapping.DefaultStatus = models.CheckboxStatusTodo
+	} else {
+		return nil, fmt.Errorf("database has no status, select or checkbox property to track progress")
+	}
+
+	if dates := propertiesOfType(database, models.PropertyTypeDate); len(dates) > 0 {
+		mapping.DueDate = preferByName(dates, dueDateNames).Name
+	}
+
+	// A select is only taken as the priority by name, since any select
+	// could be the status or something else entirely
+	for _, name := range priorityNames {
+		for _, property := range propertiesOfType(database, models.PropertyTypeSelect) {
+			if mapping.Priority == "" && property.Name != mapping.Status && strings.EqualFold(property.Name, name) {
+				mapping.Priority = property.Name
+				mapping.PriorityOptions = optionNames(property.Select)
+			}
+		}
+	}
+	if tags := propertiesOfType(database, models.PropertyTypeMultiSelect); len(tags) > 0 {
+		mapping.Tags = preferByName(tags, tagNames).Name
+	}
+	if people := propertiesOfType(database, models.PropertyTypePeople); len(people) > 0 {
+		mapping.Assignee = preferByName(people, assigneeNames).Name
+	}
+
+	return mapping, nil
+}
+
+// propertiesOfType returns the properties of the given type sorted by name
+func propertiesOfType(database *models.NotionDatabase, propertyType string) []models.NotionDatabaseProperty {
+	var properties []models.NotionDatabaseProperty
+	for name, property := range database.Properties {
+		if property.Type == propertyType {
+			if property.Name == "" {
+				property.Name = name
+			}
+			properties = append(properties, property)
+		}
+	}
+	sort.Slice(properties, func(i, j int) bool {
+		return properties[i].Name < properties[j].Name
+	})
+	return properties
+}
+
+// preferByName returns the first property whose name matches one of names,
+// falling back to the first property
+func preferByName(properties []models.NotionDatabaseProperty, names []string) models.NotionDatabaseProperty {
+	for _, name := range names {
+		for _, property := range properties {
+			if strings.EqualFold(property.Name, name) {
+				return property
+			}
+		}
+	}
+	return properties[0]
+}
+
+// optionNames returns the names of select options in schema order
+func optionNames(config *models.NotionSelectConfig) []string {
+	if config == nil {
+		return nil
+	}
+	var names []string
+	for _, option := range config.Options {
+		names = append(names, option.Name)
+	}
+	return names
+}
+
+// defaultStatusOption returns the first option of the To-do group
+func defaultStatusOption(property models.NotionDatabaseProperty) string {
+	if property.Status == nil || len(property.Status.Options) == 0 {
+		return ""
+	}
+	for _, group := range property.Status.Groups {
+		if !strings.EqualFold(group.Name, models.StatusGroupTodo) || len(group.OptionIDs) == 0 {
+			continue
+		}
+		for _, option := range property.Status.Options {
+			if option.ID == group.OptionIDs[0] {
+				return option.Name
+			}
+		}
+	}
+	return property.Status.Options[0].Name
+}
+
+// statusGroups maps every status option name to the name of its group
+func statusGroups(property models.NotionDatabaseProperty) map[string]string {
+	if property.Status == nil {
+		return nil
+	}
+	optionNames := map[string]string{}
+	for _, option := range property.Status.Options {
+		optionNames[option.ID] = option.Name
+	}
+	groups := map[string]string{}
+	for _, group := range property.Status.Groups {
+		for _, id := range group.OptionIDs {
+			if name, ok := optionNames[id]; ok {
+				groups[name] = group.Name
+			}
+		}
+	}
+	return groups
+}
+
+// defaultSelectOption returns the option that looks like a not-started
+// state, falling back to the first option
+func defaultSelectOption(property models.NotionDatabaseProperty) string {
+	if property.Select == nil || len(property.Select.Options) == 0 {
+		return ""
+	}
+	for _, name := range defaultStatusNames {
+		for _, option := range property.Select.Options {
+			if strings.EqualFold(option.Name, name) {
+				return option.Name
+			}
+		}
+	}
+	return property.Select.Options[0].Name
+}
@@ -0,0 +1,140 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+func TestDiscoverMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		want    models.PropertyMapping
+		wantErr string
+	}{
+		{
+			name: "status type with groups",
+			schema: `{
+				"Task": {"type": "title"},
+				"Stage": {"type": "status", "status": {
+					"options": [{"id": "a", "name": "Backlog"}, {"id": "b", "name": "Doing"}, {"id": "c", "name": "Shipped"}],
+					"groups": [
+						{"name": "To-do", "option_ids": ["a"]},
+						{"name": "In progress", "option_ids": ["b"]},
+						{"name": "Complete", "option_ids": ["c"]}
+					]
+				}},
+				"Kind": {"type": "select", "select": {"options": [{"name": "Bug"}]}},
+				"When": {"type": "date"}
+			}`,
+			want: models.PropertyMapping{
+				Title:         "Task",
+				Status:        "Stage",
+				StatusType:    models.PropertyTypeStatus,
+				DefaultStatus: "Backlog",
+				StatusGroups:  map[string]string{"Backlog": "To-do", "Doing": "In progress", "Shipped": "Complete"},
+				DueDate:       "When",
+			},
+		},
+		{
+			name: "select preferred by name",
+			schema: `{
+				"Name": {"type": "title"},
+				"Area": {"type": "select", "select": {"options": [{"name": "Home"}]}},
+				"State": {"type": "select", "select": {"options": [{"name": "Open"}, {"name": "Not started"}]}},
+				"Created": {"type": "date"},
+				"Deadline": {"type": "date"}
+			}`,
+			want: models.PropertyMapping{
+				Title:         "Name",
+				Status:        "State",
+				StatusType:    models.PropertyTypeSelect,
+				DefaultStatus: "Not started",
+				DueDate:       "Deadline",
+			},
+		},
+		{
+			name: "checkbox",
+			schema: `{
+				"Name": {"type": "title"},
+				"Archived": {"type": "checkbox"},
+				"Finished": {"type": "checkbox"}
+			}`,
+			want: models.PropertyMapping{
+				Title:         "Name",
+				Status:        "Finished",
+				StatusType:    models.PropertyTypeCheckbox,
+				DefaultStatus: models.CheckboxStatusTodo,
+			},
+		},
+		{
+			name:    "no title",
+			schema:  `{"Status": {"type": "checkbox"}}`,
+			wantErr: "no title property",
+		},
+		{
+			name:    "no status",
+			schema:  `{"Name": {"type": "title"}, "Due": {"type": "date"}}`,
+			wantErr: "no status, select or checkbox property",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &models.NotionDatabase{}
+			if err := json.Unmarshal([]byte(tt.schema), &database.Properties); err != nil {
+				t.Fatal(err)
+			}
+			got, err := discoverMapping(database)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("discoverMapping() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("discoverMapping() error = %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestDiscoverPropertiesSaved(t *testing.T) {
+	_, client := newTestClient(t, map[string]map[string]interface{}{
+		"Task": {"type": "title"},
+		"Progress": {"type": "select", "select": map[string]interface{}{
+			"options": []interface{}{
+				map[string]interface{}{"name": "Pending"},
+				map[string]interface{}{"name": "Done"},
+			},
+		}},
+	})
+
+	config, err := client.credentialService.GetSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Properties == nil {
+		t.Fatal("property mapping was not saved")
+	}
+	want := models.PropertyMapping{
+		Title:         "Task",
+		Status:        "Progress",
+		StatusType:    models.PropertyTypeSelect,
+		DefaultStatus: "Pending",
+	}
+	assertJSON(t, config.Properties, want)
+
+	statuses, err := client.ListStatuses(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertJSON(t, statuses, []models.StatusOption{
+		{Name: "Pending", Color: "default", Group: models.StatusGroupTodo},
+		{Name: "Done", Color: "default", Group: models.StatusGroupComplete},
+	})
+}
@@ -0,0 +1,181 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+)
+
+// sharedTransport pools connections across every Notion client
+var sharedTransport http.RoundTripper = http.DefaultTransport.(*http.Transport).Clone()
+
+// transport is the single HTTP layer used by every Notion API call. It sets
+// the Notion headers, applies timeouts, waits out 429 responses and retries
+// idempotent requests with jittered exponential backoff.
+type transport struct {
+	roundTripper http.RoundTripper
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+	wait         func(ctx context.Context, d time.Duration) error
+}
+
+// apiRequest describes a single Notion API call
+type apiRequest struct {
+	method string
+	url    string
+	body   []byte
+	// idempotent requests are retried on network errors and 5xx responses.
+	// Rate limited requests are always retried since Notion did not apply them.
+	idempotent bool
+}
+
+func newTransport() *transport {
+	return &transport{
+		roundTripper: sharedTransport,
+		baseDelay:    consts.RETRY_BASE_DELAY,
+		maxDelay:     consts.RETRY_MAX_DELAY,
+		wait:         wait,
+	}
+}
+
+// wait pauses for d, returning early with the context error on cancellation
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// timeout returns the configured per-attempt timeout
+func timeout(cfg *models.Config) time.Duration {
+	if cfg.RequestTimeout > 0 {
+		return time.Duration(cfg.RequestTimeout) * time.Second
+	}
+	return consts.DEFAULT_TIMEOUT
+}
+
+// maxRetries returns the configured retry budget
+func maxRetries(cfg *models.Config) int {
+	if cfg.MaxRetries < 0 {
+		return 0
+	}
+	if cfg.MaxRetries > 0 {
+		return cfg.MaxRetries
+	}
+	return consts.DEFAULT_MAX_RETRIES
+}
+
+// do sends the request, retrying as allowed, and returns the response body
+// of the first successful attempt. Cancelling ctx aborts both in-flight
+// requests and pending retries.
+func (t *transport) do(ctx context.Context, cfg *models.Config, r apiRequest) ([]byte, error) {
+	client := http.Client{
+		Transport: t.roundTripper,
+		Timeout:   timeout(cfg),
+	}
+	retries := maxRetries(cfg)
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, r.method, r.url, bytes.NewReader(r.body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", consts.CONTENT_TYPE)
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+		req.Header.Set("Notion-Version", consts.NOTION_VERSION)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if r.idempotent && attempt < retries {
+				if err := t.wait(ctx, t.backoff(attempt)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+
+		// Read response body for better error reporting
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			if r.idempotent && attempt < retries {
+				if err := t.wait(ctx, t.backoff(attempt)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, fmt.Errorf("failed to read response body: %v", err)
+		}
+
+		if attempt < retries {
+			if resp.StatusCode == http.StatusTooManyRequests {
+				if err := t.wait(ctx, t.retryAfter(resp, attempt)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if r.idempotent && isRetryableStatus(resp.StatusCode) {
+				if err := t.wait(ctx, t.backoff(attempt)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		// Check the response status code
+		if resp.StatusCode != http.StatusOK {
+			return nil, newAPIError(resp.StatusCode, body)
+		}
+		return body, nil
+	}
+}
+
+// backoff returns a full-jitter exponential delay for the given attempt
+func (t *transport) backoff(attempt int) time.Duration {
+	delay := t.baseDelay << uint(attempt)
+	if delay <= 0 || delay > t.maxDelay {
+		delay = t.maxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay))) + time.Millisecond
+}
+
+// retryAfter honours the Retry-After header sent with 429 responses,
+// falling back to the regular backoff when it is missing
+func (t *transport) retryAfter(resp *http.Response, attempt int) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return t.backoff(attempt)
+	}
+	delay := time.Duration(seconds) * time.Second
+	if delay > t.maxDelay {
+		delay = t.maxDelay
+	}
+	return delay
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
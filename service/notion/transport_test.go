@@ -0,0 +1,102 @@
+package notion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+)
+
+func TestTransportHeaders(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cfg := &models.Config{Profile: models.Profile{Token: "secret_headers"}}
+	if _, err := newTransport().do(context.Background(), cfg, apiRequest{method: http.MethodGet, url: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"Authorization":  "Bearer secret_headers",
+		"Notion-Version": consts.NOTION_VERSION,
+		"Content-Type":   consts.CONTENT_TYPE,
+	}
+	for name, value := range want {
+		if got := header.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestTransportTimeout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for the request timeout")
+	}
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	tr := newTransport()
+	var waits int
+	tr.wait = func(ctx context.Context, d time.Duration) error {
+		waits++
+		return nil
+	}
+	// Each attempt times out on its own, and idempotent calls are retried
+	cfg := &models.Config{RequestTimeout: 1, MaxRetries: 1}
+	start := time.Now()
+	_, err := tr.do(context.Background(), cfg, apiRequest{method: http.MethodGet, url: server.URL, idempotent: true})
+	if err == nil || !IsUnavailable(err) {
+		t.Fatalf("error = %v, want a network error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("took %v, want the 1s timeout per attempt", elapsed)
+	}
+	if n := requests.Load(); n != 2 || waits != 1 {
+		t.Errorf("sent %d requests after %d waits, want 2 after 1", n, waits)
+	}
+
+	// Writes are not retried, as Notion may have applied them
+	requests.Store(0)
+	_, err = tr.do(context.Background(), cfg, apiRequest{method: http.MethodPost, url: server.URL})
+	if err == nil || requests.Load() != 1 {
+		t.Errorf("sent %d requests, error %v, want one failed request", requests.Load(), err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tr := &transport{baseDelay: 100 * time.Millisecond, maxDelay: time.Second}
+	for attempt := 0; attempt < 70; attempt++ {
+		limit := tr.maxDelay
+		if attempt < 4 {
+			limit = tr.baseDelay << uint(attempt)
+		}
+		for i := 0; i < 20; i++ {
+			if d := tr.backoff(attempt); d <= 0 || d > limit+time.Millisecond {
+				t.Fatalf("backoff(%d) = %v, want within (0, %v]", attempt, d, limit+time.Millisecond)
+			}
+		}
+	}
+
+	defaults := &models.Config{}
+	if timeout(defaults) != consts.DEFAULT_TIMEOUT || maxRetries(defaults) != consts.DEFAULT_MAX_RETRIES {
+		t.Errorf("defaults: timeout %v, retries %d", timeout(defaults), maxRetries(defaults))
+	}
+	if got := maxRetries(&models.Config{MaxRetries: -1}); got != 0 {
+		t.Errorf("maxRetries(-1) = %d, want retries disabled", got)
+	}
+}
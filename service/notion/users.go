@@ -0,0 +1,68 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/utility"
+)
+
+// ListUsers returns the people and bots of the workspace, following
+// pagination cursors. The list is fetched once per run.
+func (n *notionImpl) ListUsers(ctx context.Context) ([]models.NotionUser, error) {
+	n.usersMu.Lock()
+	defer n.usersMu.Unlock()
+	if n.users != nil {
+		return n.users, nil
+	}
+
+	users := []models.NotionUser{}
+	cursor := ""
+	for {
+		query := url.Values{"page_size": {fmt.Sprint(consts.PAGE_SIZE)}}
+		if cursor != "" {
+			query.Set("start_cursor", cursor)
+		}
+		body, err := n.doRequest(ctx, http.MethodGet, "/users?"+query.Encode(), nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		var list models.NotionUserList
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("failed to parse users: %v", err)
+		}
+		users = append(users, list.Results...)
+		if !list.HasMore || list.NextCursor == nil || *list.NextCursor == "" {
+			break
+		}
+		cursor = *list.NextCursor
+	}
+	n.users = users
+	return users, nil
+}
+
+// resolveUsers returns the IDs of the users referenced by name, email or ID
+func (n *notionImpl) resolveUsers(ctx context.Context, refs []string) ([]string, error) {
+	if len(refs) == 0 {
+		return refs, nil
+	}
+	users, err := n.ListUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		id, err := utility.ResolveUserRef(users, ref)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
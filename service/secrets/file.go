@@ -0,0 +1,201 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/caffeines/notion-todo/service/files"
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters of new files, as recommended for interactive logins
+const (
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	keyLength = 32
+)
+
+// envelope is the encrypted file: the secrets as a JSON object sealed with
+// AES-256-GCM under a key derived from the passphrase with scrypt
+type envelope struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+type fileStore struct {
+	file files.File
+	// passphrase returns the passphrase, asking for confirmation when a
+	// new file is created
+	passphrase func(confirm bool) (string, error)
+
+	mu      sync.Mutex
+	loaded  bool
+	secrets map[string]string
+	// key, salt and the scrypt parameters are kept after the first read so
+	// later writes do not ask for the passphrase again
+	key     []byte
+	salt    []byte
+	n, r, p int
+}
+
+// NewFileStore returns a store encrypting its secrets into file with a
+// passphrase obtained from passphrase
+func NewFileStore(file files.File, passphrase func(confirm bool) (string, error)) Store {
+	return &fileStore{file: file, passphrase: passphrase}
+}
+
+func (s *fileStore) Name() string {
+	return BackendFile
+}
+
+func (s *fileStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return "", err
+	}
+	value, ok := s.secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *fileStore) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.secrets[key] = value
+	return s.save()
+}
+
+func (s *fileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.secrets[key]; !ok {
+		return nil
+	}
+	delete(s.secrets, key)
+	return s.save()
+}
+
+// load decrypts the file once; a missing file holds no secrets
+func (s *fileStore) load() error {
+	if s.loaded {
+		return nil
+	}
+	data, err := s.file.ReadFile()
+	if os.IsNotExist(err) {
+		s.secrets = map[string]string{}
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var sealed envelope
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return fmt.Errorf("invalid encrypted token file: %v", err)
+	}
+	if sealed.Version != 1 || sealed.KDF != "scrypt" {
+		return fmt.Errorf("unsupported encrypted token file version %d (%s)", sealed.Version, sealed.KDF)
+	}
+	passphrase, err := s.passphrase(false)
+	if err != nil {
+		return err
+	}
+	key, err := scrypt.Key([]byte(passphrase), sealed.Salt, sealed.N, sealed.R, sealed.P, keyLength)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	plain, err := aead.Open(nil, sealed.Nonce, sealed.Data, nil)
+	if err != nil {
+		return ErrWrongPassphrase
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return fmt.Errorf("invalid encrypted token file: %v", err)
+	}
+
+	s.secrets, s.key, s.salt, s.loaded = secrets, key, sealed.Salt, true
+	s.n, s.r, s.p = sealed.N, sealed.R, sealed.P
+	return nil
+}
+
+// save encrypts the secrets with a fresh nonce, deriving a key from a new
+// passphrase when the file did not exist
+func (s *fileStore) save() error {
+	if s.key == nil {
+		passphrase, err := s.passphrase(true)
+		if err != nil {
+			return err
+		}
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+		if err != nil {
+			return err
+		}
+		s.key, s.salt = key, salt
+		s.n, s.r, s.p = scryptN, scryptR, scryptP
+	}
+
+	plain, err := json.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(s.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data, err := json.Marshal(envelope{
+		Version: 1,
+		KDF:     "scrypt",
+		N:       s.n,
+		R:       s.r,
+		P:       s.p,
+		Salt:    s.salt,
+		Nonce:   nonce,
+		Data:    aead.Seal(nil, nonce, plain, nil),
+	})
+	if err != nil {
+		return err
+	}
+	return s.file.SaveFile(data)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
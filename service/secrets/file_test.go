@@ -0,0 +1,146 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// memFile is a files.File kept in memory
+type memFile struct {
+	data []byte
+}
+
+func (f *memFile) SaveFile(data []byte) error {
+	f.data = append([]byte(nil), data...)
+	return nil
+}
+
+func (f *memFile) ReadFile() ([]byte, error) {
+	if f.data == nil {
+		return nil, os.ErrNotExist
+	}
+	return f.data, nil
+}
+
+func (f *memFile) Path() (string, error) {
+	return "secrets.enc", nil
+}
+
+// passphrase returns a passphrase function always answering with value
+func passphrase(value string) func(confirm bool) (string, error) {
+	return func(confirm bool) (string, error) {
+		return value, nil
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	// Encrypt once, as deriving keys with scrypt is slow on purpose
+	sealed := &memFile{}
+	store := NewFileStore(sealed, passphrase("correct horse"))
+	if err := store.Set("default", "secret_abc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("work", "secret_def"); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed.data, []byte("secret_abc")) {
+		t.Fatal("the token is stored unencrypted")
+	}
+	var envelope map[string]interface{}
+	if err := json.Unmarshal(sealed.data, &envelope); err != nil {
+		t.Fatal(err)
+	}
+
+	// corrupt returns the sealed file with one field replaced
+	corrupt := func(field string, value interface{}) []byte {
+		changed := map[string]interface{}{}
+		for key, v := range envelope {
+			changed[key] = v
+		}
+		changed[field] = value
+		data, _ := json.Marshal(changed)
+		return data
+	}
+	flipped, _ := base64.StdEncoding.DecodeString(envelope["data"].(string))
+	flipped[len(flipped)/2] ^= 1
+
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase func(confirm bool) (string, error)
+		key        string
+		want       string
+		wantErr    error
+		wantText   string // error text, for errors without a value
+	}{
+		{name: "round trip", data: sealed.data, passphrase: passphrase("correct horse"), key: "default", want: "secret_abc"},
+		{name: "second key", data: sealed.data, passphrase: passphrase("correct horse"), key: "work", want: "secret_def"},
+		{name: "missing key", data: sealed.data, passphrase: passphrase("correct horse"), key: "home", wantErr: ErrNotFound},
+		{name: "wrong passphrase", data: sealed.data, passphrase: passphrase("battery staple"), key: "default", wantErr: ErrWrongPassphrase},
+		{
+			name:       "no passphrase",
+			data:       sealed.data,
+			passphrase: func(bool) (string, error) { return "", ErrPassphraseRequired },
+			key:        "default",
+			wantErr:    ErrPassphraseRequired,
+		},
+		{name: "no file", passphrase: passphrase("correct horse"), key: "default", wantErr: ErrNotFound},
+		{name: "not JSON", data: []byte("secret_abc"), passphrase: passphrase("correct horse"), key: "default", wantText: "invalid encrypted token file"},
+		{name: "truncated", data: sealed.data[:len(sealed.data)/2], passphrase: passphrase("correct horse"), key: "default", wantText: "invalid encrypted token file"},
+		{name: "tampered data", data: corrupt("data", flipped), passphrase: passphrase("correct horse"), key: "default", wantErr: ErrWrongPassphrase},
+		{name: "other nonce", data: corrupt("nonce", "AAAAAAAAAAAAAAAA"), passphrase: passphrase("correct horse"), key: "default", wantErr: ErrWrongPassphrase},
+		{name: "unknown version", data: corrupt("version", 2), passphrase: passphrase("correct horse"), key: "default", wantText: "unsupported encrypted token file version 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewFileStore(&memFile{data: tt.data}, tt.passphrase)
+			got, err := store.Get(tt.key)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantText) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantText)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case got != tt.want:
+				t.Errorf("Get(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("update and delete", func(t *testing.T) {
+		file := &memFile{data: sealed.data}
+		asked := 0
+		store := NewFileStore(file, func(confirm bool) (string, error) {
+			asked++
+			return "correct horse", nil
+		})
+		if err := store.Set("default", "secret_new"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Delete("work"); err != nil {
+			t.Fatal(err)
+		}
+		if asked != 1 {
+			t.Errorf("asked for the passphrase %d times, want once", asked)
+		}
+
+		reopened := NewFileStore(&memFile{data: file.data}, passphrase("correct horse"))
+		if got, err := reopened.Get("default"); err != nil || got != "secret_new" {
+			t.Errorf("Get(default) = %q, %v after updating", got, err)
+		}
+		if _, err := reopened.Get("work"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(work) error = %v after deleting", err)
+		}
+	})
+}
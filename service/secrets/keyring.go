@@ -0,0 +1,188 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// keyringService is the service name secrets are stored under
+const keyringService = "notion-todo"
+
+// keyringStore keeps secrets in the login keychain on macOS and in the
+// Secret Service (GNOME Keyring, KWallet) elsewhere, through their command
+// line tools
+type keyringStore struct {
+	// run starts the keyring tools
+	run Runner
+
+	mu sync.Mutex
+	// cache keeps the secrets read or written during this run, so the tools
+	// are not started for every API call
+	cache map[string]string
+}
+
+// Runner runs the keyring tool name with input on standard input and
+// returns what it printed. A tool exiting with an error is a *ToolError.
+type Runner func(input, name string, args ...string) (string, error)
+
+// ToolError reports a keyring tool that exited with an error
+type ToolError struct {
+	Tool   string
+	Code   int
+	Stderr string
+}
+
+func (e *ToolError) Error() string {
+	if e.Stderr == "" {
+		return fmt.Sprintf("%s exited with status %d", e.Tool, e.Code)
+	}
+	return fmt.Sprintf("%s exited with status %d: %s", e.Tool, e.Code, e.Stderr)
+}
+
+// securityItemNotFound is the exit status of security for a missing item,
+// errSecItemNotFound (-25300) truncated to a byte
+const securityItemNotFound = 44
+
+// NewKeyringStore returns a store backed by the keyring of the system,
+// driving its tools with run
+func NewKeyringStore(run Runner) Store {
+	return &keyringStore{run: run, cache: map[string]string{}}
+}
+
+// KeyringAvailable reports whether a keyring can be used: the security tool
+// on macOS, or secret-tool with a session bus elsewhere
+func KeyringAvailable() bool {
+	if runtime.GOOS == "darwin" {
+		_, err := exec.LookPath("security")
+		return err == nil
+	}
+	if runtime.GOOS == "windows" || os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func (*keyringStore) Name() string {
+	return BackendKeyring
+}
+
+func (s *keyringStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if value, ok := s.cache[key]; ok {
+		return value, nil
+	}
+
+	var out string
+	var err error
+	if runtime.GOOS == "darwin" {
+		out, err = s.run("", "security", "find-generic-password", "-s", keyringService, "-a", key, "-w")
+	} else {
+		out, err = s.run("", "secret-tool", "lookup", "service", keyringService, "account", key)
+	}
+	if missingItem(err) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		// A locked keychain, a denied prompt or no session bus
+		return "", err
+	}
+	value := strings.TrimSuffix(out, "\n")
+	if value == "" {
+		return "", ErrNotFound
+	}
+	s.cache[key] = value
+	return value, nil
+}
+
+func (s *keyringStore) Set(key, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return errors.New("secrets cannot contain line breaks")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, key)
+
+	// Secrets go through standard input, as command line arguments are
+	// visible to every local user in ps
+	var err error
+	if runtime.GOOS == "darwin" {
+		// In interactive mode security reads its commands from standard input
+		command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(keyringService), quote(key), quote(value))
+		_, err = s.run(command, "security", "-i")
+	} else {
+		_, err = s.run(value, "secret-tool", "store", "--label", keyringService+" "+key, "service", keyringService, "account", key)
+	}
+	if err != nil {
+		return err
+	}
+	s.cache[key] = value
+	return nil
+}
+
+func (s *keyringStore) Delete(key string) error {
+	_, err := s.Get(key)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, key)
+
+	if runtime.GOOS == "darwin" {
+		_, err = s.run("", "security", "delete-generic-password", "-s", keyringService, "-a", key)
+		return err
+	}
+	_, err = s.run("", "secret-tool", "clear", "service", keyringService, "account", key)
+	return err
+}
+
+// missingItem reports the documented signal of each tool for a missing
+// item: security exits with 44, secret-tool with 1 and no message
+func missingItem(err error) bool {
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) {
+		return false
+	}
+	switch toolErr.Tool {
+	case "security":
+		return toolErr.Code == securityItemNotFound
+	case "secret-tool":
+		return toolErr.Code == 1 && toolErr.Stderr == ""
+	}
+	return false
+}
+
+// quote quotes an argument of a security interactive mode command
+func quote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+}
+
+// RunTool is the Runner of the tools installed on the system. Tools exiting
+// with an error return a *ToolError with what they wrote to standard error.
+func RunTool(input, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return "", &ToolError{Tool: name, Code: exitErr.ExitCode(), Stderr: strings.TrimSpace(stderr.String())}
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,173 @@
+package secrets
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeKeyring answers the commands of security and secret-tool from items,
+// or fails every command with err
+type fakeKeyring struct {
+	items map[string]string
+	err   error
+	// args are the arguments of every command run
+	args [][]string
+}
+
+func (k *fakeKeyring) run(input, name string, args ...string) (string, error) {
+	k.args = append(k.args, append([]string{name}, args...))
+	if k.err != nil {
+		return "", k.err
+	}
+	account := ""
+	for i, arg := range args {
+		if (arg == "-a" || arg == "account") && i+1 < len(args) {
+			account = args[i+1]
+		}
+	}
+	missing := &ToolError{Tool: name, Code: 1}
+	if name == "security" {
+		missing = &ToolError{Tool: name, Code: securityItemNotFound, Stderr: "security: SecKeychainSearchCopyNext: The specified item could not be found in the keychain."}
+	}
+
+	switch args[0] {
+	case "find-generic-password", "lookup":
+		value, ok := k.items[account]
+		if !ok {
+			return "", missing
+		}
+		return value + "\n", nil
+	case "-i":
+		// add-generic-password -U -s 'service' -a 'account' -w 'value'
+		fields := strings.Fields(input)
+		unquote := func(arg string) string { return strings.ReplaceAll(strings.Trim(arg, "'"), `'"'"'`, "'") }
+		k.items[unquote(fields[5])] = unquote(fields[7])
+	case "store":
+		k.items[account] = input
+	case "delete-generic-password", "clear":
+		delete(k.items, account)
+	}
+	return "", nil
+}
+
+func TestKeyringGet(t *testing.T) {
+	tool := "secret-tool"
+	if runtime.GOOS == "darwin" {
+		tool = "security"
+	}
+
+	tests := []struct {
+		name    string
+		keyring *fakeKeyring
+		want    string
+		wantErr string // empty for success; ErrNotFound's text for a missing item
+	}{
+		{name: "found", keyring: &fakeKeyring{items: map[string]string{"default": "secret_abc"}}, want: "secret_abc"},
+		{name: "missing", keyring: &fakeKeyring{items: map[string]string{}}, wantErr: ErrNotFound.Error()},
+		{name: "empty value", keyring: &fakeKeyring{items: map[string]string{"default": ""}}, wantErr: ErrNotFound.Error()},
+		{
+			name:    "locked",
+			keyring: &fakeKeyring{err: &ToolError{Tool: tool, Code: 36, Stderr: "User interaction is not allowed."}},
+			wantErr: "User interaction is not allowed.",
+		},
+		{
+			name:    "no session bus",
+			keyring: &fakeKeyring{err: &ToolError{Tool: tool, Code: 1, Stderr: "Cannot autolaunch D-Bus without X11 $DISPLAY"}},
+			wantErr: "Cannot autolaunch D-Bus",
+		},
+		{
+			name:    "not installed",
+			keyring: &fakeKeyring{err: errors.New(tool + `: exec: "` + tool + `": executable file not found in $PATH`)},
+			wantErr: "executable file not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewKeyringStore(tt.keyring.run).Get("default")
+			if tt.wantErr == "" {
+				if err != nil || got != tt.want {
+					t.Errorf("Get = %q, %v, want %q", got, err, tt.want)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			wantMissing := tt.wantErr == ErrNotFound.Error()
+			if missing := errors.Is(err, ErrNotFound); missing != wantMissing {
+				t.Errorf("errors.Is(%v, ErrNotFound) = %v, want %v", err, missing, wantMissing)
+			}
+		})
+	}
+}
+
+func TestMissingItem(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: &ToolError{Tool: "security", Code: 44, Stderr: "The specified item could not be found in the keychain."}, want: true},
+		{err: &ToolError{Tool: "security", Code: 36, Stderr: "User interaction is not allowed."}},
+		{err: &ToolError{Tool: "security", Code: 51, Stderr: "The user canceled the operation."}},
+		{err: &ToolError{Tool: "secret-tool", Code: 1}, want: true},
+		{err: &ToolError{Tool: "secret-tool", Code: 1, Stderr: "Cannot autolaunch D-Bus without X11 $DISPLAY"}},
+		{err: &ToolError{Tool: "secret-tool", Code: 2}},
+		{err: errors.New("exec: not found")},
+		{err: nil},
+	}
+	for _, tt := range tests {
+		if got := missingItem(tt.err); got != tt.want {
+			t.Errorf("missingItem(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestKeyringSetDelete(t *testing.T) {
+	keyring := &fakeKeyring{items: map[string]string{}}
+	store := NewKeyringStore(keyring.run)
+	if err := store.Set("work", "secret_it's"); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range keyring.args {
+		if strings.Contains(strings.Join(args, " "), "secret_it") {
+			t.Errorf("the secret is passed as an argument: %v", args)
+		}
+	}
+	if err := store.Set("work", "two\nlines"); err == nil {
+		t.Error("Set accepted a secret with a line break")
+	}
+
+	// Reads are cached for the run
+	keyring.args = nil
+	if got, err := store.Get("work"); err != nil || got != "secret_it's" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if len(keyring.args) != 0 {
+		t.Errorf("Get ran %v instead of using the cache", keyring.args)
+	}
+	if got, err := NewKeyringStore(keyring.run).Get("work"); err != nil || got != "secret_it's" {
+		t.Errorf("Get from the keyring = %q, %v", got, err)
+	}
+
+	if err := store.Delete("work"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := keyring.items["work"]; ok {
+		t.Error("Delete kept the item")
+	}
+	if err := store.Delete("work"); err != nil {
+		t.Errorf("deleting a missing item: %v", err)
+	}
+
+	// A locked keyring is not mistaken for a missing item
+	keyring.err = &ToolError{Tool: "security", Code: 36, Stderr: "User interaction is not allowed."}
+	if runtime.GOOS != "darwin" {
+		keyring.err = &ToolError{Tool: "secret-tool", Code: 1, Stderr: "Cannot autolaunch D-Bus without X11 $DISPLAY"}
+	}
+	if err := NewKeyringStore(keyring.run).Delete("work"); err == nil {
+		t.Error("Delete reported success on a locked keyring")
+	}
+}
@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/manifoldco/promptui"
+	"github.com/mattn/go-isatty"
+)
+
+// PromptPassphrase returns the TODO_PASSPHRASE environment variable or asks
+// for the passphrase on the terminal. confirm asks twice, for a new file.
+// Without a terminal it returns ErrPassphraseRequired.
+func PromptPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(consts.EnvPassphrase); passphrase != "" {
+		return passphrase, nil
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return "", ErrPassphraseRequired
+	}
+
+	label := "Passphrase for the token file"
+	if confirm {
+		fmt.Println("Choose a passphrase to encrypt your Notion token.")
+		label = "New passphrase"
+	}
+	prompt := promptui.Prompt{
+		Label: label,
+		Mask:  '*',
+		Validate: func(input string) error {
+			if input == "" {
+				return errors.New("Passphrase cannot be empty")
+			}
+			return nil
+		},
+	}
+	passphrase, err := prompt.Run()
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		repeat := promptui.Prompt{Label: "Repeat passphrase", Mask: '*'}
+		again, err := repeat.Run()
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,38 @@
+package secrets
+
+import "errors"
+
+// Store keeps secrets, such as integration tokens, outside the config file
+type Store interface {
+	// Name is the backend name recorded in the config file
+	Name() string
+	// Get returns the secret stored under key, or ErrNotFound
+	Get(key string) (string, error)
+	// Set stores value under key, replacing any previous value
+	Set(key, value string) error
+	// Delete removes the secret stored under key, if any
+	Delete(key string) error
+}
+
+// Backend names
+const (
+	// BackendKeyring keeps secrets in the keyring of the operating system
+	BackendKeyring = "keyring"
+	// BackendFile keeps secrets in a passphrase-encrypted file
+	BackendFile = "file"
+	// BackendPlain keeps secrets in the config file itself
+	BackendPlain = "plain"
+)
+
+// Backends lists the backend names in the order shown in help
+var Backends = []string{BackendKeyring, BackendFile, BackendPlain}
+
+var (
+	// ErrNotFound reports a key without a stored secret
+	ErrNotFound = errors.New("secret not found")
+	// ErrPassphraseRequired reports that the encrypted file cannot be opened
+	// without a terminal to ask for the passphrase
+	ErrPassphraseRequired = errors.New("a passphrase is required to open the encrypted token file")
+	// ErrWrongPassphrase reports a passphrase that does not decrypt the file
+	ErrWrongPassphrase = errors.New("wrong passphrase for the encrypted token file")
+)
@@ -0,0 +1,22 @@
+package statuses
+
+import (
+	"context"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+// Statuses provides the status options of the database, cached locally
+type Statuses interface {
+	// GetStatuses returns cached statuses, fetching them from Notion when the
+	// cache is missing, stale or belongs to another database
+	GetStatuses(ctx context.Context) ([]models.StatusOption, error)
+	// Refresh fetches statuses from Notion and updates the cache
+	Refresh(ctx context.Context) ([]models.StatusOption, error)
+	// Cached returns statuses from the cache without network access
+	Cached() ([]models.StatusOption, error)
+	// Find returns the status named name, ignoring case. Unknown names refresh
+	// the cache once in case the status was added since. The returned status
+	// is nil when no status matches.
+	Find(ctx context.Context, name string) (*models.StatusOption, []models.StatusOption, error)
+}
@@ -0,0 +1,149 @@
+package statuses
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/notion"
+)
+
+type statusesImpl struct {
+	notionService     notion.Notion
+	credentialService config.Credential
+	cache             files.File
+}
+
+var statuses Statuses
+
+// NewStatusSvc returns the status service backed by the given cache file
+func NewStatusSvc(notionSvc notion.Notion, credService config.Credential, cache files.File) Statuses {
+	if notionSvc == nil || credService == nil || cache == nil {
+		panic("status service dependencies not initialized")
+	}
+	if statuses == nil {
+		statuses = &statusesImpl{
+			notionService:     notionSvc,
+			credentialService: credService,
+			cache:             cache,
+		}
+	}
+	return statuses
+}
+
+func (s *statusesImpl) GetStatuses(ctx context.Context) ([]models.StatusOption, error) {
+	cfg, err := s.credentialService.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := s.readCache()
+	if err == nil && cache.DatabaseID == cfg.DatabaseID && time.Since(cache.FetchedAt) < consts.StatusCacheTTL {
+		return cache.Statuses, nil
+	}
+	return s.Refresh(ctx)
+}
+
+func (s *statusesImpl) Refresh(ctx context.Context) ([]models.StatusOption, error) {
+	cfg, err := s.credentialService.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	options, err := s.notionService.ListStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(models.StatusCache{
+		DatabaseID: cfg.DatabaseID,
+		FetchedAt:  time.Now(),
+		Statuses:   options,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.SaveFile(data); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+func (s *statusesImpl) Cached() ([]models.StatusOption, error) {
+	cfg, err := s.credentialService.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := s.readCache()
+	if err != nil {
+		return nil, err
+	}
+	if cache.DatabaseID != cfg.DatabaseID {
+		// The cache belongs to the database of another profile
+		return nil, errors.New("status cache belongs to another database")
+	}
+	return cache.Statuses, nil
+}
+
+func (s *statusesImpl) Find(ctx context.Context, name string) (*models.StatusOption, []models.StatusOption, error) {
+	options, err := s.GetStatuses(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if option := find(options, name); option != nil {
+		return option, options, nil
+	}
+
+	options, err = s.Refresh(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return find(options, name), options, nil
+}
+
+func (s *statusesImpl) readCache() (*models.StatusCache, error) {
+	data, err := s.cache.ReadFile()
+	if err != nil {
+		return nil, err
+	}
+	var cache models.StatusCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, err
+	}
+	if len(cache.Statuses) == 0 {
+		return nil, errors.New("status cache is empty")
+	}
+	return &cache, nil
+}
+
+func find(options []models.StatusOption, name string) *models.StatusOption {
+	for i := range options {
+		if strings.EqualFold(options[i].Name, strings.TrimSpace(name)) {
+			return &options[i]
+		}
+	}
+	return nil
+}
+
+// Names returns the names of options in order
+func Names(options []models.StatusOption) []string {
+	names := make([]string, 0, len(options))
+	for _, option := range options {
+		names = append(names, option.Name)
+	}
+	return names
+}
+
+// FirstInGroup returns the first option in group, or nil when the group is empty
+func FirstInGroup(options []models.StatusOption, group string) *models.StatusOption {
+	for i := range options {
+		if options[i].Group == group {
+			return &options[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package statuses
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+	"github.com/caffeines/notion-todo/service/notion"
+	"github.com/caffeines/notion-todo/service/notion/fakenotion"
+)
+
+// newTestStatuses starts a fake Notion server with a database of schema
+// and returns a status service for it, caching to a temporary directory
+func newTestStatuses(t *testing.T, databaseID string, schema map[string]map[string]interface{}) (*fakenotion.Server, *statusesImpl) {
+	t.Helper()
+	server := fakenotion.NewServer()
+	t.Cleanup(server.Close)
+	server.AddDatabase(databaseID, "Todo", schema)
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv(consts.EnvToken, "secret_test")
+	t.Setenv(consts.EnvDatabaseID, databaseID)
+	t.Setenv(consts.EnvAPIURL, server.APIURL())
+	t.Setenv(consts.EnvMaxRetries, "-1")
+
+	credService := config.NewCredentialSvc(files.NewFileService(files.ConfigDir, consts.ConfigFileName))
+	return server, &statusesImpl{
+		notionService:     notion.NewNotionSvc(credService),
+		credentialService: credService,
+		cache:             files.NewFileService(files.CacheDir, consts.StatusCacheFileName),
+	}
+}
+
+// options returns a select-like schema config with options named names
+func options(names ...string) map[string]interface{} {
+	var list []interface{}
+	for _, name := range names {
+		list = append(list, map[string]interface{}{"name": name})
+	}
+	return map[string]interface{}{"options": list}
+}
+
+// databaseReads counts the requests for the schema of databaseID
+func databaseReads(server *fakenotion.Server, databaseID string) int {
+	n := 0
+	for _, request := range server.Requests() {
+		if request.Method == http.MethodGet && strings.HasSuffix(request.Path, "/databases/"+databaseID) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestStatusGroups(t *testing.T) {
+	tests := []struct {
+		name       string
+		databaseID string
+		status     map[string]interface{}
+		want       []models.StatusOption
+	}{
+		{
+			name:       "status groups",
+			databaseID: "5a7e5b0e0c1d4e2f8a9b0c1d2e3f4a01",
+			status: map[string]interface{}{"type": "status", "status": map[string]interface{}{"options": []interface{}{
+				map[string]interface{}{"name": "Backlog", "color": "gray"},
+				map[string]interface{}{"name": "Doing", "color": "blue", "group": "In progress"},
+				map[string]interface{}{"name": "Review", "color": "purple", "group": "In progress"},
+				map[string]interface{}{"name": "Shipped", "color": "green", "group": "Complete"},
+			}}},
+			want: []models.StatusOption{
+				{Name: "Backlog", Color: "gray", Group: models.StatusGroupTodo},
+				{Name: "Doing", Color: "blue", Group: models.StatusGroupInProgress},
+				{Name: "Review", Color: "purple", Group: models.StatusGroupInProgress},
+				{Name: "Shipped", Color: "green", Group: models.StatusGroupComplete},
+			},
+		},
+		{
+			// Select options have no groups, so they follow from the names
+			name:       "select names",
+			databaseID: "5a7e5b0e0c1d4e2f8a9b0c1d2e3f4a02",
+			status:     map[string]interface{}{"type": "select", "select": options("Todo", "In Progress", "Blocked", "Someday", "Done")},
+			want: []models.StatusOption{
+				{Name: "Todo", Color: "default", Group: models.StatusGroupTodo},
+				{Name: "In Progress", Color: "default", Group: models.StatusGroupInProgress},
+				{Name: "Blocked", Color: "default", Group: models.StatusGroupInProgress},
+				{Name: "Someday", Color: "default", Group: models.StatusGroupTodo},
+				{Name: "Done", Color: "default", Group: models.StatusGroupComplete},
+			},
+		},
+		{
+			name:       "checkbox",
+			databaseID: "5a7e5b0e0c1d4e2f8a9b0c1d2e3f4a03",
+			status:     map[string]interface{}{"type": "checkbox", "checkbox": map[string]interface{}{}},
+			want: []models.StatusOption{
+				{Name: models.CheckboxStatusTodo, Color: "default", Group: models.StatusGroupTodo},
+				{Name: models.CheckboxStatusDone, Color: "green", Group: models.StatusGroupComplete},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, svc := newTestStatuses(t, tt.databaseID, map[string]map[string]interface{}{
+				"Name":   {"type": "title"},
+				"Status": tt.status,
+			})
+			got, err := svc.GetStatuses(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetStatuses() = %+v, want %+v", got, tt.want)
+			}
+			for _, group := range []string{models.StatusGroupTodo, models.StatusGroupComplete} {
+				first := FirstInGroup(got, group)
+				if first == nil || first.Group != group {
+					t.Errorf("FirstInGroup(%s) = %+v", group, first)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusCache(t *testing.T) {
+	const databaseID = "5a7e5b0e0c1d4e2f8a9b0c1d2e3f4a04"
+	schema := map[string]map[string]interface{}{
+		"Name":   {"type": "title"},
+		"Status": {"type": "select", "select": options("Todo", "Done")},
+	}
+	server, svc := newTestStatuses(t, databaseID, schema)
+	ctx := context.Background()
+
+	if _, err := svc.Cached(); err == nil {
+		t.Error("Cached() succeeded without a cache")
+	}
+	if _, err := svc.GetStatuses(ctx); err != nil {
+		t.Fatal(err)
+	}
+	reads := databaseReads(server, databaseID)
+	cached, err := svc.GetStatuses(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := databaseReads(server, databaseID); got != reads {
+		t.Errorf("a fresh cache read the schema %d more times", got-reads)
+	}
+	if offline, err := svc.Cached(); err != nil || !reflect.DeepEqual(offline, cached) {
+		t.Errorf("Cached() = %v, %v, want %v", offline, err, cached)
+	}
+
+	// A stale cache is fetched again
+	data, _ := svc.cache.ReadFile()
+	var stale models.StatusCache
+	if err := json.Unmarshal(data, &stale); err != nil {
+		t.Fatal(err)
+	}
+	stale.FetchedAt = time.Now().Add(-consts.StatusCacheTTL - time.Minute)
+	data, _ = json.Marshal(stale)
+	if err := svc.cache.SaveFile(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.GetStatuses(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := databaseReads(server, databaseID); got == reads {
+		t.Error("a stale cache was used")
+	}
+
+	// Unknown names refresh the cache once, finding statuses added since
+	schema["Status"] = map[string]interface{}{"type": "select", "select": options("Todo", "Review", "Done")}
+	server.AddDatabase(databaseID, "Todo", schema)
+	option, list, err := svc.Find(ctx, " review ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if option == nil || option.Name != "Review" || option.Group != models.StatusGroupInProgress {
+		t.Errorf("Find(review) = %+v", option)
+	}
+	if names := Names(list); !reflect.DeepEqual(names, []string{"Todo", "Review", "Done"}) {
+		t.Errorf("statuses = %v", names)
+	}
+	reads = databaseReads(server, databaseID)
+	if option, _, err := svc.Find(ctx, "DONE"); err != nil || option == nil || option.Name != "Done" {
+		t.Errorf("Find(DONE) = %+v, %v", option, err)
+	}
+	if got := databaseReads(server, databaseID); got != reads {
+		t.Error("finding a cached status read the schema")
+	}
+	if option, list, err := svc.Find(ctx, "Archived"); err != nil || option != nil || len(list) != 3 {
+		t.Errorf("Find(Archived) = %+v, %v, %v, want no status", option, list, err)
+	}
+	if got := databaseReads(server, databaseID); got != reads+1 {
+		t.Errorf("an unknown status read the schema %d times, want once", got-reads)
+	}
+
+	// The cache of another database is not used
+	t.Setenv(consts.EnvDatabaseID, "5a7e5b0e0c1d4e2f8a9b0c1d2e3f4a05")
+	if _, err := svc.Cached(); err == nil || !strings.Contains(err.Error(), "another database") {
+		t.Errorf("Cached() for another database: error = %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+package utility
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLocale is the order of day, month and year in numeric dates, used both
+// to read dates typed by the user and to display dates
+type DateLocale string
+
+const (
+	LocaleDMY DateLocale = "DD-MM-YYYY"
+	LocaleMDY DateLocale = "MM-DD-YYYY"
+	LocaleYMD DateLocale = "YYYY-MM-DD"
+)
+
+// DefaultDateLocale keeps the original DD-MM-YYYY format
+const DefaultDateLocale = LocaleDMY
+
+// DateLocales lists the supported locales
+var DateLocales = []DateLocale{LocaleDMY, LocaleMDY, LocaleYMD}
+
+// ParseDateLocale validates a locale name, ignoring case. An empty name is
+// the default locale.
+func ParseDateLocale(name string) (DateLocale, error) {
+	if name == "" {
+		return DefaultDateLocale, nil
+	}
+	for _, locale := range DateLocales {
+		if strings.EqualFold(name, string(locale)) {
+			return locale, nil
+		}
+	}
+	return "", fmt.Errorf("unknown date format %q, use one of: %s, %s, %s", name, LocaleDMY, LocaleMDY, LocaleYMD)
+}
+
+// layout returns the time layout of numeric dates in the locale
+func (l DateLocale) layout() string {
+	switch l {
+	case LocaleMDY:
+		return "01-02-2006"
+	case LocaleYMD:
+		return "2006-01-02"
+	}
+	return "02-01-2006"
+}
+
+// inputLayout is layout accepting single-digit days and months
+func (l DateLocale) inputLayout() string {
+	switch l {
+	case LocaleMDY:
+		return "1-2-2006"
+	case LocaleYMD:
+		return "2006-1-2"
+	}
+	return "2-1-2006"
+}
+
+// ParsedDate is a calendar date with an optional time of day
+type ParsedDate struct {
+	Time    time.Time
+	HasTime bool
+}
+
+// Notion returns the date in the ISO 8601 form Notion expects: YYYY-MM-DD,
+// or a date-time with offset when a time was given
+func (d ParsedDate) Notion() string {
+	if d.HasTime {
+		return d.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return d.Time.Format("2006-01-02")
+}
+
+// NotionInZone returns the date like Notion, but date-times without an
+// offset, as Notion expects when a time_zone is sent with them
+func (d ParsedDate) NotionInZone() string {
+	if d.HasTime {
+		return d.Time.Format(wallLayout)
+	}
+	return d.Time.Format("2006-01-02")
+}
+
+// wallLayout is a date-time without offset
+const wallLayout = "2006-01-02T15:04:05"
+
+var (
+	weekdays = map[string]time.Weekday{
+		"sun": time.Sunday, "sunday": time.Sunday,
+		"mon": time.Monday, "monday": time.Monday,
+		"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+		"wed": time.Wednesday, "wednesday": time.Wednesday,
+		"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+		"fri": time.Friday, "friday": time.Friday,
+		"sat": time.Saturday, "saturday": time.Saturday,
+	}
+	relativePattern = regexp.MustCompile(`^in (\d+|a|an|one) (day|days|week|weeks|month|months|year|years)$`)
+	timePattern     = regexp.MustCompile(`^(?:at )?(\d{1,2})(?::(\d{2}))? ?(am|pm)?$`)
+	numericPattern  = regexp.MustCompile(`^\d{1,4}[-/.]\d{1,2}[-/.]\d{1,4}$`)
+)
+
+// ParseDate parses a due date relative to now. It understands
+//
+//	today, tomorrow, yesterday
+//	mon … sun: the next such day after today
+//	next mon … next sun: that day in the following week
+//	next week, next month: the following Monday or first of the month
+//	in 3 days, in 2 weeks, in a month
+//	eow, eom: the coming Friday, the last day of the month
+//	2025-03-15 (ISO), and numeric dates in locale order, e.g. 15-03-2025
+//
+// optionally followed by a time such as 17:00, 5pm or "at 9:30am". Dates are
+// checked against the calendar, so 31-02-2025 is rejected. Times are in the
+// location of now.
+func ParseDate(input string, now time.Time, locale DateLocale) (ParsedDate, error) {
+	text := strings.ToLower(strings.Join(strings.Fields(input), " "))
+	if text == "" {
+		return ParsedDate{}, fmt.Errorf("empty date")
+	}
+
+	// ISO date-times are taken as they are
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, strings.ToUpper(text), now.Location()); err == nil {
+			return ParsedDate{Time: t, HasTime: true}, nil
+		}
+	}
+
+	// Try the whole input as a date, then split off a trailing time
+	if date, err := parseDay(text, now, locale); err == nil {
+		return ParsedDate{Time: date}, nil
+	}
+	words := strings.Fields(text)
+	for split := len(words) - 1; split > 0; split-- {
+		datePart := strings.Join(words[:split], " ")
+		timePart := strings.Join(words[split:], " ")
+		hour, minute, ok := parseClock(timePart)
+		if !ok {
+			continue
+		}
+		if datePart == "at" {
+			// "at 5pm" is a time alone
+			break
+		}
+		date, err := parseDay(strings.TrimSuffix(datePart, " at"), now, locale)
+		if err != nil {
+			return ParsedDate{}, err
+		}
+		t := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, now.Location())
+		return ParsedDate{Time: t, HasTime: true}, nil
+	}
+
+	// A time alone means today
+	if hour, minute, ok := parseClock(text); ok {
+		t := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+		return ParsedDate{Time: t, HasTime: true}, nil
+	}
+
+	_, err := parseDay(text, now, locale)
+	return ParsedDate{}, err
+}
+
+// parseDay parses the date part of an input as midnight in now's location
+func parseDay(text string, now time.Time, locale DateLocale) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch text {
+	case "today", "tod":
+		return today, nil
+	case "tomorrow", "tmr", "tmrw":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "eow", "end of week":
+		return nextWeekday(today, time.Friday, true), nil
+	case "eom", "end of month":
+		return time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, today.Location()), nil
+	case "next week":
+		return nextWeekday(today, time.Monday, false), nil
+	case "next month":
+		return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), nil
+	}
+
+	if weekday, ok := weekdays[text]; ok {
+		return nextWeekday(today, weekday, false), nil
+	}
+	if name, ok := strings.CutPrefix(text, "next "); ok {
+		if weekday, ok := weekdays[name]; ok {
+			// The day in the week after this one, weeks starting on Monday
+			monday := nextWeekday(today, time.Monday, false)
+			return monday.AddDate(0, 0, (int(weekday)+6)%7), nil
+		}
+	}
+
+	if match := relativePattern.FindStringSubmatch(text); match != nil {
+		n := 1
+		if count, err := strconv.Atoi(match[1]); err == nil {
+			n = count
+		}
+		switch strings.TrimSuffix(match[2], "s") {
+		case "day":
+			return today.AddDate(0, 0, n), nil
+		case "week":
+			return today.AddDate(0, 0, 7*n), nil
+		case "month":
+			return today.AddDate(0, n, 0), nil
+		case "year":
+			return today.AddDate(n, 0, 0), nil
+		}
+	}
+
+	if numericPattern.MatchString(text) {
+		normalized := strings.NewReplacer("/", "-", ".", "-").Replace(text)
+		// ISO dates are accepted whatever the locale
+		if t, err := time.ParseInLocation("2006-1-2", normalized, now.Location()); err == nil && len(strings.Split(normalized, "-")[0]) == 4 {
+			return t, nil
+		}
+		t, err := time.ParseInLocation(locale.inputLayout(), normalized, now.Location())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q: not a calendar date in %s format", text, locale)
+		}
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("could not understand date %q, try today, tomorrow, fri, next monday, in 3 days, eow or %s", text, locale)
+}
+
+// nextWeekday returns the next weekday after today, or today itself when
+// includeToday is set and today is that weekday
+func nextWeekday(today time.Time, weekday time.Weekday, includeToday bool) time.Time {
+	days := (int(weekday) - int(today.Weekday()) + 7) % 7
+	if days == 0 && !includeToday {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+// ParseClock parses a time of day such as 17:00, 5pm or "at 9:30am"
+func ParseClock(text string) (hour, minute int, ok bool) {
+	return parseClock(strings.ToLower(strings.Join(strings.Fields(text), " ")))
+}
+
+// parseClock parses 17:00, 5pm, 5:30 pm or "at 9am"
+func parseClock(text string) (hour, minute int, ok bool) {
+	match := timePattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, 0, false
+	}
+	hour, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	} else if match[3] == "" {
+		// A bare number is not a time
+		return 0, 0, false
+	}
+	switch match[3] {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+// ParseNotionDate parses a date read from Notion. Date-times without an
+// offset are in timeZone, or UTC when it is empty.
+func ParseNotionDate(value, timeZone string) (t time.Time, hasTime bool, err error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, false, nil
+	}
+	loc := time.UTC
+	if timeZone != "" {
+		if loc, err = time.LoadLocation(timeZone); err != nil {
+			return time.Time{}, false, err
+		}
+	}
+	t, err = time.ParseInLocation(wallLayout, strings.SplitN(value, ".", 2)[0], loc)
+	return t, err == nil, err
+}
+
+// FormatDateRange formats a Notion date with its optional end for display
+// in the locale. Date-times are converted to loc; an end on the same day
+// shows only its time. Unparseable values are returned unchanged.
+func FormatDateRange(start string, end, timeZone *string, locale DateLocale, loc *time.Location) string {
+	zone := ""
+	if timeZone != nil {
+		zone = *timeZone
+	}
+	startTime, startHasTime, err := ParseNotionDate(start, zone)
+	if err != nil {
+		return start
+	}
+	text := formatTime(startTime, startHasTime, locale, loc)
+	if end == nil || *end == "" {
+		return text
+	}
+
+	endTime, endHasTime, err := ParseNotionDate(*end, zone)
+	if err != nil {
+		return text + " to " + *end
+	}
+	if startHasTime && endHasTime {
+		s, e := startTime.In(loc), endTime.In(loc)
+		if s.Year() == e.Year() && s.YearDay() == e.YearDay() {
+			return text + " to " + e.Format("15:04")
+		}
+	}
+	return text + " to " + formatTime(endTime, endHasTime, locale, loc)
+}
+
+func formatTime(t time.Time, hasTime bool, locale DateLocale, loc *time.Location) string {
+	if hasTime {
+		return t.In(loc).Format(locale.layout() + " 15:04")
+	}
+	return t.Format(locale.layout())
+}
@@ -0,0 +1,199 @@
+package utility
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2025, time.March, 12, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		locale   DateLocale
+		want     string // 2006-01-02, or 2006-01-02 15:04 for date-times
+		hasTime  bool
+		errorHas string
+	}{
+		{input: "today", want: "2025-03-12"},
+		{input: "Tomorrow", want: "2025-03-13"},
+		{input: "yesterday", want: "2025-03-11"},
+
+		// A weekday is the next such day after today, never today
+		{input: "fri", want: "2025-03-14"},
+		{input: "monday", want: "2025-03-17"},
+		{input: "wed", want: "2025-03-19"},
+		{input: "tue", want: "2025-03-18"},
+
+		// next <weekday> is that day in the week after this one
+		{input: "next mon", want: "2025-03-17"},
+		{input: "next wed", want: "2025-03-19"},
+		{input: "next fri", want: "2025-03-21"},
+		{input: "next sunday", want: "2025-03-23"},
+		{input: "next week", want: "2025-03-17"},
+		{input: "next month", want: "2025-04-01"},
+		{input: "eow", want: "2025-03-14"},
+		{input: "end of month", want: "2025-03-31"},
+
+		{input: "in 3 days", want: "2025-03-15"},
+		{input: "in a week", want: "2025-03-19"},
+		{input: "in 2 months", want: "2025-05-12"},
+		{input: "in one year", want: "2026-03-12"},
+
+		// Numeric dates follow the locale, ISO dates are read in any locale
+		{input: "15-03-2025", locale: LocaleDMY, want: "2025-03-15"},
+		{input: "5/3/2025", locale: LocaleDMY, want: "2025-03-05"},
+		{input: "5/3/2025", locale: LocaleMDY, want: "2025-05-03"},
+		{input: "03-15-2025", locale: LocaleMDY, want: "2025-03-15"},
+		{input: "2025.3.15", locale: LocaleYMD, want: "2025-03-15"},
+		{input: "2025-03-15", locale: LocaleMDY, want: "2025-03-15"},
+		{input: "29-02-2024", locale: LocaleDMY, want: "2024-02-29"},
+
+		{input: "fri 17:00", want: "2025-03-14 17:00", hasTime: true},
+		{input: "tomorrow at 9:30am", want: "2025-03-13 09:30", hasTime: true},
+		{input: "15-03-2025 5 pm", want: "2025-03-15 17:00", hasTime: true},
+		{input: "5pm", want: "2025-03-12 17:00", hasTime: true},
+		{input: "12am", want: "2025-03-12 00:00", hasTime: true},
+		{input: "at 12pm", want: "2025-03-12 12:00", hasTime: true},
+		{input: "2025-03-15T08:45", want: "2025-03-15 08:45", hasTime: true},
+
+		{input: "31-02-2025", locale: LocaleDMY, errorHas: "not a calendar date in DD-MM-YYYY format"},
+		{input: "02-31-2025", locale: LocaleMDY, errorHas: "not a calendar date in MM-DD-YYYY format"},
+		{input: "29-02-2025", locale: LocaleDMY, errorHas: "not a calendar date"},
+		{input: "13-13-2025", locale: LocaleDMY, errorHas: "not a calendar date"},
+		{input: "31-02-2025 17:00", locale: LocaleDMY, errorHas: "not a calendar date"},
+		{input: "fri 25:00", errorHas: "could not understand"},
+		{input: "13pm", errorHas: "could not understand"},
+		{input: "someday", errorHas: "could not understand"},
+		{input: "next", errorHas: "could not understand"},
+		{input: " ", errorHas: "empty date"},
+	}
+
+	for _, tt := range tests {
+		locale := tt.locale
+		if locale == "" {
+			locale = DefaultDateLocale
+		}
+		t.Run(string(locale)+"/"+tt.input, func(t *testing.T) {
+			got, err := ParseDate(tt.input, now, locale)
+			if tt.errorHas != "" {
+				if err == nil {
+					t.Fatalf("ParseDate(%q) = %v, want an error containing %q", tt.input, got.Time, tt.errorHas)
+				}
+				if !strings.Contains(err.Error(), tt.errorHas) {
+					t.Fatalf("ParseDate(%q) error = %q, want it to contain %q", tt.input, err, tt.errorHas)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDate(%q) error = %v", tt.input, err)
+			}
+			layout := "2006-01-02"
+			if tt.hasTime {
+				layout = "2006-01-02 15:04"
+			}
+			if text := got.Time.Format(layout); text != tt.want {
+				t.Errorf("ParseDate(%q) = %s, want %s", tt.input, text, tt.want)
+			}
+			if got.HasTime != tt.hasTime {
+				t.Errorf("ParseDate(%q).HasTime = %v, want %v", tt.input, got.HasTime, tt.hasTime)
+			}
+			if got.Time.Location() != now.Location() {
+				t.Errorf("ParseDate(%q) is in %v, want %v", tt.input, got.Time.Location(), now.Location())
+			}
+		})
+	}
+}
+
+func TestParseDateNotion(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	now := time.Date(2025, time.March, 12, 10, 30, 0, 0, berlin)
+
+	tests := []struct {
+		input      string
+		wantNotion string
+		wantInZone string
+	}{
+		{input: "tomorrow", wantNotion: "2025-03-13", wantInZone: "2025-03-13"},
+		{input: "fri 17:00", wantNotion: "2025-03-14T17:00:00+01:00", wantInZone: "2025-03-14T17:00:00"},
+		// Summer time starts on 30 March
+		{input: "31-03-2025 9am", wantNotion: "2025-03-31T09:00:00+02:00", wantInZone: "2025-03-31T09:00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDate(tt.input, now, LocaleDMY)
+			if err != nil {
+				t.Fatalf("ParseDate(%q) error = %v", tt.input, err)
+			}
+			if notion := got.Notion(); notion != tt.wantNotion {
+				t.Errorf("Notion() = %s, want %s", notion, tt.wantNotion)
+			}
+			if inZone := got.NotionInZone(); inZone != tt.wantInZone {
+				t.Errorf("NotionInZone() = %s, want %s", inZone, tt.wantInZone)
+			}
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		input        string
+		hour, minute int
+		ok           bool
+	}{
+		{input: "17:00", hour: 17, ok: true},
+		{input: "9:05", hour: 9, minute: 5, ok: true},
+		{input: "5pm", hour: 17, ok: true},
+		{input: "5:30 PM", hour: 17, minute: 30, ok: true},
+		{input: "at 9am", hour: 9, ok: true},
+		{input: "12pm", hour: 12, ok: true},
+		{input: "12am", hour: 0, ok: true},
+		{input: "17", ok: false},
+		{input: "24:00", ok: false},
+		{input: "9:60", ok: false},
+		{input: "0am", ok: false},
+		{input: "13pm", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			hour, minute, ok := ParseClock(tt.input)
+			if ok != tt.ok || hour != tt.hour || minute != tt.minute {
+				t.Errorf("ParseClock(%q) = %d, %d, %v, want %d, %d, %v", tt.input, hour, minute, ok, tt.hour, tt.minute, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFormatDateRange(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		start    string
+		end      *string
+		timeZone *string
+		locale   DateLocale
+		want     string
+	}{
+		{name: "date", start: "2025-03-15", locale: LocaleDMY, want: "15-03-2025"},
+		{name: "month first", start: "2025-03-15", locale: LocaleMDY, want: "03-15-2025"},
+		{name: "iso", start: "2025-03-15", locale: LocaleYMD, want: "2025-03-15"},
+		{name: "date range", start: "2025-03-15", end: str("2025-03-17"), locale: LocaleDMY, want: "15-03-2025 to 17-03-2025"},
+		{name: "same day times", start: "2025-03-15T09:00:00Z", end: str("2025-03-15T10:30:00Z"), locale: LocaleDMY, want: "15-03-2025 09:00 to 10:30"},
+		{name: "zone without offset", start: "2025-03-15T09:00:00", timeZone: str("Asia/Kolkata"), locale: LocaleDMY, want: "15-03-2025 03:30"},
+		{name: "unparseable", start: "soon", locale: LocaleDMY, want: "soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatDateRange(tt.start, tt.end, tt.timeZone, tt.locale, time.UTC)
+			if got != tt.want {
+				t.Errorf("FormatDateRange(%q) = %q, want %q", tt.start, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package utility
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+// SubTaskNotFoundError reports a reference matching no sub-task
+type SubTaskNotFoundError struct {
+	Ref string
+}
+
+func (e *SubTaskNotFoundError) Error() string {
+	return fmt.Sprintf("no sub-task matches %q", e.Ref)
+}
+
+// ResolveSubTaskRef returns the index of the sub-task referenced by ref,
+// which is a 1-based position or text. Text matches exactly, then by
+// substring, ignoring case, and must match a single sub-task.
+func ResolveSubTaskRef(subTasks []models.SubTask, ref string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	if index, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil {
+		if index >= 1 && index <= len(subTasks) {
+			return index - 1, nil
+		}
+		return 0, &SubTaskNotFoundError{Ref: ref}
+	}
+
+	query := strings.ToLower(ref)
+	rules := []func(text string) bool{
+		func(text string) bool { return text == query },
+		func(text string) bool { return strings.Contains(text, query) },
+	}
+	for _, rule := range rules {
+		var matches []int
+		for i, subTask := range subTasks {
+			if rule(strings.ToLower(subTask.Text)) {
+				matches = append(matches, i)
+			}
+		}
+		switch {
+		case len(matches) == 1:
+			return matches[0], nil
+		case len(matches) > 1:
+			return 0, fmt.Errorf("%q matches %d sub-tasks, use its number instead", ref, len(matches))
+		}
+	}
+	return 0, &SubTaskNotFoundError{Ref: ref}
+}
@@ -0,0 +1,60 @@
+package utility
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+func TestResolveSubTaskRef(t *testing.T) {
+	subTasks := []models.SubTask{
+		{ID: "b1", Text: "Call the bank"},
+		{ID: "b2", Text: "Book flights"},
+		{ID: "b3", Text: "Book hotel"},
+		{ID: "b4", Text: "Book"},
+	}
+
+	tests := []struct {
+		name     string
+		ref      string
+		want     int
+		notFound bool
+		wantErr  string
+	}{
+		{name: "position", ref: "2", want: 1},
+		{name: "hash position", ref: "#1", want: 0},
+		{name: "position out of range", ref: "5", notFound: true},
+		{name: "position zero", ref: "0", notFound: true},
+		{name: "exact text wins", ref: "BOOK", want: 3},
+		{name: "substring", ref: "hotel", want: 2},
+		{name: "unique substring", ref: "book f", want: 1},
+		{name: "ambiguous substring", ref: "boo", wantErr: `"boo" matches 3 sub-tasks`},
+		{name: "no match", ref: "pack", notFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveSubTaskRef(subTasks, tt.ref)
+			if tt.notFound {
+				var notFound *SubTaskNotFoundError
+				if !errors.As(err, &notFound) {
+					t.Fatalf("error = %v, want a SubTaskNotFoundError", err)
+				}
+				return
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveSubTaskRef(%q) = %d, want %d", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
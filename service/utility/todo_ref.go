@@ -0,0 +1,136 @@
+package utility
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+// MinShortIDLength is the shortest page ID prefix accepted as a reference
+const MinShortIDLength = 4
+
+// ShortID returns the first eight characters of a page ID without dashes
+func ShortID(id string) string {
+	id = strings.ReplaceAll(id, "-", "")
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// AmbiguousRefError reports a reference matching more than one todo
+type AmbiguousRefError struct {
+	Ref     string
+	Matches []models.TodoItem
+}
+
+func (e *AmbiguousRefError) Error() string {
+	return fmt.Sprintf("%q matches %d todos", e.Ref, len(e.Matches))
+}
+
+// NotFoundRefError reports a reference matching no todo
+type NotFoundRefError struct {
+	Ref string
+}
+
+func (e *NotFoundRefError) Error() string {
+	return fmt.Sprintf("no todo matches %q", e.Ref)
+}
+
+// ResolveTodoRef finds the todos referenced by ref, which is tried in turn as
+// a 1-based index into todos (3 or #3), a page ID or prefix of at least
+// MinShortIDLength characters, and a title. Indexes follow the order of
+// todos, which is the order 'todo list -o table' prints. Titles match
+// exactly, then by substring, then by containing every word of ref, ignoring
+// case. Unless allMatching is set, a reference matching several todos is an
+// *AmbiguousRefError.
+func ResolveTodoRef(todos []models.TodoItem, ref string, allMatching bool) ([]models.TodoItem, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, &NotFoundRefError{Ref: ref}
+	}
+
+	if index, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil {
+		if index >= 1 && index <= len(todos) {
+			return todos[index-1 : index], nil
+		}
+		// A bare number out of range may still be a short ID or a title
+		if strings.HasPrefix(ref, "#") {
+			return nil, &NotFoundRefError{Ref: ref}
+		}
+	}
+
+	matches := matchID(todos, ref)
+	if len(matches) == 0 {
+		matches = matchTitle(todos, ref)
+	}
+
+	switch {
+	case len(matches) == 0:
+		return nil, &NotFoundRefError{Ref: ref}
+	case len(matches) > 1 && !allMatching:
+		return nil, &AmbiguousRefError{Ref: ref, Matches: matches}
+	}
+	return matches, nil
+}
+
+// IsPageID reports whether ref is a full page ID, with or without dashes
+func IsPageID(ref string) bool {
+	id := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(ref), "-", ""))
+	return len(id) == 32 && strings.Trim(id, "0123456789abcdef") == ""
+}
+
+// SameID reports whether two page or database IDs are equal, ignoring dashes
+// and case
+func SameID(a, b string) bool {
+	return strings.EqualFold(strings.ReplaceAll(a, "-", ""), strings.ReplaceAll(b, "-", ""))
+}
+
+// matchID returns todos whose page ID starts with ref, ignoring dashes
+func matchID(todos []models.TodoItem, ref string) []models.TodoItem {
+	prefix := strings.ToLower(strings.ReplaceAll(ref, "-", ""))
+	if len(prefix) < MinShortIDLength || strings.Trim(prefix, "0123456789abcdef") != "" {
+		return nil
+	}
+	var matches []models.TodoItem
+	for _, todo := range todos {
+		if strings.HasPrefix(strings.ReplaceAll(todo.ID, "-", ""), prefix) {
+			matches = append(matches, todo)
+		}
+	}
+	return matches
+}
+
+// matchTitle returns todos matching ref by the strictest title rule that
+// matches any todo
+func matchTitle(todos []models.TodoItem, ref string) []models.TodoItem {
+	query := strings.ToLower(ref)
+	words := strings.Fields(query)
+	rules := []func(title string) bool{
+		func(title string) bool { return title == query },
+		func(title string) bool { return strings.Contains(title, query) },
+		func(title string) bool {
+			for _, word := range words {
+				if !strings.Contains(title, word) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+
+	for _, rule := range rules {
+		var matches []models.TodoItem
+		for _, todo := range todos {
+			if rule(strings.ToLower(todo.Title)) {
+				matches = append(matches, todo)
+			}
+		}
+		if len(matches) > 0 {
+			return matches
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package utility
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+func TestResolveTodoRef(t *testing.T) {
+	todos := []models.TodoItem{
+		{ID: "1a2b3c4d-0000-4000-8000-000000000001", Title: "Buy milk"},
+		{ID: "1a2b9999-0000-4000-8000-000000000002", Title: "Write quarterly report"},
+		{ID: "5e6f7a8b-0000-4000-8000-000000000003", Title: "Review report draft"},
+		{ID: "20250000-0000-4000-8000-000000000004", Title: "2025 taxes"},
+	}
+
+	tests := []struct {
+		name        string
+		ref         string
+		allMatching bool
+		want        []string // Titles of the todos found
+		wantErr     interface{}
+	}{
+		{name: "index", ref: "2", want: []string{"Write quarterly report"}},
+		{name: "hash index", ref: "#3", want: []string{"Review report draft"}},
+		{name: "index with spaces", ref: " 1 ", want: []string{"Buy milk"}},
+		{name: "hash index out of range", ref: "#9", wantErr: &NotFoundRefError{}},
+		{name: "hash zero", ref: "#0", wantErr: &NotFoundRefError{}},
+		// Numbers beyond the list fall through to IDs and titles
+		{name: "number as short ID", ref: "2025", want: []string{"2025 taxes"}},
+		{name: "short ID", ref: "5e6f", want: []string{"Review report draft"}},
+		{name: "dashed ID prefix", ref: "1a2b3c4d-0000", want: []string{"Buy milk"}},
+		{name: "ambiguous short ID", ref: "1a2b", wantErr: &AmbiguousRefError{}},
+		{name: "too short for an ID", ref: "5e6", wantErr: &NotFoundRefError{}},
+		{name: "exact title", ref: "buy MILK", want: []string{"Buy milk"}},
+		{name: "substring", ref: "quarterly", want: []string{"Write quarterly report"}},
+		{name: "every word", ref: "report review", want: []string{"Review report draft"}},
+		{name: "ambiguous title", ref: "report", wantErr: &AmbiguousRefError{}},
+		{name: "all matching", ref: "report", allMatching: true, want: []string{"Write quarterly report", "Review report draft"}},
+		{name: "no match", ref: "holiday", wantErr: &NotFoundRefError{}},
+		{name: "empty", ref: "  ", wantErr: &NotFoundRefError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := ResolveTodoRef(todos, tt.ref, tt.allMatching)
+			switch want := tt.wantErr.(type) {
+			case *NotFoundRefError:
+				if !errors.As(err, &want) {
+					t.Fatalf("error = %v, want a NotFoundRefError", err)
+				}
+				return
+			case *AmbiguousRefError:
+				if !errors.As(err, &want) {
+					t.Fatalf("error = %v, want an AmbiguousRefError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, todo := range matches {
+				titles = append(titles, todo.Title)
+			}
+			if strings.Join(titles, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("ResolveTodoRef(%q) = %v, want %v", tt.ref, titles, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPageID(t *testing.T) {
+	tests := map[string]bool{
+		"1a2b3c4d5e6f708192a3b4c5d6e7f801":     true,
+		"1A2B3C4D-5E6F-7081-92A3-B4C5D6E7F801": true,
+		"1a2b3c4d":                             false,
+		"1a2b3c4d5e6f708192a3b4c5d6e7f80g":     false,
+		"3":                                    false,
+	}
+	for ref, want := range tests {
+		if got := IsPageID(ref); got != want {
+			t.Errorf("IsPageID(%q) = %v, want %v", ref, got, want)
+		}
+	}
+}
@@ -1,30 +1,25 @@
 package utility
 
 import (
-	"bytes"
-	"encoding/json"
-
 	"github.com/caffeines/notion-todo/models"
 )
 
 // NewTodoProperties returns a new Properties
-func newTodoProperties(title, date string) models.ItemData {
-	return models.NewProperties(title, date)
+func newTodoProperties(mapping models.PropertyMapping, todo models.NewTodo) (models.ItemData, error) {
+	return models.NewProperties(mapping, todo)
 }
 
-// GetCreateTodoBody returns a new Todo body
-func GetCreateTodoBody(title, date, databaseId string) (*bytes.Buffer, error) {
-	itemData := newTodoProperties(title, date)
-	payload := models.CreateTodoPayload{
+// GetCreateTodoPayload returns a new Todo payload with optional page content
+func GetCreateTodoPayload(mapping models.PropertyMapping, todo models.NewTodo, children []models.BlockData, databaseId string) (models.CreateTodoPayload, error) {
+	itemData, err := newTodoProperties(mapping, todo)
+	if err != nil {
+		return models.CreateTodoPayload{}, err
+	}
+	return models.CreateTodoPayload{
 		Parent: models.Parent{
 			DatabaseID: databaseId,
 		},
 		Properties: itemData,
-	}
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	return bytes.NewBuffer(jsonPayload), nil
+		Children:   children,
+	}, nil
 }
@@ -0,0 +1,58 @@
+package utility
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+// UserNotFoundError reports a reference matching no workspace user
+type UserNotFoundError struct {
+	Ref string
+}
+
+func (e *UserNotFoundError) Error() string {
+	return fmt.Sprintf("no user matches %q", e.Ref)
+}
+
+// ResolveUserRef returns the ID of the user referenced by ref, which is an
+// ID, an email address or a name. Names match exactly, then by prefix, then
+// by substring, ignoring case, and must match a single user.
+func ResolveUserRef(users []models.NotionUser, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	query := strings.ToLower(ref)
+	for _, user := range users {
+		if strings.ReplaceAll(user.ID, "-", "") == strings.ReplaceAll(query, "-", "") {
+			return user.ID, nil
+		}
+		if user.Person != nil && strings.EqualFold(user.Person.Email, ref) {
+			return user.ID, nil
+		}
+	}
+
+	rules := []func(name string) bool{
+		func(name string) bool { return name == query },
+		func(name string) bool { return strings.HasPrefix(name, query) },
+		func(name string) bool { return strings.Contains(name, query) },
+	}
+	for _, rule := range rules {
+		var matches []models.NotionUser
+		for _, user := range users {
+			if user.Name != "" && rule(strings.ToLower(user.Name)) {
+				matches = append(matches, user)
+			}
+		}
+		switch {
+		case len(matches) == 1:
+			return matches[0].ID, nil
+		case len(matches) > 1:
+			var names []string
+			for _, user := range matches {
+				names = append(names, user.DisplayName())
+			}
+			return "", fmt.Errorf("%q matches several users: %s", ref, strings.Join(names, ", "))
+		}
+	}
+	return "", &UserNotFoundError{Ref: ref}
+}
@@ -0,0 +1,63 @@
+package utility
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/models"
+)
+
+func TestResolveUserRef(t *testing.T) {
+	person := func(id, name, email string) models.NotionUser {
+		return models.NotionUser{ID: id, Type: models.UserTypePerson, Name: name, Person: &models.NotionPerson{Email: email}}
+	}
+	users := []models.NotionUser{
+		person("6a1f0c2e-0000-4000-8000-000000000001", "Ada Lovelace", "ada@example.com"),
+		person("6a1f0c2e-0000-4000-8000-000000000002", "Grace Hopper", "grace@example.com"),
+		person("6a1f0c2e-0000-4000-8000-000000000003", "Grace", "g@example.com"),
+		{ID: "6a1f0c2e-0000-4000-8000-000000000004", Type: models.UserTypeBot, Name: "Release bot"},
+	}
+
+	tests := []struct {
+		name     string
+		ref      string
+		want     string // Last digit of the user ID found
+		notFound bool
+		wantErr  string
+	}{
+		{name: "ID", ref: "6a1f0c2e-0000-4000-8000-000000000002", want: "2"},
+		{name: "ID without dashes", ref: "6a1f0c2e000040008000000000000004", want: "4"},
+		{name: "email", ref: "ADA@example.com", want: "1"},
+		{name: "exact name wins", ref: "grace", want: "3"},
+		{name: "prefix", ref: "ada", want: "1"},
+		{name: "substring", ref: "hopper", want: "2"},
+		{name: "bot", ref: "release", want: "4"},
+		{name: "ambiguous", ref: "gr", wantErr: `"gr" matches several users: Grace Hopper, Grace`},
+		{name: "no match", ref: "linus", notFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveUserRef(users, tt.ref)
+			if tt.notFound {
+				var notFound *UserNotFoundError
+				if !errors.As(err, &notFound) {
+					t.Fatalf("error = %v, want a UserNotFoundError", err)
+				}
+				return
+			}
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(got, tt.want) {
+				t.Errorf("ResolveUserRef(%q) = %s, want the user ending in %s", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package views
+
+import "github.com/caffeines/notion-todo/models"
+
+// Views manages the saved list views stored in the config file
+type Views interface {
+	// List returns the saved views in the order they were saved
+	List() ([]models.View, error)
+	// Get returns the view named name, ignoring case, or an error
+	Get(name string) (*models.View, error)
+	// Save adds a view, replacing any view with the same name
+	Save(view models.View) error
+	// Delete removes the view named name, ignoring case
+	Delete(name string) error
+}
@@ -0,0 +1,92 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+)
+
+// NotFoundError reports a view name matching no saved view
+type NotFoundError struct {
+	Name string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("no view named %q", e.Name)
+}
+
+type viewsImpl struct {
+	credentialService config.Credential
+}
+
+var views Views
+
+// NewViewSvc returns the view service backed by the config file
+func NewViewSvc(credService config.Credential) Views {
+	if credService == nil {
+		panic("credential service not initialized")
+	}
+	if views == nil {
+		views = &viewsImpl{
+			credentialService: credService,
+		}
+	}
+	return views
+}
+
+func (v *viewsImpl) List() ([]models.View, error) {
+	cfg, err := v.credentialService.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Views, nil
+}
+
+func (v *viewsImpl) Get(name string) (*models.View, error) {
+	list, err := v.List()
+	if err != nil {
+		return nil, err
+	}
+	if i := indexOf(list, name); i >= 0 {
+		return &list[i], nil
+	}
+	return nil, &NotFoundError{Name: name}
+}
+
+func (v *viewsImpl) Save(view models.View) error {
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" {
+		return errors.New("view name cannot be empty")
+	}
+	return v.credentialService.UpdateConfig(func(cfg *models.Config) error {
+		if i := indexOf(cfg.Views, view.Name); i >= 0 {
+			cfg.Views[i] = view
+			return nil
+		}
+		cfg.Views = append(cfg.Views, view)
+		return nil
+	})
+}
+
+func (v *viewsImpl) Delete(name string) error {
+	return v.credentialService.UpdateConfig(func(cfg *models.Config) error {
+		i := indexOf(cfg.Views, name)
+		if i < 0 {
+			return &NotFoundError{Name: name}
+		}
+		cfg.Views = append(cfg.Views[:i], cfg.Views[i+1:]...)
+		return nil
+	})
+}
+
+func indexOf(list []models.View, name string) int {
+	for i, view := range list {
+		if strings.EqualFold(view.Name, strings.TrimSpace(name)) {
+			return i
+		}
+	}
+	return -1
+}
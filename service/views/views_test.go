@@ -0,0 +1,151 @@
+package views
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/caffeines/notion-todo/consts"
+	"github.com/caffeines/notion-todo/models"
+	"github.com/caffeines/notion-todo/service/config"
+	"github.com/caffeines/notion-todo/service/files"
+)
+
+// newTestViews returns a view service reading the config file stored from a
+// temporary config directory
+func newTestViews(t *testing.T, stored string) (Views, files.File) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(consts.EnvProfile, "")
+	t.Setenv(consts.EnvDatabaseID, "")
+	file := files.NewFileService(files.ConfigDir, consts.ConfigFileName)
+	if err := file.SaveFile([]byte(stored)); err != nil {
+		t.Fatal(err)
+	}
+	return &viewsImpl{credentialService: config.NewCredentialSvc(file)}, file
+}
+
+func names(list []models.View) []string {
+	var names []string
+	for _, view := range list {
+		names = append(names, view.Name)
+	}
+	return names
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	svc, file := newTestViews(t, `{"secretStore":"plain","token":"secret","databaseId":"db"}`)
+
+	if list, err := svc.List(); err != nil || len(list) != 0 {
+		t.Fatalf("List() = %v, %v, want no views", list, err)
+	}
+
+	week := models.View{
+		Name:    " This week ",
+		Filter:  models.TodoFilter{Statuses: []string{"Not started", "In progress"}, DueBefore: "in 7 days", Sorts: []models.TodoSort{{Key: "due"}}},
+		Columns: []string{"position", "title", "Estimate"},
+	}
+	overdue := models.View{Name: "Overdue", Filter: models.TodoFilter{Overdue: true}}
+	for _, view := range []models.View{week, overdue} {
+		if err := svc.Save(view); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := svc.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(list); !reflect.DeepEqual(got, []string{"This week", "Overdue"}) {
+		t.Errorf("views = %v, want them in the order saved with names trimmed", got)
+	}
+
+	// Dates are stored as entered, so the view stays relative
+	data, err := file.ReadFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"dueBefore":"in 7 days"`) {
+		t.Errorf("config file = %s, want the date as entered", data)
+	}
+
+	view, err := svc.Get("THIS WEEK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	week.Name = "This week"
+	if !reflect.DeepEqual(*view, week) {
+		t.Errorf("Get() = %+v, want %+v", *view, week)
+	}
+	if _, err := svc.Get("Someday"); err == nil || !strings.Contains(err.Error(), `no view named "Someday"`) {
+		t.Errorf("Get(Someday) error = %v", err)
+	}
+
+	// Saving under an existing name replaces the view in place
+	replaced := models.View{Name: "this week", Filter: models.TodoFilter{DueOn: "today"}}
+	if err := svc.Save(replaced); err != nil {
+		t.Fatal(err)
+	}
+	list, _ = svc.List()
+	if got := names(list); !reflect.DeepEqual(got, []string{"this week", "Overdue"}) {
+		t.Errorf("views = %v after replacing", got)
+	}
+	if list[0].Filter.DueOn != "today" || list[0].Columns != nil {
+		t.Errorf("replaced view = %+v", list[0])
+	}
+
+	if err := svc.Save(models.View{Name: "  "}); err == nil {
+		t.Error("Save accepted an empty name")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	svc, _ := newTestViews(t, `{"secretStore":"plain","token":"secret","databaseId":"db","views":[{"name":"A","filter":{}},{"name":"B","filter":{}},{"name":"C","filter":{}}]}`)
+
+	if err := svc.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	list, err := svc.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(list); !reflect.DeepEqual(got, []string{"A", "C"}) {
+		t.Errorf("views = %v after deleting B", got)
+	}
+	if err := svc.Delete("B"); err == nil {
+		t.Error("deleting a missing view succeeded")
+	}
+}
+
+func TestViewsPerProfile(t *testing.T) {
+	svc, file := newTestViews(t, `{"secretStore":"plain","token":"secret","databaseId":"db","profiles":{"work":{"databaseId":"db-work"}}}`)
+	if err := svc.Save(models.View{Name: "Mine"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(consts.EnvProfile, "work")
+	if list, err := svc.List(); err != nil || len(list) != 0 {
+		t.Errorf("work views = %v, %v, want none", list, err)
+	}
+	if err := svc.Save(models.View{Name: "Team"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := file.ReadFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored models.Config
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(stored.Views); !reflect.DeepEqual(got, []string{"Mine"}) {
+		t.Errorf("default views = %v", got)
+	}
+	if got := names(stored.Profiles["work"].Views); !reflect.DeepEqual(got, []string{"Team"}) {
+		t.Errorf("work views = %v", got)
+	}
+}